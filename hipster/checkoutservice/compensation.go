@@ -0,0 +1,36 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+)
+
+// paymentVoider reverses a charge made through PaymentService.
+type paymentVoider interface {
+	Void(ctx context.Context, transactionID string, amount *pb.Money) error
+}
+
+// loggingPaymentVoider is an in-process stand-in for a refund API, which
+// PaymentService does not offer. It only logs the voided transaction.
+type loggingPaymentVoider struct{}
+
+func (loggingPaymentVoider) Void(ctx context.Context, transactionID string, amount *pb.Money) error {
+	log.WithField("transaction_id", transactionID).Infof("voided payment of %d.%09d %s",
+		amount.GetUnits(), amount.GetNanos(), amount.GetCurrencyCode())
+	return nil
+}
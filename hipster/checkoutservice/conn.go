@@ -0,0 +1,126 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability/health"
+)
+
+const (
+	// keepaliveTime is how long a connection with active RPCs may stay
+	// silent before the client pings the server.
+	keepaliveTime = 30 * time.Second
+	// keepaliveTimeout is how long the client waits for a ping ack before
+	// it considers the connection dead and reconnects.
+	keepaliveTimeout = 10 * time.Second
+)
+
+// mustConnGRPC dials addr and stores the long-lived connection in conn. The
+// dial is non-blocking: the connection is established in the background and
+// re-established by gRPC whenever it breaks. Connection state changes are
+// logged until ctx is cancelled.
+func mustConnGRPC(ctx context.Context, conn **grpc.ClientConn, addr string) {
+	var err error
+
+	*conn, err = grpc.Dial(addr, grpc.WithInsecure(),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    keepaliveTime,
+			Timeout: keepaliveTimeout,
+		}),
+		grpc.WithChainUnaryInterceptor(otelgrpc.UnaryClientInterceptor(), observability.UnaryClientLogInterceptor()),
+		grpc.WithChainStreamInterceptor(otelgrpc.StreamClientInterceptor(), observability.StreamClientLogInterceptor()),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("grpc: failed to connect %s: %+v", addr, err))
+	}
+	go monitorConn(ctx, *conn, addr)
+}
+
+// monitorConn logs every connectivity state transition of conn until ctx is
+// cancelled or the connection is shut down.
+func monitorConn(ctx context.Context, conn *grpc.ClientConn, addr string) {
+	state := conn.GetState()
+	for conn.WaitForStateChange(ctx, state) {
+		state = conn.GetState()
+		l := log.WithField("addr", addr).WithField("state", state.String())
+		if state == connectivity.TransientFailure {
+			l.Warn("downstream connection failed")
+		} else {
+			l.Debug("downstream connection state changed")
+		}
+		if state == connectivity.Shutdown {
+			return
+		}
+	}
+}
+
+// conns returns every downstream connection held by the service.
+func (cs *checkoutService) conns() []*grpc.ClientConn {
+	return []*grpc.ClientConn{
+		cs.productCatalogSvcConn,
+		cs.currencySvcConn,
+		cs.cartSvcConn,
+		cs.shippingSvcConn,
+		cs.paymentSvcConn,
+		cs.emailSvcConn,
+	}
+}
+
+// newHealthServer returns the health service of the checkout service, which
+// is serving while none of its downstream connections is failing.
+func (cs *checkoutService) newHealthServer() *health.Server {
+	hs := health.NewServer("hipstershop.CheckoutService")
+	for name, conn := range map[string]*grpc.ClientConn{
+		"productcatalogservice": cs.productCatalogSvcConn,
+		"currencyservice":       cs.currencySvcConn,
+		"cartservice":           cs.cartSvcConn,
+		"shippingservice":       cs.shippingSvcConn,
+		"paymentservice":        cs.paymentSvcConn,
+		"emailservice":          cs.emailSvcConn,
+	} {
+		hs.Register(name, health.ConnChecker(conn))
+	}
+	return hs
+}
+
+// close stops the connection monitors, closes all downstream connections and
+// releases the idempotency and order stores.
+func (cs *checkoutService) close() {
+	cs.stopMonitors()
+	if err := cs.idempotency.Close(); err != nil {
+		log.WithError(err).Warn("failed to close idempotency store")
+	}
+	if err := cs.orders.Close(); err != nil {
+		log.WithError(err).Warn("failed to close order store")
+	}
+	for _, conn := range cs.conns() {
+		if conn == nil {
+			continue
+		}
+		if err := conn.Close(); err != nil {
+			log.WithError(err).WithField("addr", conn.Target()).Warn("failed to close downstream connection")
+		}
+	}
+}
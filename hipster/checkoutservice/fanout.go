@@ -0,0 +1,51 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultFanOutLimit caps the number of downstream calls a single request
+// keeps in flight when it fans out over a list of items.
+const defaultFanOutLimit = 8
+
+// fanOut calls fn for every index in [0, n) with at most limit calls running
+// at once; limit <= 0 means defaultFanOutLimit. Callers keep result order by
+// writing into a slice at index i. The first error cancels the context handed
+// to the other calls and is returned after all started calls have finished.
+func fanOut(ctx context.Context, n, limit int, fn func(ctx context.Context, i int) error) error {
+	if limit <= 0 {
+		limit = defaultFanOutLimit
+	}
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// A call already failed; stop scheduling and report its error.
+			return g.Wait()
+		}
+		i := i
+		g.Go(func() error {
+			defer func() { <-sem }()
+			return fn(ctx, i)
+		})
+	}
+	return g.Wait()
+}
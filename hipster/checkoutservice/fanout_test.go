@@ -0,0 +1,158 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+)
+
+func TestFanOutKeepsOrder(t *testing.T) {
+	out := make([]int, 50)
+	err := fanOut(context.Background(), len(out), 4, func(ctx context.Context, i int) error {
+		time.Sleep(time.Duration(len(out)-i) * 10 * time.Microsecond)
+		out[i] = i * i
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range out {
+		if v != i*i {
+			t.Fatalf("out[%d] = %d, want %d", i, v, i*i)
+		}
+	}
+}
+
+func TestFanOutLimit(t *testing.T) {
+	const limit = 3
+	var inFlight, peak int32
+	err := fanOut(context.Background(), 20, limit, func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak > limit {
+		t.Errorf("peak concurrency = %d, want <= %d", peak, limit)
+	}
+}
+
+func TestFanOutCancelsOnError(t *testing.T) {
+	boom := errors.New("boom")
+	var started int32
+	err := fanOut(context.Background(), 100, 2, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&started, 1)
+		if i == 0 {
+			return boom
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+	if err != boom {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if n := atomic.LoadInt32(&started); n == 100 {
+		t.Errorf("all %d calls started after the first one failed", n)
+	}
+}
+
+// fakeLatency is how long each fake downstream RPC takes in the benchmarks.
+const fakeLatency = time.Millisecond
+
+type fakeCatalog struct {
+	pb.ProductCatalogServiceServer
+}
+
+func (fakeCatalog) GetProducts(ctx context.Context, req *pb.GetProductsRequest) (*pb.GetProductsResponse, error) {
+	time.Sleep(fakeLatency)
+	resp := &pb.GetProductsResponse{}
+	for _, id := range req.GetIds() {
+		resp.Products = append(resp.Products, &pb.Product{Id: id, PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 10}})
+	}
+	return resp, nil
+}
+
+type fakeCurrency struct {
+	pb.CurrencyServiceServer
+}
+
+func (fakeCurrency) Convert(ctx context.Context, req *pb.CurrencyConversionRequest) (*pb.Money, error) {
+	time.Sleep(fakeLatency)
+	return &pb.Money{CurrencyCode: req.GetToCode(), Units: req.GetFrom().GetUnits() * 2}, nil
+}
+
+// dialFake serves register on an in-memory listener and returns a client
+// connection to it.
+func dialFake(tb testing.TB, register func(*grpc.Server)) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	register(srv)
+	go srv.Serve(lis)
+	tb.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func benchmarkPrepOrderItems(b *testing.B, limit int) {
+	cs := &checkoutService{
+		productCatalogSvcConn: dialFake(b, func(s *grpc.Server) { pb.RegisterProductCatalogServiceServer(s, fakeCatalog{}) }),
+		currencySvcConn:       dialFake(b, func(s *grpc.Server) { pb.RegisterCurrencyServiceServer(s, fakeCurrency{}) }),
+		fanOutLimit:           limit,
+	}
+	items := make([]*pb.CartItem, 10)
+	for i := range items {
+		items[i] = &pb.CartItem{ProductId: fmt.Sprintf("P%d", i), Quantity: 1}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, _, err := cs.prepOrderItems(context.Background(), items, "EUR"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPrepOrderItemsSequential(b *testing.B) { benchmarkPrepOrderItems(b, 1) }
+func BenchmarkPrepOrderItemsFanOut(b *testing.B)     { benchmarkPrepOrderItems(b, 0) }
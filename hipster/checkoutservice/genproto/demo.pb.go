@@ -39,6 +39,77 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type CatalogEvent_Type int32
+
+const (
+	CatalogEvent_TYPE_UNSPECIFIED CatalogEvent_Type = 0
+	CatalogEvent_ADDED            CatalogEvent_Type = 1
+	CatalogEvent_UPDATED          CatalogEvent_Type = 2
+	// Only the product's id is set.
+	CatalogEvent_REMOVED CatalogEvent_Type = 3
+	// All events of this version have been sent; a client's copy of the
+	// catalog is now consistent.
+	CatalogEvent_SYNCED CatalogEvent_Type = 4
+)
+
+var CatalogEvent_Type_name = map[int32]string{
+	0: "TYPE_UNSPECIFIED",
+	1: "ADDED",
+	2: "UPDATED",
+	3: "REMOVED",
+	4: "SYNCED",
+}
+
+var CatalogEvent_Type_value = map[string]int32{
+	"TYPE_UNSPECIFIED": 0,
+	"ADDED":            1,
+	"UPDATED":          2,
+	"REMOVED":          3,
+	"SYNCED":           4,
+}
+
+func (x CatalogEvent_Type) String() string {
+	return proto.EnumName(CatalogEvent_Type_name, int32(x))
+}
+
+func (CatalogEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{9, 0}
+}
+
+type ShipmentStatus_State int32
+
+const (
+	ShipmentStatus_STATE_UNSPECIFIED ShipmentStatus_State = 0
+	ShipmentStatus_LABEL_CREATED     ShipmentStatus_State = 1
+	ShipmentStatus_IN_TRANSIT        ShipmentStatus_State = 2
+	ShipmentStatus_OUT_FOR_DELIVERY  ShipmentStatus_State = 3
+	ShipmentStatus_DELIVERED         ShipmentStatus_State = 4
+)
+
+var ShipmentStatus_State_name = map[int32]string{
+	0: "STATE_UNSPECIFIED",
+	1: "LABEL_CREATED",
+	2: "IN_TRANSIT",
+	3: "OUT_FOR_DELIVERY",
+	4: "DELIVERED",
+}
+
+var ShipmentStatus_State_value = map[string]int32{
+	"STATE_UNSPECIFIED": 0,
+	"LABEL_CREATED":     1,
+	"IN_TRANSIT":        2,
+	"OUT_FOR_DELIVERY":  3,
+	"DELIVERED":         4,
+}
+
+func (x ShipmentStatus_State) String() string {
+	return proto.EnumName(ShipmentStatus_State_name, int32(x))
+}
+
+func (ShipmentStatus_State) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{30, 0}
+}
+
 type CartItem struct {
 	ProductId            string   `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
 	Quantity             int32    `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
@@ -375,6 +446,313 @@ func (m *ListRecommendationsResponse) GetProductIds() []string {
 	return nil
 }
 
+type WatchCatalogRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchCatalogRequest) Reset()         { *m = WatchCatalogRequest{} }
+func (m *WatchCatalogRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchCatalogRequest) ProtoMessage()    {}
+func (*WatchCatalogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{8}
+}
+
+func (m *WatchCatalogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchCatalogRequest.Unmarshal(m, b)
+}
+func (m *WatchCatalogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchCatalogRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchCatalogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchCatalogRequest.Merge(m, src)
+}
+func (m *WatchCatalogRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchCatalogRequest.Size(m)
+}
+func (m *WatchCatalogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchCatalogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchCatalogRequest proto.InternalMessageInfo
+
+type CatalogEvent struct {
+	Type    CatalogEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=hipstershop.CatalogEvent_Type" json:"type,omitempty"`
+	Product *Product          `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	// Increases every time the catalog served changes.
+	Version              int64    `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CatalogEvent) Reset()         { *m = CatalogEvent{} }
+func (m *CatalogEvent) String() string { return proto.CompactTextString(m) }
+func (*CatalogEvent) ProtoMessage()    {}
+func (*CatalogEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{9}
+}
+
+func (m *CatalogEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CatalogEvent.Unmarshal(m, b)
+}
+func (m *CatalogEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CatalogEvent.Marshal(b, m, deterministic)
+}
+func (m *CatalogEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CatalogEvent.Merge(m, src)
+}
+func (m *CatalogEvent) XXX_Size() int {
+	return xxx_messageInfo_CatalogEvent.Size(m)
+}
+func (m *CatalogEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_CatalogEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CatalogEvent proto.InternalMessageInfo
+
+func (m *CatalogEvent) GetType() CatalogEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return CatalogEvent_TYPE_UNSPECIFIED
+}
+
+func (m *CatalogEvent) GetProduct() *Product {
+	if m != nil {
+		return m.Product
+	}
+	return nil
+}
+
+func (m *CatalogEvent) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type CreateProductRequest struct {
+	Product              *Product `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateProductRequest) Reset()         { *m = CreateProductRequest{} }
+func (m *CreateProductRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateProductRequest) ProtoMessage()    {}
+func (*CreateProductRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{10}
+}
+
+func (m *CreateProductRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateProductRequest.Unmarshal(m, b)
+}
+func (m *CreateProductRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateProductRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateProductRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateProductRequest.Merge(m, src)
+}
+func (m *CreateProductRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateProductRequest.Size(m)
+}
+func (m *CreateProductRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateProductRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateProductRequest proto.InternalMessageInfo
+
+func (m *CreateProductRequest) GetProduct() *Product {
+	if m != nil {
+		return m.Product
+	}
+	return nil
+}
+
+type UpdateProductRequest struct {
+	Product              *Product `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateProductRequest) Reset()         { *m = UpdateProductRequest{} }
+func (m *UpdateProductRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateProductRequest) ProtoMessage()    {}
+func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{11}
+}
+
+func (m *UpdateProductRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateProductRequest.Unmarshal(m, b)
+}
+func (m *UpdateProductRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateProductRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateProductRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateProductRequest.Merge(m, src)
+}
+func (m *UpdateProductRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateProductRequest.Size(m)
+}
+func (m *UpdateProductRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateProductRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateProductRequest proto.InternalMessageInfo
+
+func (m *UpdateProductRequest) GetProduct() *Product {
+	if m != nil {
+		return m.Product
+	}
+	return nil
+}
+
+type DeleteProductRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteProductRequest) Reset()         { *m = DeleteProductRequest{} }
+func (m *DeleteProductRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteProductRequest) ProtoMessage()    {}
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{12}
+}
+
+func (m *DeleteProductRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteProductRequest.Unmarshal(m, b)
+}
+func (m *DeleteProductRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteProductRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteProductRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteProductRequest.Merge(m, src)
+}
+func (m *DeleteProductRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteProductRequest.Size(m)
+}
+func (m *DeleteProductRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteProductRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteProductRequest proto.InternalMessageInfo
+
+func (m *DeleteProductRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type BulkImportRequest struct {
+	Products []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	// Remove every product that is not in products.
+	Replace              bool     `protobuf:"varint,2,opt,name=replace,proto3" json:"replace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkImportRequest) Reset()         { *m = BulkImportRequest{} }
+func (m *BulkImportRequest) String() string { return proto.CompactTextString(m) }
+func (*BulkImportRequest) ProtoMessage()    {}
+func (*BulkImportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{13}
+}
+
+func (m *BulkImportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkImportRequest.Unmarshal(m, b)
+}
+func (m *BulkImportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkImportRequest.Marshal(b, m, deterministic)
+}
+func (m *BulkImportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkImportRequest.Merge(m, src)
+}
+func (m *BulkImportRequest) XXX_Size() int {
+	return xxx_messageInfo_BulkImportRequest.Size(m)
+}
+func (m *BulkImportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkImportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkImportRequest proto.InternalMessageInfo
+
+func (m *BulkImportRequest) GetProducts() []*Product {
+	if m != nil {
+		return m.Products
+	}
+	return nil
+}
+
+func (m *BulkImportRequest) GetReplace() bool {
+	if m != nil {
+		return m.Replace
+	}
+	return false
+}
+
+type BulkImportResponse struct {
+	Created              int32    `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`
+	Updated              int32    `protobuf:"varint,2,opt,name=updated,proto3" json:"updated,omitempty"`
+	Deleted              int32    `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkImportResponse) Reset()         { *m = BulkImportResponse{} }
+func (m *BulkImportResponse) String() string { return proto.CompactTextString(m) }
+func (*BulkImportResponse) ProtoMessage()    {}
+func (*BulkImportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{14}
+}
+
+func (m *BulkImportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkImportResponse.Unmarshal(m, b)
+}
+func (m *BulkImportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkImportResponse.Marshal(b, m, deterministic)
+}
+func (m *BulkImportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkImportResponse.Merge(m, src)
+}
+func (m *BulkImportResponse) XXX_Size() int {
+	return xxx_messageInfo_BulkImportResponse.Size(m)
+}
+func (m *BulkImportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkImportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkImportResponse proto.InternalMessageInfo
+
+func (m *BulkImportResponse) GetCreated() int32 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *BulkImportResponse) GetUpdated() int32 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+func (m *BulkImportResponse) GetDeleted() int32 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
 type Product struct {
 	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
@@ -393,7 +771,7 @@ func (m *Product) Reset()         { *m = Product{} }
 func (m *Product) String() string { return proto.CompactTextString(m) }
 func (*Product) ProtoMessage()    {}
 func (*Product) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{8}
+	return fileDescriptor_ca53982754088a9d, []int{15}
 }
 
 func (m *Product) XXX_Unmarshal(b []byte) error {
@@ -467,7 +845,7 @@ func (m *ListProductsResponse) Reset()         { *m = ListProductsResponse{} }
 func (m *ListProductsResponse) String() string { return proto.CompactTextString(m) }
 func (*ListProductsResponse) ProtoMessage()    {}
 func (*ListProductsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{9}
+	return fileDescriptor_ca53982754088a9d, []int{16}
 }
 
 func (m *ListProductsResponse) XXX_Unmarshal(b []byte) error {
@@ -506,7 +884,7 @@ func (m *GetProductRequest) Reset()         { *m = GetProductRequest{} }
 func (m *GetProductRequest) String() string { return proto.CompactTextString(m) }
 func (*GetProductRequest) ProtoMessage()    {}
 func (*GetProductRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{10}
+	return fileDescriptor_ca53982754088a9d, []int{17}
 }
 
 func (m *GetProductRequest) XXX_Unmarshal(b []byte) error {
@@ -534,8 +912,104 @@ func (m *GetProductRequest) GetId() string {
 	return ""
 }
 
+type GetProductsRequest struct {
+	Ids                  []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetProductsRequest) Reset()         { *m = GetProductsRequest{} }
+func (m *GetProductsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetProductsRequest) ProtoMessage()    {}
+func (*GetProductsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{18}
+}
+
+func (m *GetProductsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProductsRequest.Unmarshal(m, b)
+}
+func (m *GetProductsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProductsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetProductsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProductsRequest.Merge(m, src)
+}
+func (m *GetProductsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetProductsRequest.Size(m)
+}
+func (m *GetProductsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProductsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetProductsRequest proto.InternalMessageInfo
+
+func (m *GetProductsRequest) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type GetProductsResponse struct {
+	// Products found, in the order their IDs were requested. Duplicate IDs
+	// are returned once.
+	Products []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	// Requested IDs that do not exist in the catalog.
+	MissingIds           []string `protobuf:"bytes,2,rep,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetProductsResponse) Reset()         { *m = GetProductsResponse{} }
+func (m *GetProductsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetProductsResponse) ProtoMessage()    {}
+func (*GetProductsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{19}
+}
+
+func (m *GetProductsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProductsResponse.Unmarshal(m, b)
+}
+func (m *GetProductsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProductsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetProductsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProductsResponse.Merge(m, src)
+}
+func (m *GetProductsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetProductsResponse.Size(m)
+}
+func (m *GetProductsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProductsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetProductsResponse proto.InternalMessageInfo
+
+func (m *GetProductsResponse) GetProducts() []*Product {
+	if m != nil {
+		return m.Products
+	}
+	return nil
+}
+
+func (m *GetProductsResponse) GetMissingIds() []string {
+	if m != nil {
+		return m.MissingIds
+	}
+	return nil
+}
+
 type SearchProductsRequest struct {
-	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Only return products in at least one of these categories. Empty means
+	// any category.
+	Categories []string `protobuf:"bytes,2,rep,name=categories,proto3" json:"categories,omitempty"`
+	// Maximum number of results to return. Defaults to 10, capped at 100.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from a previous response with the same query.
+	PageToken            string   `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -545,7 +1019,7 @@ func (m *SearchProductsRequest) Reset()         { *m = SearchProductsRequest{} }
 func (m *SearchProductsRequest) String() string { return proto.CompactTextString(m) }
 func (*SearchProductsRequest) ProtoMessage()    {}
 func (*SearchProductsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{11}
+	return fileDescriptor_ca53982754088a9d, []int{20}
 }
 
 func (m *SearchProductsRequest) XXX_Unmarshal(b []byte) error {
@@ -573,24 +1047,50 @@ func (m *SearchProductsRequest) GetQuery() string {
 	return ""
 }
 
-type SearchProductsResponse struct {
-	Results              []*Product `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+func (m *SearchProductsRequest) GetCategories() []string {
+	if m != nil {
+		return m.Categories
+	}
+	return nil
 }
 
-func (m *SearchProductsResponse) Reset()         { *m = SearchProductsResponse{} }
-func (m *SearchProductsResponse) String() string { return proto.CompactTextString(m) }
-func (*SearchProductsResponse) ProtoMessage()    {}
-func (*SearchProductsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{12}
+func (m *SearchProductsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
 }
 
-func (m *SearchProductsResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SearchProductsResponse.Unmarshal(m, b)
-}
-func (m *SearchProductsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *SearchProductsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type SearchProductsResponse struct {
+	// Matching products, best match first.
+	Results []*Product `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// Pass as page_token to fetch the next page. Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Number of matching products across all pages.
+	TotalSize            int32    `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchProductsResponse) Reset()         { *m = SearchProductsResponse{} }
+func (m *SearchProductsResponse) String() string { return proto.CompactTextString(m) }
+func (*SearchProductsResponse) ProtoMessage()    {}
+func (*SearchProductsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{21}
+}
+
+func (m *SearchProductsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchProductsResponse.Unmarshal(m, b)
+}
+func (m *SearchProductsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	return xxx_messageInfo_SearchProductsResponse.Marshal(b, m, deterministic)
 }
 func (m *SearchProductsResponse) XXX_Merge(src proto.Message) {
@@ -612,184 +1112,597 @@ func (m *SearchProductsResponse) GetResults() []*Product {
 	return nil
 }
 
+func (m *SearchProductsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *SearchProductsResponse) GetTotalSize() int32 {
+	if m != nil {
+		return m.TotalSize
+	}
+	return 0
+}
+
 type GetQuoteRequest struct {
+	// The destination. Without one the shipment is quoted to the default
+	// zone of the rate card.
+	Address *Address    `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Items   []*CartItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	// The value of the items, compared against the free-shipping thresholds.
+	SubtotalUsd *Money `protobuf:"bytes,3,opt,name=subtotal_usd,json=subtotalUsd,proto3" json:"subtotal_usd,omitempty"`
+	// The service level priced in cost_usd, such as "standard", "express"
+	// or "overnight". Defaults to "standard".
+	ServiceLevel         string   `protobuf:"bytes,4,opt,name=service_level,json=serviceLevel,proto3" json:"service_level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetQuoteRequest) Reset()         { *m = GetQuoteRequest{} }
+func (m *GetQuoteRequest) String() string { return proto.CompactTextString(m) }
+func (*GetQuoteRequest) ProtoMessage()    {}
+func (*GetQuoteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{22}
+}
+
+func (m *GetQuoteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetQuoteRequest.Unmarshal(m, b)
+}
+func (m *GetQuoteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetQuoteRequest.Marshal(b, m, deterministic)
+}
+func (m *GetQuoteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetQuoteRequest.Merge(m, src)
+}
+func (m *GetQuoteRequest) XXX_Size() int {
+	return xxx_messageInfo_GetQuoteRequest.Size(m)
+}
+func (m *GetQuoteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetQuoteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetQuoteRequest proto.InternalMessageInfo
+
+func (m *GetQuoteRequest) GetAddress() *Address {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *GetQuoteRequest) GetItems() []*CartItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *GetQuoteRequest) GetSubtotalUsd() *Money {
+	if m != nil {
+		return m.SubtotalUsd
+	}
+	return nil
+}
+
+func (m *GetQuoteRequest) GetServiceLevel() string {
+	if m != nil {
+		return m.ServiceLevel
+	}
+	return ""
+}
+
+type GetQuoteResponse struct {
+	// The cost of the requested service level.
+	CostUsd *Money `protobuf:"bytes,1,opt,name=cost_usd,json=costUsd,proto3" json:"cost_usd,omitempty"`
+	// Every service level available for the destination, cheapest first.
+	Options              []*ShippingOption `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *GetQuoteResponse) Reset()         { *m = GetQuoteResponse{} }
+func (m *GetQuoteResponse) String() string { return proto.CompactTextString(m) }
+func (*GetQuoteResponse) ProtoMessage()    {}
+func (*GetQuoteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{23}
+}
+
+func (m *GetQuoteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetQuoteResponse.Unmarshal(m, b)
+}
+func (m *GetQuoteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetQuoteResponse.Marshal(b, m, deterministic)
+}
+func (m *GetQuoteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetQuoteResponse.Merge(m, src)
+}
+func (m *GetQuoteResponse) XXX_Size() int {
+	return xxx_messageInfo_GetQuoteResponse.Size(m)
+}
+func (m *GetQuoteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetQuoteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetQuoteResponse proto.InternalMessageInfo
+
+func (m *GetQuoteResponse) GetCostUsd() *Money {
+	if m != nil {
+		return m.CostUsd
+	}
+	return nil
+}
+
+func (m *GetQuoteResponse) GetOptions() []*ShippingOption {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+type ShippingOption struct {
+	ServiceLevel string `protobuf:"bytes,1,opt,name=service_level,json=serviceLevel,proto3" json:"service_level,omitempty"`
+	// A display name, such as "Express (2-3 days)".
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	CostUsd              *Money   `protobuf:"bytes,3,opt,name=cost_usd,json=costUsd,proto3" json:"cost_usd,omitempty"`
+	MinDays              int32    `protobuf:"varint,4,opt,name=min_days,json=minDays,proto3" json:"min_days,omitempty"`
+	MaxDays              int32    `protobuf:"varint,5,opt,name=max_days,json=maxDays,proto3" json:"max_days,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShippingOption) Reset()         { *m = ShippingOption{} }
+func (m *ShippingOption) String() string { return proto.CompactTextString(m) }
+func (*ShippingOption) ProtoMessage()    {}
+func (*ShippingOption) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{24}
+}
+
+func (m *ShippingOption) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShippingOption.Unmarshal(m, b)
+}
+func (m *ShippingOption) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShippingOption.Marshal(b, m, deterministic)
+}
+func (m *ShippingOption) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShippingOption.Merge(m, src)
+}
+func (m *ShippingOption) XXX_Size() int {
+	return xxx_messageInfo_ShippingOption.Size(m)
+}
+func (m *ShippingOption) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShippingOption.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShippingOption proto.InternalMessageInfo
+
+func (m *ShippingOption) GetServiceLevel() string {
+	if m != nil {
+		return m.ServiceLevel
+	}
+	return ""
+}
+
+func (m *ShippingOption) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ShippingOption) GetCostUsd() *Money {
+	if m != nil {
+		return m.CostUsd
+	}
+	return nil
+}
+
+func (m *ShippingOption) GetMinDays() int32 {
+	if m != nil {
+		return m.MinDays
+	}
+	return 0
+}
+
+func (m *ShippingOption) GetMaxDays() int32 {
+	if m != nil {
+		return m.MaxDays
+	}
+	return 0
+}
+
+type ShipOrderRequest struct {
 	Address              *Address    `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 	Items                []*CartItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	ServiceLevel         string      `protobuf:"bytes,3,opt,name=service_level,json=serviceLevel,proto3" json:"service_level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ShipOrderRequest) Reset()         { *m = ShipOrderRequest{} }
+func (m *ShipOrderRequest) String() string { return proto.CompactTextString(m) }
+func (*ShipOrderRequest) ProtoMessage()    {}
+func (*ShipOrderRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{25}
+}
+
+func (m *ShipOrderRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShipOrderRequest.Unmarshal(m, b)
+}
+func (m *ShipOrderRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShipOrderRequest.Marshal(b, m, deterministic)
+}
+func (m *ShipOrderRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShipOrderRequest.Merge(m, src)
+}
+func (m *ShipOrderRequest) XXX_Size() int {
+	return xxx_messageInfo_ShipOrderRequest.Size(m)
+}
+func (m *ShipOrderRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShipOrderRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShipOrderRequest proto.InternalMessageInfo
+
+func (m *ShipOrderRequest) GetAddress() *Address {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *ShipOrderRequest) GetItems() []*CartItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *ShipOrderRequest) GetServiceLevel() string {
+	if m != nil {
+		return m.ServiceLevel
+	}
+	return ""
+}
+
+type ShipOrderResponse struct {
+	// The tracking ID of the first package, for clients that predate
+	// packages.
+	TrackingId string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId,proto3" json:"tracking_id,omitempty"`
+	// The address the order ships to, normalized.
+	Address *Address `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// The packages the order is split into, each tracked on its own.
+	Packages             []*Package `protobuf:"bytes,3,rep,name=packages,proto3" json:"packages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ShipOrderResponse) Reset()         { *m = ShipOrderResponse{} }
+func (m *ShipOrderResponse) String() string { return proto.CompactTextString(m) }
+func (*ShipOrderResponse) ProtoMessage()    {}
+func (*ShipOrderResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{26}
+}
+
+func (m *ShipOrderResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShipOrderResponse.Unmarshal(m, b)
+}
+func (m *ShipOrderResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShipOrderResponse.Marshal(b, m, deterministic)
+}
+func (m *ShipOrderResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShipOrderResponse.Merge(m, src)
+}
+func (m *ShipOrderResponse) XXX_Size() int {
+	return xxx_messageInfo_ShipOrderResponse.Size(m)
+}
+func (m *ShipOrderResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShipOrderResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShipOrderResponse proto.InternalMessageInfo
+
+func (m *ShipOrderResponse) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+func (m *ShipOrderResponse) GetAddress() *Address {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *ShipOrderResponse) GetPackages() []*Package {
+	if m != nil {
+		return m.Packages
+	}
+	return nil
+}
+
+type Package struct {
+	TrackingId string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId,proto3" json:"tracking_id,omitempty"`
+	// The box the items are packed in, empty if they ship as they are.
+	Box                  string      `protobuf:"bytes,2,opt,name=box,proto3" json:"box,omitempty"`
+	Items                []*CartItem `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	WeightKg             float64     `protobuf:"fixed64,4,opt,name=weight_kg,json=weightKg,proto3" json:"weight_kg,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
 }
 
-func (m *GetQuoteRequest) Reset()         { *m = GetQuoteRequest{} }
-func (m *GetQuoteRequest) String() string { return proto.CompactTextString(m) }
-func (*GetQuoteRequest) ProtoMessage()    {}
-func (*GetQuoteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{13}
+func (m *Package) Reset()         { *m = Package{} }
+func (m *Package) String() string { return proto.CompactTextString(m) }
+func (*Package) ProtoMessage()    {}
+func (*Package) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{27}
+}
+
+func (m *Package) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Package.Unmarshal(m, b)
+}
+func (m *Package) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Package.Marshal(b, m, deterministic)
+}
+func (m *Package) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Package.Merge(m, src)
+}
+func (m *Package) XXX_Size() int {
+	return xxx_messageInfo_Package.Size(m)
+}
+func (m *Package) XXX_DiscardUnknown() {
+	xxx_messageInfo_Package.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Package proto.InternalMessageInfo
+
+func (m *Package) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+func (m *Package) GetBox() string {
+	if m != nil {
+		return m.Box
+	}
+	return ""
+}
+
+func (m *Package) GetItems() []*CartItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *Package) GetWeightKg() float64 {
+	if m != nil {
+		return m.WeightKg
+	}
+	return 0
+}
+
+type GetShipmentStatusRequest struct {
+	TrackingId           string   `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId,proto3" json:"tracking_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetShipmentStatusRequest) Reset()         { *m = GetShipmentStatusRequest{} }
+func (m *GetShipmentStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetShipmentStatusRequest) ProtoMessage()    {}
+func (*GetShipmentStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{28}
 }
 
-func (m *GetQuoteRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetQuoteRequest.Unmarshal(m, b)
+func (m *GetShipmentStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetShipmentStatusRequest.Unmarshal(m, b)
 }
-func (m *GetQuoteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetQuoteRequest.Marshal(b, m, deterministic)
+func (m *GetShipmentStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetShipmentStatusRequest.Marshal(b, m, deterministic)
 }
-func (m *GetQuoteRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetQuoteRequest.Merge(m, src)
+func (m *GetShipmentStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetShipmentStatusRequest.Merge(m, src)
 }
-func (m *GetQuoteRequest) XXX_Size() int {
-	return xxx_messageInfo_GetQuoteRequest.Size(m)
+func (m *GetShipmentStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetShipmentStatusRequest.Size(m)
 }
-func (m *GetQuoteRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetQuoteRequest.DiscardUnknown(m)
+func (m *GetShipmentStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetShipmentStatusRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetQuoteRequest proto.InternalMessageInfo
-
-func (m *GetQuoteRequest) GetAddress() *Address {
-	if m != nil {
-		return m.Address
-	}
-	return nil
-}
+var xxx_messageInfo_GetShipmentStatusRequest proto.InternalMessageInfo
 
-func (m *GetQuoteRequest) GetItems() []*CartItem {
+func (m *GetShipmentStatusRequest) GetTrackingId() string {
 	if m != nil {
-		return m.Items
+		return m.TrackingId
 	}
-	return nil
+	return ""
 }
 
-type GetQuoteResponse struct {
-	CostUsd              *Money   `protobuf:"bytes,1,opt,name=cost_usd,json=costUsd,proto3" json:"cost_usd,omitempty"`
+type WatchShipmentRequest struct {
+	TrackingId           string   `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId,proto3" json:"tracking_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetQuoteResponse) Reset()         { *m = GetQuoteResponse{} }
-func (m *GetQuoteResponse) String() string { return proto.CompactTextString(m) }
-func (*GetQuoteResponse) ProtoMessage()    {}
-func (*GetQuoteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{14}
+func (m *WatchShipmentRequest) Reset()         { *m = WatchShipmentRequest{} }
+func (m *WatchShipmentRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchShipmentRequest) ProtoMessage()    {}
+func (*WatchShipmentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{29}
 }
 
-func (m *GetQuoteResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetQuoteResponse.Unmarshal(m, b)
+func (m *WatchShipmentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchShipmentRequest.Unmarshal(m, b)
 }
-func (m *GetQuoteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetQuoteResponse.Marshal(b, m, deterministic)
+func (m *WatchShipmentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchShipmentRequest.Marshal(b, m, deterministic)
 }
-func (m *GetQuoteResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetQuoteResponse.Merge(m, src)
+func (m *WatchShipmentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchShipmentRequest.Merge(m, src)
 }
-func (m *GetQuoteResponse) XXX_Size() int {
-	return xxx_messageInfo_GetQuoteResponse.Size(m)
+func (m *WatchShipmentRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchShipmentRequest.Size(m)
 }
-func (m *GetQuoteResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetQuoteResponse.DiscardUnknown(m)
+func (m *WatchShipmentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchShipmentRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetQuoteResponse proto.InternalMessageInfo
+var xxx_messageInfo_WatchShipmentRequest proto.InternalMessageInfo
 
-func (m *GetQuoteResponse) GetCostUsd() *Money {
+func (m *WatchShipmentRequest) GetTrackingId() string {
 	if m != nil {
-		return m.CostUsd
+		return m.TrackingId
 	}
-	return nil
+	return ""
 }
 
-type ShipOrderRequest struct {
-	Address              *Address    `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
-	Items                []*CartItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+type ShipmentStatus struct {
+	TrackingId   string               `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId,proto3" json:"tracking_id,omitempty"`
+	State        ShipmentStatus_State `protobuf:"varint,2,opt,name=state,proto3,enum=hipstershop.ShipmentStatus_State" json:"state,omitempty"`
+	ServiceLevel string               `protobuf:"bytes,3,opt,name=service_level,json=serviceLevel,proto3" json:"service_level,omitempty"`
+	// Every state the shipment has been in, oldest first.
+	Events []*ShipmentEvent `protobuf:"bytes,4,rep,name=events,proto3" json:"events,omitempty"`
+	// When the shipment is, or was, delivered, in seconds since the Unix
+	// epoch.
+	EstimatedDelivery    int64    `protobuf:"varint,5,opt,name=estimated_delivery,json=estimatedDelivery,proto3" json:"estimated_delivery,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ShipOrderRequest) Reset()         { *m = ShipOrderRequest{} }
-func (m *ShipOrderRequest) String() string { return proto.CompactTextString(m) }
-func (*ShipOrderRequest) ProtoMessage()    {}
-func (*ShipOrderRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{15}
+func (m *ShipmentStatus) Reset()         { *m = ShipmentStatus{} }
+func (m *ShipmentStatus) String() string { return proto.CompactTextString(m) }
+func (*ShipmentStatus) ProtoMessage()    {}
+func (*ShipmentStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{30}
 }
 
-func (m *ShipOrderRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ShipOrderRequest.Unmarshal(m, b)
+func (m *ShipmentStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShipmentStatus.Unmarshal(m, b)
 }
-func (m *ShipOrderRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ShipOrderRequest.Marshal(b, m, deterministic)
+func (m *ShipmentStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShipmentStatus.Marshal(b, m, deterministic)
 }
-func (m *ShipOrderRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ShipOrderRequest.Merge(m, src)
+func (m *ShipmentStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShipmentStatus.Merge(m, src)
 }
-func (m *ShipOrderRequest) XXX_Size() int {
-	return xxx_messageInfo_ShipOrderRequest.Size(m)
+func (m *ShipmentStatus) XXX_Size() int {
+	return xxx_messageInfo_ShipmentStatus.Size(m)
 }
-func (m *ShipOrderRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ShipOrderRequest.DiscardUnknown(m)
+func (m *ShipmentStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShipmentStatus.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ShipOrderRequest proto.InternalMessageInfo
+var xxx_messageInfo_ShipmentStatus proto.InternalMessageInfo
 
-func (m *ShipOrderRequest) GetAddress() *Address {
+func (m *ShipmentStatus) GetTrackingId() string {
 	if m != nil {
-		return m.Address
+		return m.TrackingId
 	}
-	return nil
+	return ""
 }
 
-func (m *ShipOrderRequest) GetItems() []*CartItem {
+func (m *ShipmentStatus) GetState() ShipmentStatus_State {
 	if m != nil {
-		return m.Items
+		return m.State
+	}
+	return ShipmentStatus_STATE_UNSPECIFIED
+}
+
+func (m *ShipmentStatus) GetServiceLevel() string {
+	if m != nil {
+		return m.ServiceLevel
+	}
+	return ""
+}
+
+func (m *ShipmentStatus) GetEvents() []*ShipmentEvent {
+	if m != nil {
+		return m.Events
 	}
 	return nil
 }
 
-type ShipOrderResponse struct {
-	TrackingId           string   `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId,proto3" json:"tracking_id,omitempty"`
+func (m *ShipmentStatus) GetEstimatedDelivery() int64 {
+	if m != nil {
+		return m.EstimatedDelivery
+	}
+	return 0
+}
+
+type ShipmentEvent struct {
+	State ShipmentStatus_State `protobuf:"varint,1,opt,name=state,proto3,enum=hipstershop.ShipmentStatus_State" json:"state,omitempty"`
+	// In seconds since the Unix epoch.
+	Time                 int64    `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ShipOrderResponse) Reset()         { *m = ShipOrderResponse{} }
-func (m *ShipOrderResponse) String() string { return proto.CompactTextString(m) }
-func (*ShipOrderResponse) ProtoMessage()    {}
-func (*ShipOrderResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{16}
+func (m *ShipmentEvent) Reset()         { *m = ShipmentEvent{} }
+func (m *ShipmentEvent) String() string { return proto.CompactTextString(m) }
+func (*ShipmentEvent) ProtoMessage()    {}
+func (*ShipmentEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{31}
 }
 
-func (m *ShipOrderResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ShipOrderResponse.Unmarshal(m, b)
+func (m *ShipmentEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShipmentEvent.Unmarshal(m, b)
 }
-func (m *ShipOrderResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ShipOrderResponse.Marshal(b, m, deterministic)
+func (m *ShipmentEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShipmentEvent.Marshal(b, m, deterministic)
 }
-func (m *ShipOrderResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ShipOrderResponse.Merge(m, src)
+func (m *ShipmentEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShipmentEvent.Merge(m, src)
 }
-func (m *ShipOrderResponse) XXX_Size() int {
-	return xxx_messageInfo_ShipOrderResponse.Size(m)
+func (m *ShipmentEvent) XXX_Size() int {
+	return xxx_messageInfo_ShipmentEvent.Size(m)
 }
-func (m *ShipOrderResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_ShipOrderResponse.DiscardUnknown(m)
+func (m *ShipmentEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShipmentEvent.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ShipOrderResponse proto.InternalMessageInfo
+var xxx_messageInfo_ShipmentEvent proto.InternalMessageInfo
 
-func (m *ShipOrderResponse) GetTrackingId() string {
+func (m *ShipmentEvent) GetState() ShipmentStatus_State {
 	if m != nil {
-		return m.TrackingId
+		return m.State
 	}
-	return ""
+	return ShipmentStatus_STATE_UNSPECIFIED
+}
+
+func (m *ShipmentEvent) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
 }
 
 type Address struct {
-	StreetAddress        string   `protobuf:"bytes,1,opt,name=street_address,json=streetAddress,proto3" json:"street_address,omitempty"`
-	City                 string   `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
-	State                string   `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
-	Country              string   `protobuf:"bytes,4,opt,name=country,proto3" json:"country,omitempty"`
+	StreetAddress string `protobuf:"bytes,1,opt,name=street_address,json=streetAddress,proto3" json:"street_address,omitempty"`
+	City          string `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	State         string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	Country       string `protobuf:"bytes,4,opt,name=country,proto3" json:"country,omitempty"`
+	// zip_code is kept for clients that predate postal_code, which can hold
+	// postal codes that are not numbers or have leading zeros. postal_code
+	// takes precedence when both are set.
 	ZipCode              int32    `protobuf:"varint,5,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	PostalCode           string   `protobuf:"bytes,6,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -799,7 +1712,7 @@ func (m *Address) Reset()         { *m = Address{} }
 func (m *Address) String() string { return proto.CompactTextString(m) }
 func (*Address) ProtoMessage()    {}
 func (*Address) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{17}
+	return fileDescriptor_ca53982754088a9d, []int{32}
 }
 
 func (m *Address) XXX_Unmarshal(b []byte) error {
@@ -855,6 +1768,13 @@ func (m *Address) GetZipCode() int32 {
 	return 0
 }
 
+func (m *Address) GetPostalCode() string {
+	if m != nil {
+		return m.PostalCode
+	}
+	return ""
+}
+
 // Represents an amount of money with its currency type.
 type Money struct {
 	// The 3-letter currency code defined in ISO 4217.
@@ -878,7 +1798,7 @@ func (m *Money) Reset()         { *m = Money{} }
 func (m *Money) String() string { return proto.CompactTextString(m) }
 func (*Money) ProtoMessage()    {}
 func (*Money) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{18}
+	return fileDescriptor_ca53982754088a9d, []int{33}
 }
 
 func (m *Money) XXX_Unmarshal(b []byte) error {
@@ -932,7 +1852,7 @@ func (m *GetSupportedCurrenciesResponse) Reset()         { *m = GetSupportedCurr
 func (m *GetSupportedCurrenciesResponse) String() string { return proto.CompactTextString(m) }
 func (*GetSupportedCurrenciesResponse) ProtoMessage()    {}
 func (*GetSupportedCurrenciesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{19}
+	return fileDescriptor_ca53982754088a9d, []int{34}
 }
 
 func (m *GetSupportedCurrenciesResponse) XXX_Unmarshal(b []byte) error {
@@ -973,7 +1893,7 @@ func (m *CurrencyConversionRequest) Reset()         { *m = CurrencyConversionReq
 func (m *CurrencyConversionRequest) String() string { return proto.CompactTextString(m) }
 func (*CurrencyConversionRequest) ProtoMessage()    {}
 func (*CurrencyConversionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{20}
+	return fileDescriptor_ca53982754088a9d, []int{35}
 }
 
 func (m *CurrencyConversionRequest) XXX_Unmarshal(b []byte) error {
@@ -1022,7 +1942,7 @@ func (m *CreditCardInfo) Reset()         { *m = CreditCardInfo{} }
 func (m *CreditCardInfo) String() string { return proto.CompactTextString(m) }
 func (*CreditCardInfo) ProtoMessage()    {}
 func (*CreditCardInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{21}
+	return fileDescriptor_ca53982754088a9d, []int{36}
 }
 
 func (m *CreditCardInfo) XXX_Unmarshal(b []byte) error {
@@ -1083,7 +2003,7 @@ func (m *ChargeRequest) Reset()         { *m = ChargeRequest{} }
 func (m *ChargeRequest) String() string { return proto.CompactTextString(m) }
 func (*ChargeRequest) ProtoMessage()    {}
 func (*ChargeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{22}
+	return fileDescriptor_ca53982754088a9d, []int{37}
 }
 
 func (m *ChargeRequest) XXX_Unmarshal(b []byte) error {
@@ -1129,7 +2049,7 @@ func (m *ChargeResponse) Reset()         { *m = ChargeResponse{} }
 func (m *ChargeResponse) String() string { return proto.CompactTextString(m) }
 func (*ChargeResponse) ProtoMessage()    {}
 func (*ChargeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{23}
+	return fileDescriptor_ca53982754088a9d, []int{38}
 }
 
 func (m *ChargeResponse) XXX_Unmarshal(b []byte) error {
@@ -1169,7 +2089,7 @@ func (m *OrderItem) Reset()         { *m = OrderItem{} }
 func (m *OrderItem) String() string { return proto.CompactTextString(m) }
 func (*OrderItem) ProtoMessage()    {}
 func (*OrderItem) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{24}
+	return fileDescriptor_ca53982754088a9d, []int{39}
 }
 
 func (m *OrderItem) XXX_Unmarshal(b []byte) error {
@@ -1210,16 +2130,20 @@ type OrderResult struct {
 	ShippingCost         *Money       `protobuf:"bytes,3,opt,name=shipping_cost,json=shippingCost,proto3" json:"shipping_cost,omitempty"`
 	ShippingAddress      *Address     `protobuf:"bytes,4,opt,name=shipping_address,json=shippingAddress,proto3" json:"shipping_address,omitempty"`
 	Items                []*OrderItem `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	ShippingServiceLevel string       `protobuf:"bytes,6,opt,name=shipping_service_level,json=shippingServiceLevel,proto3" json:"shipping_service_level,omitempty"`
+	// The packages the order ships in. shipping_tracking_id is the
+	// tracking ID of the first.
+	Packages             []*Package `protobuf:"bytes,7,rep,name=packages,proto3" json:"packages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
 
 func (m *OrderResult) Reset()         { *m = OrderResult{} }
 func (m *OrderResult) String() string { return proto.CompactTextString(m) }
 func (*OrderResult) ProtoMessage()    {}
 func (*OrderResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{25}
+	return fileDescriptor_ca53982754088a9d, []int{40}
 }
 
 func (m *OrderResult) XXX_Unmarshal(b []byte) error {
@@ -1275,6 +2199,20 @@ func (m *OrderResult) GetItems() []*OrderItem {
 	return nil
 }
 
+func (m *OrderResult) GetShippingServiceLevel() string {
+	if m != nil {
+		return m.ShippingServiceLevel
+	}
+	return ""
+}
+
+func (m *OrderResult) GetPackages() []*Package {
+	if m != nil {
+		return m.Packages
+	}
+	return nil
+}
+
 type SendOrderConfirmationRequest struct {
 	Email                string       `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
 	Order                *OrderResult `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
@@ -1287,7 +2225,7 @@ func (m *SendOrderConfirmationRequest) Reset()         { *m = SendOrderConfirmat
 func (m *SendOrderConfirmationRequest) String() string { return proto.CompactTextString(m) }
 func (*SendOrderConfirmationRequest) ProtoMessage()    {}
 func (*SendOrderConfirmationRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{26}
+	return fileDescriptor_ca53982754088a9d, []int{41}
 }
 
 func (m *SendOrderConfirmationRequest) XXX_Unmarshal(b []byte) error {
@@ -1323,21 +2261,29 @@ func (m *SendOrderConfirmationRequest) GetOrder() *OrderResult {
 }
 
 type PlaceOrderRequest struct {
-	UserId               string          `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	UserCurrency         string          `protobuf:"bytes,2,opt,name=user_currency,json=userCurrency,proto3" json:"user_currency,omitempty"`
-	Address              *Address        `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
-	Email                string          `protobuf:"bytes,5,opt,name=email,proto3" json:"email,omitempty"`
-	CreditCard           *CreditCardInfo `protobuf:"bytes,6,opt,name=credit_card,json=creditCard,proto3" json:"credit_card,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	UserId       string          `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UserCurrency string          `protobuf:"bytes,2,opt,name=user_currency,json=userCurrency,proto3" json:"user_currency,omitempty"`
+	Address      *Address        `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Email        string          `protobuf:"bytes,5,opt,name=email,proto3" json:"email,omitempty"`
+	CreditCard   *CreditCardInfo `protobuf:"bytes,6,opt,name=credit_card,json=creditCard,proto3" json:"credit_card,omitempty"`
+	// Identifies a checkout attempt. Retries of a request carrying the same
+	// key for the same user return the result of the first request instead
+	// of placing a new order. The key may also be sent as the
+	// "idempotency-key" request metadata.
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// The shipping service level chosen from the options of GetQuote.
+	// Defaults to "standard".
+	ShippingServiceLevel string   `protobuf:"bytes,8,opt,name=shipping_service_level,json=shippingServiceLevel,proto3" json:"shipping_service_level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *PlaceOrderRequest) Reset()         { *m = PlaceOrderRequest{} }
 func (m *PlaceOrderRequest) String() string { return proto.CompactTextString(m) }
 func (*PlaceOrderRequest) ProtoMessage()    {}
 func (*PlaceOrderRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{27}
+	return fileDescriptor_ca53982754088a9d, []int{42}
 }
 
 func (m *PlaceOrderRequest) XXX_Unmarshal(b []byte) error {
@@ -1393,6 +2339,20 @@ func (m *PlaceOrderRequest) GetCreditCard() *CreditCardInfo {
 	return nil
 }
 
+func (m *PlaceOrderRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
+func (m *PlaceOrderRequest) GetShippingServiceLevel() string {
+	if m != nil {
+		return m.ShippingServiceLevel
+	}
+	return ""
+}
+
 type PlaceOrderResponse struct {
 	Order                *OrderResult `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
@@ -1404,7 +2364,7 @@ func (m *PlaceOrderResponse) Reset()         { *m = PlaceOrderResponse{} }
 func (m *PlaceOrderResponse) String() string { return proto.CompactTextString(m) }
 func (*PlaceOrderResponse) ProtoMessage()    {}
 func (*PlaceOrderResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{28}
+	return fileDescriptor_ca53982754088a9d, []int{43}
 }
 
 func (m *PlaceOrderResponse) XXX_Unmarshal(b []byte) error {
@@ -1425,13 +2385,168 @@ func (m *PlaceOrderResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PlaceOrderResponse proto.InternalMessageInfo
 
-func (m *PlaceOrderResponse) GetOrder() *OrderResult {
+func (m *PlaceOrderResponse) GetOrder() *OrderResult {
+	if m != nil {
+		return m.Order
+	}
+	return nil
+}
+
+type GetOrderRequest struct {
+	OrderId string `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// Required: only an order placed by this user is returned.
+	UserId               string   `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOrderRequest) Reset()         { *m = GetOrderRequest{} }
+func (m *GetOrderRequest) String() string { return proto.CompactTextString(m) }
+func (*GetOrderRequest) ProtoMessage()    {}
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{44}
+}
+
+func (m *GetOrderRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetOrderRequest.Unmarshal(m, b)
+}
+func (m *GetOrderRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetOrderRequest.Marshal(b, m, deterministic)
+}
+func (m *GetOrderRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOrderRequest.Merge(m, src)
+}
+func (m *GetOrderRequest) XXX_Size() int {
+	return xxx_messageInfo_GetOrderRequest.Size(m)
+}
+func (m *GetOrderRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOrderRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOrderRequest proto.InternalMessageInfo
+
+func (m *GetOrderRequest) GetOrderId() string {
+	if m != nil {
+		return m.OrderId
+	}
+	return ""
+}
+
+func (m *GetOrderRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+type ListOrdersRequest struct {
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Maximum number of orders to return. The server picks a default when
+	// unset.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous response, or empty for the first page.
+	PageToken            string   `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListOrdersRequest) Reset()         { *m = ListOrdersRequest{} }
+func (m *ListOrdersRequest) String() string { return proto.CompactTextString(m) }
+func (*ListOrdersRequest) ProtoMessage()    {}
+func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{45}
+}
+
+func (m *ListOrdersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListOrdersRequest.Unmarshal(m, b)
+}
+func (m *ListOrdersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListOrdersRequest.Marshal(b, m, deterministic)
+}
+func (m *ListOrdersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListOrdersRequest.Merge(m, src)
+}
+func (m *ListOrdersRequest) XXX_Size() int {
+	return xxx_messageInfo_ListOrdersRequest.Size(m)
+}
+func (m *ListOrdersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListOrdersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListOrdersRequest proto.InternalMessageInfo
+
+func (m *ListOrdersRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *ListOrdersRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListOrdersRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type ListOrdersResponse struct {
+	// Orders of the user, most recent first.
+	Orders []*OrderResult `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	// Token for the next page, empty when there are no more orders.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListOrdersResponse) Reset()         { *m = ListOrdersResponse{} }
+func (m *ListOrdersResponse) String() string { return proto.CompactTextString(m) }
+func (*ListOrdersResponse) ProtoMessage()    {}
+func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ca53982754088a9d, []int{46}
+}
+
+func (m *ListOrdersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListOrdersResponse.Unmarshal(m, b)
+}
+func (m *ListOrdersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListOrdersResponse.Marshal(b, m, deterministic)
+}
+func (m *ListOrdersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListOrdersResponse.Merge(m, src)
+}
+func (m *ListOrdersResponse) XXX_Size() int {
+	return xxx_messageInfo_ListOrdersResponse.Size(m)
+}
+func (m *ListOrdersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListOrdersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListOrdersResponse proto.InternalMessageInfo
+
+func (m *ListOrdersResponse) GetOrders() []*OrderResult {
 	if m != nil {
-		return m.Order
+		return m.Orders
 	}
 	return nil
 }
 
+func (m *ListOrdersResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type AdRequest struct {
 	// List of important key words from the current page describing the context.
 	ContextKeys          []string `protobuf:"bytes,1,rep,name=context_keys,json=contextKeys,proto3" json:"context_keys,omitempty"`
@@ -1444,7 +2559,7 @@ func (m *AdRequest) Reset()         { *m = AdRequest{} }
 func (m *AdRequest) String() string { return proto.CompactTextString(m) }
 func (*AdRequest) ProtoMessage()    {}
 func (*AdRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{29}
+	return fileDescriptor_ca53982754088a9d, []int{47}
 }
 
 func (m *AdRequest) XXX_Unmarshal(b []byte) error {
@@ -1483,7 +2598,7 @@ func (m *AdResponse) Reset()         { *m = AdResponse{} }
 func (m *AdResponse) String() string { return proto.CompactTextString(m) }
 func (*AdResponse) ProtoMessage()    {}
 func (*AdResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{30}
+	return fileDescriptor_ca53982754088a9d, []int{48}
 }
 
 func (m *AdResponse) XXX_Unmarshal(b []byte) error {
@@ -1525,7 +2640,7 @@ func (m *Ad) Reset()         { *m = Ad{} }
 func (m *Ad) String() string { return proto.CompactTextString(m) }
 func (*Ad) ProtoMessage()    {}
 func (*Ad) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ca53982754088a9d, []int{31}
+	return fileDescriptor_ca53982754088a9d, []int{49}
 }
 
 func (m *Ad) XXX_Unmarshal(b []byte) error {
@@ -1561,6 +2676,8 @@ func (m *Ad) GetText() string {
 }
 
 func init() {
+	proto.RegisterEnum("hipstershop.CatalogEvent_Type", CatalogEvent_Type_name, CatalogEvent_Type_value)
+	proto.RegisterEnum("hipstershop.ShipmentStatus_State", ShipmentStatus_State_name, ShipmentStatus_State_value)
 	proto.RegisterType((*CartItem)(nil), "hipstershop.CartItem")
 	proto.RegisterType((*AddItemRequest)(nil), "hipstershop.AddItemRequest")
 	proto.RegisterType((*EmptyCartRequest)(nil), "hipstershop.EmptyCartRequest")
@@ -1569,15 +2686,30 @@ func init() {
 	proto.RegisterType((*Empty)(nil), "hipstershop.Empty")
 	proto.RegisterType((*ListRecommendationsRequest)(nil), "hipstershop.ListRecommendationsRequest")
 	proto.RegisterType((*ListRecommendationsResponse)(nil), "hipstershop.ListRecommendationsResponse")
+	proto.RegisterType((*WatchCatalogRequest)(nil), "hipstershop.WatchCatalogRequest")
+	proto.RegisterType((*CatalogEvent)(nil), "hipstershop.CatalogEvent")
+	proto.RegisterType((*CreateProductRequest)(nil), "hipstershop.CreateProductRequest")
+	proto.RegisterType((*UpdateProductRequest)(nil), "hipstershop.UpdateProductRequest")
+	proto.RegisterType((*DeleteProductRequest)(nil), "hipstershop.DeleteProductRequest")
+	proto.RegisterType((*BulkImportRequest)(nil), "hipstershop.BulkImportRequest")
+	proto.RegisterType((*BulkImportResponse)(nil), "hipstershop.BulkImportResponse")
 	proto.RegisterType((*Product)(nil), "hipstershop.Product")
 	proto.RegisterType((*ListProductsResponse)(nil), "hipstershop.ListProductsResponse")
 	proto.RegisterType((*GetProductRequest)(nil), "hipstershop.GetProductRequest")
+	proto.RegisterType((*GetProductsRequest)(nil), "hipstershop.GetProductsRequest")
+	proto.RegisterType((*GetProductsResponse)(nil), "hipstershop.GetProductsResponse")
 	proto.RegisterType((*SearchProductsRequest)(nil), "hipstershop.SearchProductsRequest")
 	proto.RegisterType((*SearchProductsResponse)(nil), "hipstershop.SearchProductsResponse")
 	proto.RegisterType((*GetQuoteRequest)(nil), "hipstershop.GetQuoteRequest")
 	proto.RegisterType((*GetQuoteResponse)(nil), "hipstershop.GetQuoteResponse")
+	proto.RegisterType((*ShippingOption)(nil), "hipstershop.ShippingOption")
 	proto.RegisterType((*ShipOrderRequest)(nil), "hipstershop.ShipOrderRequest")
 	proto.RegisterType((*ShipOrderResponse)(nil), "hipstershop.ShipOrderResponse")
+	proto.RegisterType((*Package)(nil), "hipstershop.Package")
+	proto.RegisterType((*GetShipmentStatusRequest)(nil), "hipstershop.GetShipmentStatusRequest")
+	proto.RegisterType((*WatchShipmentRequest)(nil), "hipstershop.WatchShipmentRequest")
+	proto.RegisterType((*ShipmentStatus)(nil), "hipstershop.ShipmentStatus")
+	proto.RegisterType((*ShipmentEvent)(nil), "hipstershop.ShipmentEvent")
 	proto.RegisterType((*Address)(nil), "hipstershop.Address")
 	proto.RegisterType((*Money)(nil), "hipstershop.Money")
 	proto.RegisterType((*GetSupportedCurrenciesResponse)(nil), "hipstershop.GetSupportedCurrenciesResponse")
@@ -1590,6 +2722,9 @@ func init() {
 	proto.RegisterType((*SendOrderConfirmationRequest)(nil), "hipstershop.SendOrderConfirmationRequest")
 	proto.RegisterType((*PlaceOrderRequest)(nil), "hipstershop.PlaceOrderRequest")
 	proto.RegisterType((*PlaceOrderResponse)(nil), "hipstershop.PlaceOrderResponse")
+	proto.RegisterType((*GetOrderRequest)(nil), "hipstershop.GetOrderRequest")
+	proto.RegisterType((*ListOrdersRequest)(nil), "hipstershop.ListOrdersRequest")
+	proto.RegisterType((*ListOrdersResponse)(nil), "hipstershop.ListOrdersResponse")
 	proto.RegisterType((*AdRequest)(nil), "hipstershop.AdRequest")
 	proto.RegisterType((*AdResponse)(nil), "hipstershop.AdResponse")
 	proto.RegisterType((*Ad)(nil), "hipstershop.Ad")
@@ -1803,7 +2938,11 @@ var _RecommendationService_serviceDesc = grpc.ServiceDesc{
 type ProductCatalogServiceClient interface {
 	ListProducts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListProductsResponse, error)
 	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error)
+	GetProducts(ctx context.Context, in *GetProductsRequest, opts ...grpc.CallOption) (*GetProductsResponse, error)
 	SearchProducts(ctx context.Context, in *SearchProductsRequest, opts ...grpc.CallOption) (*SearchProductsResponse, error)
+	// Streams the catalog: first every product as ADDED followed by SYNCED,
+	// then, whenever the catalog changes, the differences followed by SYNCED.
+	WatchCatalog(ctx context.Context, in *WatchCatalogRequest, opts ...grpc.CallOption) (ProductCatalogService_WatchCatalogClient, error)
 }
 
 type productCatalogServiceClient struct {
@@ -1832,6 +2971,15 @@ func (c *productCatalogServiceClient) GetProduct(ctx context.Context, in *GetPro
 	return out, nil
 }
 
+func (c *productCatalogServiceClient) GetProducts(ctx context.Context, in *GetProductsRequest, opts ...grpc.CallOption) (*GetProductsResponse, error) {
+	out := new(GetProductsResponse)
+	err := c.cc.Invoke(ctx, "/hipstershop.ProductCatalogService/GetProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *productCatalogServiceClient) SearchProducts(ctx context.Context, in *SearchProductsRequest, opts ...grpc.CallOption) (*SearchProductsResponse, error) {
 	out := new(SearchProductsResponse)
 	err := c.cc.Invoke(ctx, "/hipstershop.ProductCatalogService/SearchProducts", in, out, opts...)
@@ -1841,11 +2989,47 @@ func (c *productCatalogServiceClient) SearchProducts(ctx context.Context, in *Se
 	return out, nil
 }
 
+func (c *productCatalogServiceClient) WatchCatalog(ctx context.Context, in *WatchCatalogRequest, opts ...grpc.CallOption) (ProductCatalogService_WatchCatalogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ProductCatalogService_serviceDesc.Streams[0], "/hipstershop.ProductCatalogService/WatchCatalog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &productCatalogServiceWatchCatalogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ProductCatalogService_WatchCatalogClient interface {
+	Recv() (*CatalogEvent, error)
+	grpc.ClientStream
+}
+
+type productCatalogServiceWatchCatalogClient struct {
+	grpc.ClientStream
+}
+
+func (x *productCatalogServiceWatchCatalogClient) Recv() (*CatalogEvent, error) {
+	m := new(CatalogEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ProductCatalogServiceServer is the server API for ProductCatalogService service.
 type ProductCatalogServiceServer interface {
 	ListProducts(context.Context, *Empty) (*ListProductsResponse, error)
 	GetProduct(context.Context, *GetProductRequest) (*Product, error)
+	GetProducts(context.Context, *GetProductsRequest) (*GetProductsResponse, error)
 	SearchProducts(context.Context, *SearchProductsRequest) (*SearchProductsResponse, error)
+	// Streams the catalog: first every product as ADDED followed by SYNCED,
+	// then, whenever the catalog changes, the differences followed by SYNCED.
+	WatchCatalog(*WatchCatalogRequest, ProductCatalogService_WatchCatalogServer) error
 }
 
 func RegisterProductCatalogServiceServer(s *grpc.Server, srv ProductCatalogServiceServer) {
@@ -1888,6 +3072,24 @@ func _ProductCatalogService_GetProduct_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductCatalogService_GetProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductCatalogServiceServer).GetProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.ProductCatalogService/GetProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductCatalogServiceServer).GetProducts(ctx, req.(*GetProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProductCatalogService_SearchProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchProductsRequest)
 	if err := dec(in); err != nil {
@@ -1906,6 +3108,27 @@ func _ProductCatalogService_SearchProducts_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductCatalogService_WatchCatalog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchCatalogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProductCatalogServiceServer).WatchCatalog(m, &productCatalogServiceWatchCatalogServer{stream})
+}
+
+type ProductCatalogService_WatchCatalogServer interface {
+	Send(*CatalogEvent) error
+	grpc.ServerStream
+}
+
+type productCatalogServiceWatchCatalogServer struct {
+	grpc.ServerStream
+}
+
+func (x *productCatalogServiceWatchCatalogServer) Send(m *CatalogEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _ProductCatalogService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hipstershop.ProductCatalogService",
 	HandlerType: (*ProductCatalogServiceServer)(nil),
@@ -1918,11 +3141,196 @@ var _ProductCatalogService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetProduct",
 			Handler:    _ProductCatalogService_GetProduct_Handler,
 		},
+		{
+			MethodName: "GetProducts",
+			Handler:    _ProductCatalogService_GetProducts_Handler,
+		},
 		{
 			MethodName: "SearchProducts",
 			Handler:    _ProductCatalogService_SearchProducts_Handler,
 		},
 	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchCatalog",
+			Handler:       _ProductCatalogService_WatchCatalog_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "demo.proto",
+}
+
+// ProductCatalogAdminServiceClient is the client API for ProductCatalogAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ProductCatalogAdminServiceClient interface {
+	// Adds a product. Fails with ALREADY_EXISTS if the ID is taken.
+	CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error)
+	// Replaces the product with the same ID. Fails with NOT_FOUND if there
+	// is none.
+	UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*Product, error)
+	// Removes a product. Fails with NOT_FOUND if there is none.
+	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Creates or replaces many products at once. Either all products are
+	// imported or, if any is invalid, none is.
+	BulkImport(ctx context.Context, in *BulkImportRequest, opts ...grpc.CallOption) (*BulkImportResponse, error)
+}
+
+type productCatalogAdminServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewProductCatalogAdminServiceClient(cc *grpc.ClientConn) ProductCatalogAdminServiceClient {
+	return &productCatalogAdminServiceClient{cc}
+}
+
+func (c *productCatalogAdminServiceClient) CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/hipstershop.ProductCatalogAdminService/CreateProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productCatalogAdminServiceClient) UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/hipstershop.ProductCatalogAdminService/UpdateProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productCatalogAdminServiceClient) DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/hipstershop.ProductCatalogAdminService/DeleteProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productCatalogAdminServiceClient) BulkImport(ctx context.Context, in *BulkImportRequest, opts ...grpc.CallOption) (*BulkImportResponse, error) {
+	out := new(BulkImportResponse)
+	err := c.cc.Invoke(ctx, "/hipstershop.ProductCatalogAdminService/BulkImport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProductCatalogAdminServiceServer is the server API for ProductCatalogAdminService service.
+type ProductCatalogAdminServiceServer interface {
+	// Adds a product. Fails with ALREADY_EXISTS if the ID is taken.
+	CreateProduct(context.Context, *CreateProductRequest) (*Product, error)
+	// Replaces the product with the same ID. Fails with NOT_FOUND if there
+	// is none.
+	UpdateProduct(context.Context, *UpdateProductRequest) (*Product, error)
+	// Removes a product. Fails with NOT_FOUND if there is none.
+	DeleteProduct(context.Context, *DeleteProductRequest) (*Empty, error)
+	// Creates or replaces many products at once. Either all products are
+	// imported or, if any is invalid, none is.
+	BulkImport(context.Context, *BulkImportRequest) (*BulkImportResponse, error)
+}
+
+func RegisterProductCatalogAdminServiceServer(s *grpc.Server, srv ProductCatalogAdminServiceServer) {
+	s.RegisterService(&_ProductCatalogAdminService_serviceDesc, srv)
+}
+
+func _ProductCatalogAdminService_CreateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductCatalogAdminServiceServer).CreateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.ProductCatalogAdminService/CreateProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductCatalogAdminServiceServer).CreateProduct(ctx, req.(*CreateProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductCatalogAdminService_UpdateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductCatalogAdminServiceServer).UpdateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.ProductCatalogAdminService/UpdateProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductCatalogAdminServiceServer).UpdateProduct(ctx, req.(*UpdateProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductCatalogAdminService_DeleteProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductCatalogAdminServiceServer).DeleteProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.ProductCatalogAdminService/DeleteProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductCatalogAdminServiceServer).DeleteProduct(ctx, req.(*DeleteProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductCatalogAdminService_BulkImport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkImportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductCatalogAdminServiceServer).BulkImport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.ProductCatalogAdminService/BulkImport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductCatalogAdminServiceServer).BulkImport(ctx, req.(*BulkImportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ProductCatalogAdminService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "hipstershop.ProductCatalogAdminService",
+	HandlerType: (*ProductCatalogAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateProduct",
+			Handler:    _ProductCatalogAdminService_CreateProduct_Handler,
+		},
+		{
+			MethodName: "UpdateProduct",
+			Handler:    _ProductCatalogAdminService_UpdateProduct_Handler,
+		},
+		{
+			MethodName: "DeleteProduct",
+			Handler:    _ProductCatalogAdminService_DeleteProduct_Handler,
+		},
+		{
+			MethodName: "BulkImport",
+			Handler:    _ProductCatalogAdminService_BulkImport_Handler,
+		},
+	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "demo.proto",
 }
@@ -1933,6 +3341,10 @@ var _ProductCatalogService_serviceDesc = grpc.ServiceDesc{
 type ShippingServiceClient interface {
 	GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*GetQuoteResponse, error)
 	ShipOrder(ctx context.Context, in *ShipOrderRequest, opts ...grpc.CallOption) (*ShipOrderResponse, error)
+	GetShipmentStatus(ctx context.Context, in *GetShipmentStatusRequest, opts ...grpc.CallOption) (*ShipmentStatus, error)
+	// Streams the status of a shipment: first the current one, then every
+	// change until the shipment is delivered.
+	WatchShipment(ctx context.Context, in *WatchShipmentRequest, opts ...grpc.CallOption) (ShippingService_WatchShipmentClient, error)
 }
 
 type shippingServiceClient struct {
@@ -1961,10 +3373,55 @@ func (c *shippingServiceClient) ShipOrder(ctx context.Context, in *ShipOrderRequ
 	return out, nil
 }
 
+func (c *shippingServiceClient) GetShipmentStatus(ctx context.Context, in *GetShipmentStatusRequest, opts ...grpc.CallOption) (*ShipmentStatus, error) {
+	out := new(ShipmentStatus)
+	err := c.cc.Invoke(ctx, "/hipstershop.ShippingService/GetShipmentStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shippingServiceClient) WatchShipment(ctx context.Context, in *WatchShipmentRequest, opts ...grpc.CallOption) (ShippingService_WatchShipmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ShippingService_serviceDesc.Streams[0], "/hipstershop.ShippingService/WatchShipment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shippingServiceWatchShipmentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ShippingService_WatchShipmentClient interface {
+	Recv() (*ShipmentStatus, error)
+	grpc.ClientStream
+}
+
+type shippingServiceWatchShipmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *shippingServiceWatchShipmentClient) Recv() (*ShipmentStatus, error) {
+	m := new(ShipmentStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ShippingServiceServer is the server API for ShippingService service.
 type ShippingServiceServer interface {
 	GetQuote(context.Context, *GetQuoteRequest) (*GetQuoteResponse, error)
 	ShipOrder(context.Context, *ShipOrderRequest) (*ShipOrderResponse, error)
+	GetShipmentStatus(context.Context, *GetShipmentStatusRequest) (*ShipmentStatus, error)
+	// Streams the status of a shipment: first the current one, then every
+	// change until the shipment is delivered.
+	WatchShipment(*WatchShipmentRequest, ShippingService_WatchShipmentServer) error
 }
 
 func RegisterShippingServiceServer(s *grpc.Server, srv ShippingServiceServer) {
@@ -2007,6 +3464,45 @@ func _ShippingService_ShipOrder_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShippingService_GetShipmentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShipmentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShippingServiceServer).GetShipmentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.ShippingService/GetShipmentStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShippingServiceServer).GetShipmentStatus(ctx, req.(*GetShipmentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShippingService_WatchShipment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchShipmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShippingServiceServer).WatchShipment(m, &shippingServiceWatchShipmentServer{stream})
+}
+
+type ShippingService_WatchShipmentServer interface {
+	Send(*ShipmentStatus) error
+	grpc.ServerStream
+}
+
+type shippingServiceWatchShipmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *shippingServiceWatchShipmentServer) Send(m *ShipmentStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _ShippingService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hipstershop.ShippingService",
 	HandlerType: (*ShippingServiceServer)(nil),
@@ -2019,8 +3515,18 @@ var _ShippingService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ShipOrder",
 			Handler:    _ShippingService_ShipOrder_Handler,
 		},
+		{
+			MethodName: "GetShipmentStatus",
+			Handler:    _ShippingService_GetShipmentStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchShipment",
+			Handler:       _ShippingService_WatchShipment_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "demo.proto",
 }
 
@@ -2254,6 +3760,8 @@ var _EmailService_serviceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type CheckoutServiceClient interface {
 	PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*PlaceOrderResponse, error)
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*OrderResult, error)
+	ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
 }
 
 type checkoutServiceClient struct {
@@ -2273,9 +3781,29 @@ func (c *checkoutServiceClient) PlaceOrder(ctx context.Context, in *PlaceOrderRe
 	return out, nil
 }
 
+func (c *checkoutServiceClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*OrderResult, error) {
+	out := new(OrderResult)
+	err := c.cc.Invoke(ctx, "/hipstershop.CheckoutService/GetOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *checkoutServiceClient) ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error) {
+	out := new(ListOrdersResponse)
+	err := c.cc.Invoke(ctx, "/hipstershop.CheckoutService/ListOrders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CheckoutServiceServer is the server API for CheckoutService service.
 type CheckoutServiceServer interface {
 	PlaceOrder(context.Context, *PlaceOrderRequest) (*PlaceOrderResponse, error)
+	GetOrder(context.Context, *GetOrderRequest) (*OrderResult, error)
+	ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
 }
 
 func RegisterCheckoutServiceServer(s *grpc.Server, srv CheckoutServiceServer) {
@@ -2300,6 +3828,42 @@ func _CheckoutService_PlaceOrder_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CheckoutService_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CheckoutServiceServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.CheckoutService/GetOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CheckoutServiceServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CheckoutService_ListOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CheckoutServiceServer).ListOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hipstershop.CheckoutService/ListOrders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CheckoutServiceServer).ListOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _CheckoutService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hipstershop.CheckoutService",
 	HandlerType: (*CheckoutServiceServer)(nil),
@@ -2308,6 +3872,14 @@ var _CheckoutService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "PlaceOrder",
 			Handler:    _CheckoutService_PlaceOrder_Handler,
 		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _CheckoutService_GetOrder_Handler,
+		},
+		{
+			MethodName: "ListOrders",
+			Handler:    _CheckoutService_ListOrders_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "demo.proto",
@@ -2380,99 +3952,161 @@ var _AdService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("demo.proto", fileDescriptor_ca53982754088a9d) }
 
 var fileDescriptor_ca53982754088a9d = []byte{
-	// 1500 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xef, 0x72, 0x13, 0xb7,
-	0x16, 0xcf, 0x26, 0xb1, 0x1d, 0x1f, 0xc7, 0x4e, 0xa2, 0x9b, 0x04, 0xb3, 0x81, 0x10, 0x94, 0x81,
-	0x0b, 0x17, 0x08, 0x4c, 0xee, 0x9d, 0xe1, 0x03, 0xdc, 0xd2, 0x8c, 0xc9, 0x18, 0x4f, 0xa1, 0xd0,
-	0x0d, 0xe9, 0xd0, 0xa1, 0x53, 0xcf, 0xb2, 0x12, 0xf1, 0x96, 0xec, 0x6a, 0x91, 0xb4, 0x19, 0xcc,
-	0xc7, 0xf6, 0x01, 0xfa, 0x1e, 0x7d, 0x81, 0xce, 0xf4, 0x11, 0xfa, 0xbd, 0xaf, 0xd0, 0xe7, 0xe8,
-	0x48, 0xbb, 0xda, 0x7f, 0xb1, 0x13, 0xf8, 0xd2, 0x6f, 0xab, 0xa3, 0x9f, 0xce, 0xf9, 0xe9, 0xe8,
-	0xfc, 0xb3, 0x01, 0x08, 0x0d, 0xd8, 0x4e, 0xc4, 0x99, 0x64, 0xa8, 0x35, 0xf2, 0x23, 0x21, 0x29,
-	0x17, 0x23, 0x16, 0xe1, 0x7d, 0x58, 0xe8, 0xb9, 0x5c, 0x0e, 0x24, 0x0d, 0xd0, 0x65, 0x80, 0x88,
-	0x33, 0x12, 0x7b, 0x72, 0xe8, 0x93, 0xae, 0xb5, 0x65, 0xdd, 0x68, 0x3a, 0xcd, 0x54, 0x32, 0x20,
-	0xc8, 0x86, 0x85, 0xf7, 0xb1, 0x1b, 0x4a, 0x5f, 0x8e, 0xbb, 0xb3, 0x5b, 0xd6, 0x8d, 0x9a, 0x93,
-	0xad, 0xf1, 0x4b, 0xe8, 0xec, 0x11, 0xa2, 0xb4, 0x38, 0xf4, 0x7d, 0x4c, 0x85, 0x44, 0x17, 0xa0,
-	0x11, 0x0b, 0xca, 0x73, 0x4d, 0x75, 0xb5, 0x1c, 0x10, 0x74, 0x13, 0xe6, 0x7d, 0x49, 0x03, 0xad,
-	0xa2, 0xb5, 0xbb, 0xb6, 0x53, 0x60, 0xb3, 0x63, 0xa8, 0x38, 0x1a, 0x82, 0x6f, 0xc1, 0xf2, 0x7e,
-	0x10, 0xc9, 0xb1, 0x12, 0x9f, 0xa7, 0x17, 0xdf, 0x84, 0x4e, 0x9f, 0xca, 0x4f, 0x82, 0x3e, 0x85,
-	0x79, 0x85, 0x9b, 0xce, 0xf1, 0x16, 0xd4, 0x14, 0x01, 0xd1, 0x9d, 0xdd, 0x9a, 0x9b, 0x4e, 0x32,
-	0xc1, 0xe0, 0x06, 0xd4, 0x34, 0x4b, 0xfc, 0x2d, 0xd8, 0x4f, 0x7d, 0x21, 0x1d, 0xea, 0xb1, 0x20,
-	0xa0, 0x21, 0x71, 0xa5, 0xcf, 0x42, 0x71, 0xae, 0x43, 0xae, 0x40, 0x2b, 0x77, 0x7b, 0x62, 0xb2,
-	0xe9, 0x40, 0xe6, 0x77, 0x81, 0xbf, 0x80, 0x8d, 0x89, 0x7a, 0x45, 0xc4, 0x42, 0x41, 0xab, 0xe7,
-	0xad, 0x53, 0xe7, 0x7f, 0xb7, 0xa0, 0xf1, 0x22, 0x59, 0xa2, 0x0e, 0xcc, 0x66, 0x04, 0x66, 0x7d,
-	0x82, 0x10, 0xcc, 0x87, 0x6e, 0x40, 0xf5, 0x6b, 0x34, 0x1d, 0xfd, 0x8d, 0xb6, 0xa0, 0x45, 0xa8,
-	0xf0, 0xb8, 0x1f, 0x29, 0x43, 0xdd, 0x39, 0xbd, 0x55, 0x14, 0xa1, 0x2e, 0x34, 0x22, 0xdf, 0x93,
-	0x31, 0xa7, 0xdd, 0x79, 0xbd, 0x6b, 0x96, 0xe8, 0x2e, 0x34, 0x23, 0xee, 0x7b, 0x74, 0x18, 0x0b,
-	0xd2, 0xad, 0xe9, 0x27, 0x46, 0x25, 0xef, 0x3d, 0x63, 0x21, 0x1d, 0x3b, 0x0b, 0x1a, 0x74, 0x28,
-	0x08, 0xda, 0x04, 0xf0, 0x5c, 0x49, 0x8f, 0x18, 0xf7, 0xa9, 0xe8, 0xd6, 0x13, 0xf2, 0xb9, 0x04,
-	0x3f, 0x81, 0x55, 0x75, 0xf9, 0x94, 0x7f, 0x7e, 0xeb, 0x7b, 0xb0, 0x90, 0x5e, 0x31, 0xb9, 0x72,
-	0x6b, 0x77, 0xb5, 0x64, 0x27, 0x3d, 0xe0, 0x64, 0x28, 0xbc, 0x0d, 0x2b, 0x7d, 0x6a, 0x14, 0x99,
-	0x57, 0xa9, 0xf8, 0x03, 0xdf, 0x81, 0xb5, 0x03, 0xea, 0x72, 0x6f, 0x94, 0x1b, 0x4c, 0x80, 0xab,
-	0x50, 0x7b, 0x1f, 0x53, 0x3e, 0x4e, 0xb1, 0xc9, 0x02, 0x3f, 0x81, 0xf5, 0x2a, 0x3c, 0xe5, 0xb7,
-	0x03, 0x0d, 0x4e, 0x45, 0x7c, 0x7c, 0x0e, 0x3d, 0x03, 0xc2, 0x21, 0x2c, 0xf5, 0xa9, 0xfc, 0x26,
-	0x66, 0x92, 0x1a, 0x93, 0x3b, 0xd0, 0x70, 0x09, 0xe1, 0x54, 0x08, 0x6d, 0xb4, 0xaa, 0x62, 0x2f,
-	0xd9, 0x73, 0x0c, 0xe8, 0xf3, 0xa2, 0x76, 0x0f, 0x96, 0x73, 0x7b, 0x29, 0xe7, 0x3b, 0xb0, 0xe0,
-	0x31, 0x21, 0xf5, 0xdb, 0x59, 0x53, 0xdf, 0xae, 0xa1, 0x30, 0x87, 0x82, 0x60, 0x06, 0xcb, 0x07,
-	0x23, 0x3f, 0x7a, 0xce, 0x09, 0xe5, 0xff, 0x08, 0xe7, 0xff, 0xc1, 0x4a, 0xc1, 0x60, 0x1e, 0xfe,
-	0x92, 0xbb, 0xde, 0x3b, 0x3f, 0x3c, 0xca, 0x73, 0x0b, 0x8c, 0x68, 0x40, 0xf0, 0x2f, 0x16, 0x34,
-	0x52, 0xbb, 0xe8, 0x1a, 0x74, 0x84, 0xe4, 0x94, 0xca, 0x61, 0x91, 0x65, 0xd3, 0x69, 0x27, 0x52,
-	0x03, 0x43, 0x30, 0xef, 0x99, 0x32, 0xd7, 0x74, 0xf4, 0xb7, 0x0a, 0x00, 0x21, 0x5d, 0x49, 0xd3,
-	0x7c, 0x48, 0x16, 0x2a, 0x13, 0x3c, 0x16, 0x87, 0x92, 0x8f, 0x4d, 0x26, 0xa4, 0x4b, 0x74, 0x11,
-	0x16, 0x3e, 0xfa, 0xd1, 0xd0, 0x63, 0x84, 0xea, 0x44, 0xa8, 0x39, 0x8d, 0x8f, 0x7e, 0xd4, 0x63,
-	0x84, 0xe2, 0x57, 0x50, 0xd3, 0xae, 0x44, 0xdb, 0xd0, 0xf6, 0x62, 0xce, 0x69, 0xe8, 0x8d, 0x13,
-	0x60, 0xc2, 0x66, 0xd1, 0x08, 0x15, 0x5a, 0x19, 0x8e, 0x43, 0x5f, 0x0a, 0xcd, 0x66, 0xce, 0x49,
-	0x16, 0x4a, 0x1a, 0xba, 0x21, 0x13, 0x9a, 0x4e, 0xcd, 0x49, 0x16, 0xb8, 0x0f, 0x9b, 0x7d, 0x2a,
-	0x0f, 0xe2, 0x28, 0x62, 0x5c, 0x52, 0xd2, 0x4b, 0xf4, 0xf8, 0x34, 0x8f, 0xcb, 0x6b, 0xd0, 0x29,
-	0x99, 0x34, 0x05, 0xa3, 0x5d, 0xb4, 0x29, 0xf0, 0xf7, 0x70, 0xb1, 0x97, 0x09, 0xc2, 0x13, 0xca,
-	0x85, 0xcf, 0x42, 0xf3, 0xc8, 0xd7, 0x61, 0xfe, 0x2d, 0x67, 0xc1, 0x19, 0x31, 0xa2, 0xf7, 0x55,
-	0xc9, 0x93, 0x2c, 0xb9, 0x58, 0xe2, 0xc9, 0xba, 0x64, 0xda, 0x01, 0x7f, 0x59, 0xd0, 0xe9, 0x71,
-	0x4a, 0x7c, 0x55, 0xaf, 0xc9, 0x20, 0x7c, 0xcb, 0xd0, 0x6d, 0x40, 0x9e, 0x96, 0x0c, 0x3d, 0x97,
-	0x93, 0x61, 0x18, 0x07, 0x6f, 0x28, 0x4f, 0xfd, 0xb1, 0xec, 0x65, 0xd8, 0xaf, 0xb5, 0x1c, 0x5d,
-	0x87, 0xa5, 0x22, 0xda, 0x3b, 0x39, 0x49, 0x5b, 0x52, 0x3b, 0x87, 0xf6, 0x4e, 0x4e, 0xd0, 0xff,
-	0x61, 0xa3, 0x88, 0xa3, 0x1f, 0x22, 0x9f, 0xeb, 0xf2, 0x39, 0x1c, 0x53, 0x97, 0xa7, 0xbe, 0xeb,
-	0xe6, 0x67, 0xf6, 0x33, 0xc0, 0x77, 0xd4, 0xe5, 0xe8, 0x11, 0x5c, 0x9a, 0x72, 0x3c, 0x60, 0xa1,
-	0x1c, 0xe9, 0x27, 0xaf, 0x39, 0x17, 0x27, 0x9d, 0x7f, 0xa6, 0x00, 0x78, 0x0c, 0xed, 0xde, 0xc8,
-	0xe5, 0x47, 0x59, 0x4e, 0xff, 0x07, 0xea, 0x6e, 0xa0, 0x22, 0xe4, 0x0c, 0xe7, 0xa5, 0x08, 0xf4,
-	0x10, 0x5a, 0x05, 0xeb, 0x69, 0xc3, 0xdc, 0x28, 0x67, 0x48, 0xc9, 0x89, 0x0e, 0xe4, 0x4c, 0xf0,
-	0x7d, 0xe8, 0x18, 0xd3, 0xf9, 0xd3, 0x4b, 0xee, 0x86, 0xc2, 0xf5, 0xf4, 0x15, 0xb2, 0x64, 0x69,
-	0x17, 0xa4, 0x03, 0x82, 0x7f, 0x80, 0xa6, 0xce, 0x30, 0x3d, 0x13, 0x98, 0x6e, 0x6d, 0x9d, 0xdb,
-	0xad, 0x55, 0x54, 0xa8, 0xca, 0x90, 0xf2, 0x9c, 0x18, 0x15, 0x6a, 0x1f, 0xff, 0x34, 0x0b, 0x2d,
-	0x93, 0xc2, 0xf1, 0xb1, 0x54, 0x89, 0xc2, 0xd4, 0x32, 0x27, 0xd4, 0xd0, 0xeb, 0x01, 0x41, 0xf7,
-	0x60, 0x55, 0x8c, 0xfc, 0x28, 0x52, 0xb9, 0x5d, 0x4c, 0xf2, 0x24, 0x9a, 0x90, 0xd9, 0x7b, 0x99,
-	0x25, 0x3b, 0xba, 0x0f, 0xed, 0xec, 0x84, 0x66, 0x33, 0x37, 0x95, 0xcd, 0xa2, 0x01, 0xf6, 0x98,
-	0x90, 0xe8, 0x11, 0x2c, 0x67, 0x07, 0x4d, 0x6d, 0x98, 0x3f, 0xa3, 0x82, 0x2d, 0x19, 0xb4, 0xa9,
-	0x19, 0xb7, 0x4d, 0x25, 0xab, 0xe9, 0x4a, 0xb6, 0x5e, 0x3a, 0x95, 0x39, 0xd4, 0x94, 0x32, 0x02,
-	0x97, 0x0e, 0x68, 0x48, 0xb4, 0xbc, 0xc7, 0xc2, 0xb7, 0x3e, 0x0f, 0x74, 0xd8, 0x14, 0xda, 0x0d,
-	0x0d, 0x5c, 0xff, 0xd8, 0xb4, 0x1b, 0xbd, 0x40, 0x3b, 0x50, 0xd3, 0xae, 0x49, 0x7d, 0xdc, 0x3d,
-	0x6d, 0x23, 0xf1, 0xa9, 0x93, 0xc0, 0xf0, 0x9f, 0x16, 0xac, 0xbc, 0x38, 0x76, 0x3d, 0x5a, 0xaa,
-	0xd1, 0x53, 0x27, 0x91, 0x6d, 0x68, 0xeb, 0x0d, 0x53, 0x0a, 0x52, 0x3f, 0x2f, 0x2a, 0xa1, 0xa9,
-	0x06, 0xc5, 0x0a, 0x3f, 0xf7, 0x29, 0x15, 0x3e, 0xbb, 0x49, 0xad, 0x78, 0x93, 0x4a, 0x6c, 0xd7,
-	0x3f, 0x2f, 0xb6, 0x1f, 0x03, 0x2a, 0x5e, 0x2b, 0x6b, 0xb9, 0xa9, 0x77, 0xac, 0x4f, 0xf3, 0xce,
-	0x0e, 0x34, 0xf7, 0x88, 0x71, 0xca, 0x55, 0x58, 0xf4, 0x58, 0x28, 0xe9, 0x07, 0x39, 0x7c, 0x47,
-	0xc7, 0xa6, 0x2a, 0xb6, 0x52, 0xd9, 0x57, 0x74, 0x2c, 0xf0, 0x5d, 0x00, 0x85, 0x4f, 0xad, 0x5d,
-	0x85, 0x39, 0x97, 0x98, 0xe6, 0xbe, 0x54, 0xf1, 0x81, 0xa3, 0xf6, 0xf0, 0x03, 0x98, 0xdd, 0x23,
-	0x4a, 0xb3, 0x62, 0xce, 0xa9, 0x27, 0x87, 0x31, 0x37, 0x2f, 0xda, 0x32, 0xb2, 0x43, 0x7e, 0xac,
-	0xfa, 0x8d, 0xb2, 0x62, 0xfa, 0x8d, 0xfa, 0xde, 0xfd, 0xc3, 0x82, 0x96, 0xca, 0xb0, 0x03, 0xca,
-	0x4f, 0x7c, 0x8f, 0xa2, 0x87, 0xba, 0x8b, 0xe9, 0xa4, 0xdc, 0xa8, 0x7a, 0xbc, 0x30, 0x78, 0xdb,
-	0xe5, 0x50, 0x4f, 0x26, 0xd3, 0x19, 0xf4, 0x00, 0x1a, 0xe9, 0x74, 0x5c, 0x39, 0x5d, 0x9e, 0x99,
-	0xed, 0x95, 0x53, 0x19, 0x8e, 0x67, 0xd0, 0x97, 0xd0, 0xcc, 0xe6, 0x70, 0x74, 0xf9, 0xb4, 0xfe,
-	0xa2, 0x82, 0x89, 0xe6, 0x77, 0x7f, 0xb6, 0x60, 0xad, 0x3c, 0xbf, 0x9a, 0x6b, 0xfd, 0x08, 0xff,
-	0x9a, 0x30, 0xdc, 0xa2, 0x7f, 0x97, 0xd4, 0x4c, 0x1f, 0xab, 0xed, 0x1b, 0xe7, 0x03, 0x93, 0x07,
-	0x53, 0x2c, 0x66, 0x61, 0x2d, 0x1d, 0xbc, 0x7a, 0xae, 0x74, 0x8f, 0xd9, 0x91, 0x61, 0xd1, 0x87,
-	0xc5, 0xe2, 0x94, 0x89, 0x26, 0xdc, 0xc2, 0xbe, 0x7a, 0xca, 0x52, 0x75, 0xe8, 0xc3, 0x33, 0xe8,
-	0x31, 0x40, 0x3e, 0x64, 0xa2, 0xcd, 0xaa, 0xab, 0xcb, 0xd3, 0xa7, 0x3d, 0x71, 0x26, 0xc4, 0x33,
-	0xe8, 0x35, 0x74, 0xca, 0x63, 0x25, 0xc2, 0x25, 0xe4, 0xc4, 0x11, 0xd5, 0xde, 0x3e, 0x13, 0x93,
-	0x79, 0xe1, 0x57, 0x0b, 0x96, 0x0e, 0xd2, 0xe2, 0x65, 0xee, 0x3f, 0x80, 0x05, 0x33, 0x0d, 0xa2,
-	0x4b, 0x55, 0xd2, 0xc5, 0xa1, 0xd4, 0xbe, 0x3c, 0x65, 0x37, 0xf3, 0xc0, 0x53, 0x68, 0x66, 0x43,
-	0x5a, 0x25, 0x58, 0xaa, 0xd3, 0xa2, 0xbd, 0x39, 0x6d, 0x3b, 0x23, 0xfb, 0x9b, 0x05, 0x4b, 0xa6,
-	0xf4, 0x18, 0xb2, 0xaf, 0x61, 0x7d, 0xf2, 0x90, 0x33, 0xf1, 0xd9, 0x6e, 0x55, 0x09, 0x9f, 0x31,
-	0x1d, 0xe1, 0x19, 0xd4, 0x87, 0x46, 0x32, 0xf0, 0x48, 0x74, 0xbd, 0x9c, 0x0b, 0xd3, 0xc6, 0x21,
-	0x7b, 0x42, 0x73, 0xc1, 0x33, 0xbb, 0x87, 0xd0, 0x79, 0xe1, 0x8e, 0x03, 0x1a, 0x66, 0x19, 0xdc,
-	0x83, 0x7a, 0xd2, 0x91, 0x91, 0x5d, 0xd6, 0x5c, 0x9c, 0x10, 0xec, 0x8d, 0x89, 0x7b, 0x99, 0x43,
-	0x46, 0xb0, 0xb8, 0xaf, 0x2a, 0xa8, 0x51, 0xfa, 0x4a, 0xfd, 0x60, 0x99, 0xd0, 0x48, 0xd0, 0xcd,
-	0x4a, 0x34, 0x4c, 0x6f, 0x36, 0x53, 0x72, 0xf6, 0x0d, 0x2c, 0xf5, 0x46, 0xd4, 0x7b, 0xc7, 0xe2,
-	0xec, 0x06, 0xcf, 0x01, 0xf2, 0xba, 0x5b, 0x89, 0xee, 0x53, 0x7d, 0xc6, 0xbe, 0x32, 0x75, 0x3f,
-	0xbb, 0xcd, 0x13, 0x55, 0x82, 0x8d, 0xf6, 0x07, 0x50, 0xef, 0xab, 0x19, 0x5c, 0xa0, 0xf5, 0x6a,
-	0x39, 0x4d, 0x35, 0x5e, 0x38, 0x25, 0x37, 0x9a, 0xde, 0xd4, 0xf5, 0x9f, 0x1b, 0xff, 0xfd, 0x3b,
-	0x00, 0x00, 0xff, 0xff, 0xb2, 0xa0, 0x6e, 0x6c, 0xea, 0x10, 0x00, 0x00,
+	// 2487 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x59, 0xcd, 0x6e, 0x1b, 0xc9,
+	0x11, 0xd6, 0x90, 0xe2, 0x5f, 0x51, 0xa4, 0xa8, 0x5e, 0xc9, 0x4b, 0x53, 0xfe, 0x91, 0xdb, 0xb0,
+	0xd7, 0x8e, 0x77, 0xb5, 0x06, 0x93, 0x85, 0x0f, 0xde, 0x64, 0xc3, 0x25, 0x69, 0x99, 0x6b, 0xd9,
+	0x52, 0x86, 0x94, 0xb3, 0xc6, 0x2e, 0x42, 0x8c, 0x67, 0xda, 0xe2, 0x44, 0xe2, 0xcc, 0x78, 0xa6,
+	0xa9, 0x88, 0xbe, 0x06, 0x08, 0x90, 0x53, 0x2e, 0x41, 0x9e, 0x21, 0xa7, 0x20, 0x87, 0x00, 0x01,
+	0xf2, 0x08, 0x7b, 0xc9, 0x7d, 0x73, 0xcf, 0x21, 0x6f, 0x90, 0x5b, 0xd0, 0x3d, 0xdd, 0xf3, 0xc7,
+	0x19, 0x51, 0x46, 0x82, 0x9c, 0xc4, 0xae, 0xaa, 0xa9, 0xfe, 0xba, 0xaa, 0xba, 0xba, 0xaa, 0x04,
+	0x60, 0x90, 0xa9, 0xbd, 0xeb, 0xb8, 0x36, 0xb5, 0x51, 0x75, 0x62, 0x3a, 0x1e, 0x25, 0xae, 0x37,
+	0xb1, 0x1d, 0xdc, 0x87, 0x72, 0x57, 0x73, 0xe9, 0x80, 0x92, 0x29, 0xba, 0x0e, 0xe0, 0xb8, 0xb6,
+	0x31, 0xd3, 0xe9, 0xd8, 0x34, 0x9a, 0xca, 0x8e, 0x72, 0xaf, 0xa2, 0x56, 0x04, 0x65, 0x60, 0xa0,
+	0x16, 0x94, 0xdf, 0xce, 0x34, 0x8b, 0x9a, 0x74, 0xde, 0xcc, 0xed, 0x28, 0xf7, 0x0a, 0x6a, 0xb0,
+	0xc6, 0x23, 0xa8, 0x77, 0x0c, 0x83, 0x69, 0x51, 0xc9, 0xdb, 0x19, 0xf1, 0x28, 0xfa, 0x10, 0x4a,
+	0x33, 0x8f, 0xb8, 0xa1, 0xa6, 0x22, 0x5b, 0x0e, 0x0c, 0x74, 0x1f, 0x56, 0x4d, 0x4a, 0xa6, 0x5c,
+	0x45, 0xb5, 0xbd, 0xb5, 0x1b, 0x41, 0xb3, 0x2b, 0xa1, 0xa8, 0x5c, 0x04, 0x3f, 0x80, 0x46, 0x7f,
+	0xea, 0xd0, 0x39, 0x23, 0x2f, 0xd3, 0x8b, 0xef, 0x43, 0x7d, 0x8f, 0xd0, 0x4b, 0x89, 0xee, 0xc3,
+	0x2a, 0x93, 0xcb, 0xc6, 0xf8, 0x00, 0x0a, 0x0c, 0x80, 0xd7, 0xcc, 0xed, 0xe4, 0xb3, 0x41, 0xfa,
+	0x32, 0xb8, 0x04, 0x05, 0x8e, 0x12, 0xbf, 0x84, 0xd6, 0xbe, 0xe9, 0x51, 0x95, 0xe8, 0xf6, 0x74,
+	0x4a, 0x2c, 0x43, 0xa3, 0xa6, 0x6d, 0x79, 0x4b, 0x0d, 0x72, 0x13, 0xaa, 0xa1, 0xd9, 0xfd, 0x2d,
+	0x2b, 0x2a, 0x04, 0x76, 0xf7, 0xf0, 0x4f, 0x60, 0x3b, 0x55, 0xaf, 0xe7, 0xd8, 0x96, 0x47, 0x92,
+	0xdf, 0x2b, 0x0b, 0xdf, 0x6f, 0xc1, 0x07, 0x3f, 0xd7, 0xa8, 0x3e, 0xe9, 0x6a, 0x54, 0x3b, 0xb5,
+	0x8f, 0x05, 0x20, 0xfc, 0x0f, 0x05, 0xd6, 0x04, 0xa9, 0x7f, 0x46, 0x2c, 0x8a, 0xda, 0xb0, 0x4a,
+	0xe7, 0x0e, 0xe1, 0xf0, 0xea, 0xed, 0x1b, 0x89, 0x43, 0x87, 0x82, 0xbb, 0xa3, 0xb9, 0x43, 0x54,
+	0x2e, 0x8b, 0x76, 0xa1, 0x24, 0x76, 0x12, 0x0e, 0xdd, 0x8c, 0x7d, 0x76, 0xe8, 0xf3, 0x54, 0x29,
+	0x84, 0x9a, 0x50, 0x3a, 0x23, 0xae, 0x67, 0xda, 0x56, 0x33, 0xbf, 0xa3, 0xdc, 0xcb, 0xab, 0x72,
+	0x89, 0x9f, 0xc3, 0x2a, 0xd3, 0x8b, 0x36, 0xa1, 0x31, 0x7a, 0x75, 0xd8, 0x1f, 0x1f, 0xbd, 0x18,
+	0x1e, 0xf6, 0xbb, 0x83, 0x27, 0x83, 0x7e, 0xaf, 0xb1, 0x82, 0x2a, 0x50, 0xe8, 0xf4, 0x7a, 0xfd,
+	0x5e, 0x43, 0x41, 0x55, 0x28, 0x1d, 0x1d, 0xf6, 0x3a, 0xa3, 0x7e, 0xaf, 0x91, 0x63, 0x0b, 0xb5,
+	0xff, 0xfc, 0xe0, 0x65, 0xbf, 0xd7, 0xc8, 0x23, 0x80, 0xe2, 0xf0, 0xd5, 0x8b, 0x6e, 0xbf, 0xd7,
+	0x58, 0xc5, 0x4f, 0x60, 0xb3, 0xeb, 0x12, 0x8d, 0x12, 0x09, 0x41, 0xb8, 0x21, 0x02, 0x58, 0xb9,
+	0x04, 0x60, 0xa6, 0xe7, 0xc8, 0x31, 0xfe, 0x7b, 0x3d, 0x77, 0x61, 0xb3, 0x47, 0x4e, 0xc9, 0x82,
+	0x9e, 0x3a, 0xe4, 0x82, 0x88, 0xc8, 0x99, 0x06, 0x1e, 0xc3, 0xc6, 0x97, 0xb3, 0xd3, 0x93, 0xc1,
+	0xd4, 0xb1, 0xc3, 0x48, 0x7e, 0x08, 0x65, 0xa1, 0xc7, 0xf7, 0x6f, 0xd6, 0x6e, 0x81, 0x14, 0xb3,
+	0xb3, 0x4b, 0x9c, 0x53, 0x4d, 0x27, 0xdc, 0x2f, 0x65, 0x55, 0x2e, 0xf1, 0x6b, 0x40, 0xd1, 0x0d,
+	0x44, 0x10, 0x35, 0xa1, 0xa4, 0x73, 0x73, 0xf9, 0x58, 0x0a, 0xaa, 0x5c, 0x32, 0xce, 0x8c, 0x1b,
+	0xc0, 0x10, 0xb7, 0x5e, 0x2e, 0x19, 0xc7, 0xe0, 0x47, 0x32, 0xb8, 0x2f, 0x0b, 0xaa, 0x5c, 0xe2,
+	0xbf, 0x29, 0x50, 0x12, 0x98, 0x92, 0x07, 0x44, 0x08, 0x56, 0x2d, 0x6d, 0xea, 0xc3, 0xaa, 0xa8,
+	0xfc, 0x37, 0xda, 0x81, 0xaa, 0x41, 0x3c, 0xdd, 0x35, 0x1d, 0x2a, 0x23, 0xa3, 0xa2, 0x46, 0x49,
+	0x6c, 0x2f, 0xc7, 0xd4, 0xe9, 0xcc, 0x25, 0xcd, 0x55, 0xce, 0x95, 0x4b, 0xf4, 0x29, 0x54, 0x1c,
+	0xd7, 0xd4, 0xc9, 0x78, 0xe6, 0x19, 0xcd, 0x02, 0x77, 0x05, 0x8a, 0x19, 0xe7, 0xb9, 0x6d, 0x91,
+	0x39, 0x33, 0x8d, 0xa9, 0x93, 0x23, 0xcf, 0x40, 0x37, 0x00, 0x74, 0x8d, 0x92, 0x63, 0xdb, 0x35,
+	0x89, 0xd7, 0x2c, 0xfa, 0xd7, 0x25, 0xa4, 0xe0, 0xa7, 0xb0, 0xc9, 0xae, 0x9b, 0xc0, 0x1f, 0xde,
+	0xb3, 0xf7, 0x76, 0x02, 0xbe, 0x0d, 0x1b, 0x7b, 0x84, 0x2e, 0x71, 0xf8, 0x5d, 0x40, 0xa1, 0x50,
+	0x90, 0x2d, 0x1a, 0x90, 0x0f, 0x2f, 0x33, 0xfb, 0x89, 0x27, 0xf0, 0xc1, 0x1e, 0xf9, 0x1f, 0xa0,
+	0x62, 0xf9, 0x62, 0x6a, 0x7a, 0x9e, 0x69, 0x1d, 0x47, 0xf3, 0x8d, 0x20, 0xb1, 0x7c, 0xf1, 0x5b,
+	0x05, 0xb6, 0x86, 0x44, 0x73, 0xf5, 0x49, 0x12, 0xd5, 0x26, 0x14, 0xde, 0xce, 0x88, 0x3b, 0x17,
+	0xf0, 0xfd, 0x45, 0xc2, 0xa0, 0xb9, 0xa4, 0x41, 0xd1, 0x36, 0x54, 0x1c, 0xed, 0x98, 0x8c, 0x3d,
+	0xf3, 0x1d, 0x11, 0x91, 0x52, 0x66, 0x84, 0xa1, 0xf9, 0x8e, 0xf0, 0x47, 0x87, 0x31, 0xa9, 0x7d,
+	0x42, 0x2c, 0xe1, 0x5b, 0x2e, 0x3e, 0x62, 0x04, 0xfc, 0x3b, 0x05, 0xae, 0x24, 0xb1, 0x88, 0x93,
+	0xef, 0xb2, 0x10, 0xf7, 0x66, 0xa7, 0x4b, 0x0e, 0x2e, 0x85, 0xd0, 0x5d, 0x58, 0xb7, 0xc8, 0x39,
+	0x1d, 0x47, 0xb6, 0xf3, 0x63, 0xb0, 0xc6, 0xc8, 0x87, 0x72, 0x4b, 0x86, 0x88, 0xda, 0x54, 0x3b,
+	0x8d, 0xe2, 0xad, 0x70, 0x0a, 0x03, 0x8c, 0xbf, 0x53, 0x60, 0x7d, 0x8f, 0xd0, 0x9f, 0xcd, 0x6c,
+	0x4a, 0x22, 0xc9, 0x40, 0x33, 0x0c, 0x97, 0x78, 0x5e, 0x6a, 0x32, 0xe8, 0xf8, 0x3c, 0x55, 0x0a,
+	0xbd, 0xd7, 0xfb, 0x82, 0x3e, 0x83, 0x35, 0x6f, 0xf6, 0xda, 0x87, 0xc4, 0x62, 0x3c, 0x9f, 0x19,
+	0xe3, 0x55, 0x29, 0xc7, 0xc2, 0xfc, 0x36, 0xd4, 0x3c, 0xe2, 0x9e, 0xb1, 0x9b, 0x71, 0x4a, 0xce,
+	0xc8, 0xa9, 0xb0, 0xed, 0x9a, 0x20, 0xee, 0x33, 0x1a, 0x3e, 0x87, 0x46, 0x78, 0x16, 0x61, 0xd7,
+	0x4f, 0xa0, 0xac, 0xdb, 0x1e, 0xe5, 0x7b, 0x29, 0x99, 0x7b, 0x95, 0x98, 0x0c, 0xdb, 0xe7, 0x33,
+	0x28, 0xd9, 0xfc, 0x8e, 0xca, 0xd3, 0x6c, 0xc7, 0xa4, 0x87, 0x13, 0xd3, 0x71, 0x4c, 0xeb, 0xf8,
+	0x80, 0xcb, 0xa8, 0x52, 0x16, 0xff, 0x49, 0x81, 0x7a, 0x9c, 0xb7, 0x88, 0x58, 0x59, 0x44, 0x9c,
+	0x9a, 0x3e, 0xa2, 0x88, 0xf3, 0xcb, 0x11, 0x5f, 0x85, 0xf2, 0xd4, 0xb4, 0xc6, 0x86, 0x36, 0xf7,
+	0xb8, 0x51, 0x0a, 0x6a, 0x69, 0x6a, 0x5a, 0x3d, 0x6d, 0xee, 0x71, 0x96, 0x76, 0xee, 0xb3, 0x0a,
+	0x82, 0xa5, 0x9d, 0x33, 0x16, 0xfe, 0xbd, 0x02, 0x0d, 0x06, 0xf8, 0xc0, 0x35, 0x88, 0xfb, 0x7f,
+	0x71, 0xfc, 0x82, 0x3d, 0xf2, 0x29, 0x1e, 0xfc, 0x83, 0x02, 0x1b, 0x11, 0x58, 0x61, 0x4d, 0x40,
+	0x5d, 0x4d, 0x3f, 0xf1, 0x2f, 0xb9, 0x30, 0x24, 0x48, 0xd2, 0xc0, 0x88, 0x02, 0xcf, 0x5d, 0x06,
+	0x38, 0x4b, 0x33, 0x9a, 0x7e, 0xa2, 0x1d, 0x13, 0xaf, 0x99, 0x4f, 0xbb, 0x6d, 0x3e, 0x53, 0x0d,
+	0xa4, 0xf0, 0x6f, 0xd8, 0x1b, 0xe0, 0x2f, 0x96, 0xc3, 0x69, 0x40, 0xfe, 0xb5, 0x7d, 0x2e, 0x9c,
+	0xca, 0x7e, 0x86, 0x96, 0xca, 0x5f, 0xc2, 0x52, 0xdb, 0x50, 0xf9, 0x15, 0x31, 0x8f, 0x27, 0x74,
+	0x7c, 0x72, 0xcc, 0x5d, 0xaa, 0xa8, 0x65, 0x9f, 0xf0, 0xec, 0x18, 0x3f, 0x86, 0xe6, 0x1e, 0xa1,
+	0xcc, 0x46, 0x53, 0x62, 0xd1, 0x21, 0xd5, 0xe8, 0x2c, 0x48, 0x68, 0xcb, 0x80, 0xe1, 0x47, 0xb0,
+	0xc9, 0x6b, 0x27, 0xf9, 0xf9, 0xa5, 0x3f, 0xfc, 0x3e, 0x07, 0x75, 0xf9, 0x91, 0xbf, 0xe7, 0x72,
+	0x2b, 0x3c, 0x82, 0x82, 0x47, 0x35, 0xea, 0x07, 0x77, 0xbd, 0x7d, 0x6b, 0xe1, 0x22, 0x85, 0xca,
+	0x76, 0xd9, 0x1f, 0xa2, 0xfa, 0xf2, 0x97, 0x8a, 0x14, 0xd4, 0x86, 0x22, 0x61, 0xe5, 0x1b, 0x0b,
+	0x7a, 0x66, 0xd2, 0x56, 0xaa, 0x7a, 0x5e, 0xe1, 0xa9, 0x42, 0x12, 0x7d, 0x02, 0x88, 0x78, 0xd4,
+	0x9c, 0xb2, 0xf7, 0x7e, 0x6c, 0x90, 0x53, 0xf3, 0x8c, 0x65, 0xff, 0x02, 0xaf, 0xdc, 0x36, 0x02,
+	0x4e, 0x4f, 0x30, 0xf0, 0x1b, 0x28, 0x70, 0x5c, 0x68, 0x0b, 0x36, 0x86, 0xa3, 0xce, 0x28, 0x59,
+	0xc5, 0x6d, 0x40, 0x6d, 0xbf, 0xf3, 0x65, 0x7f, 0x7f, 0xdc, 0x55, 0xfb, 0xbc, 0x80, 0x53, 0x50,
+	0x1d, 0x60, 0xf0, 0x62, 0x3c, 0x52, 0x3b, 0x2f, 0x86, 0x83, 0x51, 0x23, 0xc7, 0xca, 0xbf, 0x83,
+	0xa3, 0xd1, 0xf8, 0xc9, 0x81, 0x3a, 0xee, 0xf5, 0xf7, 0x07, 0x2f, 0xfb, 0xea, 0xab, 0x46, 0x1e,
+	0xd5, 0xa0, 0x22, 0x56, 0xbc, 0xb8, 0xfb, 0x16, 0x6a, 0x31, 0xbc, 0xa1, 0xe5, 0x94, 0xf7, 0xb4,
+	0x1c, 0x82, 0x55, 0x6a, 0x8a, 0x74, 0x92, 0x57, 0xf9, 0x6f, 0xfc, 0x67, 0x05, 0x4a, 0xe2, 0x02,
+	0xa0, 0x3b, 0x50, 0xf7, 0xa8, 0x4b, 0x08, 0x1d, 0x47, 0xef, 0x79, 0x45, 0xad, 0xf9, 0x54, 0x29,
+	0x86, 0x60, 0x55, 0x97, 0x7d, 0x51, 0x45, 0xe5, 0xbf, 0xd9, 0x63, 0xe9, 0x63, 0xf2, 0x9d, 0x21,
+	0x36, 0x64, 0x85, 0x96, 0x3d, 0xb3, 0xa8, 0x3b, 0x97, 0x85, 0x8c, 0x58, 0xb2, 0xdc, 0xf3, 0xce,
+	0x74, 0xc6, 0xba, 0x6d, 0x10, 0x99, 0x7b, 0xde, 0x99, 0x4e, 0xd7, 0x36, 0xfc, 0x12, 0xdf, 0xf6,
+	0xd8, 0x03, 0xc0, 0xb9, 0x45, 0x3f, 0x72, 0x7c, 0x12, 0x13, 0xc0, 0x5f, 0x43, 0x81, 0x27, 0x39,
+	0x16, 0x09, 0xfa, 0xcc, 0x75, 0x89, 0xa5, 0xcf, 0x7d, 0x59, 0x91, 0x43, 0x25, 0x91, 0xab, 0xdb,
+	0x84, 0xc2, 0xcc, 0x32, 0xa9, 0x27, 0x4e, 0xed, 0x2f, 0x18, 0xd5, 0xd2, 0x2c, 0xdb, 0x13, 0x4f,
+	0x9e, 0xbf, 0xc0, 0x7b, 0x70, 0x83, 0xdd, 0x9e, 0x99, 0xc3, 0xca, 0x45, 0x62, 0x74, 0x7d, 0x3d,
+	0x26, 0x09, 0xdf, 0xe1, 0x3b, 0x50, 0x8f, 0x6d, 0x29, 0xab, 0x96, 0x5a, 0x74, 0x4f, 0x0f, 0x7f,
+	0x0b, 0x57, 0xbb, 0x01, 0xc1, 0x12, 0x55, 0xbf, 0xbc, 0x4e, 0x77, 0x61, 0xf5, 0x8d, 0x6b, 0x4f,
+	0x2f, 0x78, 0x6f, 0x38, 0x9f, 0x35, 0x51, 0xd4, 0xf6, 0x0f, 0xe6, 0x9b, 0xba, 0x48, 0x6d, 0x6e,
+	0x80, 0x7f, 0x2a, 0x50, 0xef, 0xba, 0xc4, 0x30, 0x59, 0x07, 0x68, 0x0c, 0xac, 0x37, 0x36, 0xfa,
+	0x18, 0x90, 0xce, 0x29, 0x63, 0x5d, 0x73, 0x8d, 0xb1, 0x35, 0x9b, 0xbe, 0x26, 0xae, 0xb0, 0x47,
+	0x43, 0x0f, 0x64, 0x5f, 0x70, 0x3a, 0xab, 0x0e, 0xa2, 0xd2, 0xfa, 0xd9, 0x99, 0x28, 0x77, 0x6b,
+	0xa1, 0x68, 0xf7, 0xec, 0x0c, 0xfd, 0x18, 0xb6, 0xa3, 0x72, 0xe4, 0xdc, 0x31, 0x5d, 0xde, 0x90,
+	0x8d, 0xe7, 0x44, 0x73, 0x85, 0xed, 0x9a, 0xe1, 0x37, 0xfd, 0x40, 0xe0, 0x15, 0xd1, 0x5c, 0xf4,
+	0x05, 0x5c, 0xcb, 0xf8, 0x7c, 0x6a, 0x5b, 0x74, 0x22, 0xde, 0xa3, 0xab, 0x69, 0xdf, 0x3f, 0x67,
+	0x02, 0x78, 0x0e, 0xb5, 0xee, 0x44, 0x73, 0x8f, 0x83, 0xda, 0xe3, 0x07, 0x50, 0xd4, 0xa6, 0x2c,
+	0x84, 0x2e, 0x30, 0x9e, 0x90, 0x40, 0x9f, 0x43, 0x35, 0xb2, 0xbb, 0xc8, 0xfc, 0xf1, 0xf7, 0x3a,
+	0x6e, 0x44, 0x15, 0x42, 0x24, 0xf8, 0x11, 0xd4, 0xe5, 0xd6, 0xa1, 0xeb, 0xa9, 0xab, 0x59, 0x9e,
+	0xa6, 0xf3, 0x23, 0x04, 0x49, 0xad, 0x16, 0xa1, 0x0e, 0x0c, 0xfc, 0x0b, 0xa8, 0xf0, 0xe7, 0x89,
+	0x4f, 0x19, 0x64, 0xff, 0xaf, 0x2c, 0xed, 0xff, 0x59, 0x54, 0xb0, 0x37, 0xbb, 0x99, 0xcb, 0x3c,
+	0x18, 0xe7, 0xe3, 0x7f, 0xe5, 0xa0, 0x2a, 0xdf, 0xbf, 0xd9, 0x29, 0x65, 0x37, 0xc9, 0x66, 0xcb,
+	0x10, 0x50, 0x89, 0xaf, 0x07, 0x06, 0x7a, 0x08, 0x9b, 0x9e, 0xa8, 0x3a, 0xc6, 0xd1, 0x64, 0xec,
+	0x47, 0x13, 0x92, 0xbc, 0x51, 0x34, 0x29, 0xd7, 0x82, 0x2f, 0x38, 0x9a, 0xec, 0x0a, 0x63, 0x4d,
+	0x0a, 0x76, 0x6d, 0x8f, 0xa2, 0x2f, 0xa0, 0x11, 0x7c, 0x28, 0x93, 0xc7, 0xea, 0x05, 0x6f, 0xed,
+	0xba, 0x94, 0x16, 0x04, 0xf4, 0xb1, 0x7c, 0x02, 0x0b, 0x3c, 0x5f, 0x5f, 0x89, 0x7d, 0x15, 0x18,
+	0x54, 0xbe, 0x81, 0x3f, 0x82, 0x2b, 0xc1, 0x76, 0xf1, 0xc7, 0xc0, 0x4f, 0x17, 0xc1, 0xb9, 0x87,
+	0xd1, 0x47, 0x21, 0xfa, 0xae, 0x97, 0x2e, 0xf5, 0xae, 0x1b, 0x70, 0x6d, 0x48, 0x2c, 0x83, 0xef,
+	0xdf, 0xb5, 0xad, 0x37, 0xa6, 0x3b, 0xe5, 0xe1, 0x19, 0xe9, 0x11, 0xc8, 0x54, 0x33, 0x65, 0xf5,
+	0xe6, 0x2f, 0xd0, 0x2e, 0x14, 0xb8, 0x0b, 0x84, 0x2f, 0x9b, 0x8b, 0x67, 0xf1, 0x7d, 0xa7, 0xfa,
+	0x62, 0xf8, 0x2f, 0x39, 0xd8, 0x38, 0x64, 0xfd, 0x6a, 0xac, 0xdc, 0xca, 0x9c, 0xa1, 0xdc, 0x86,
+	0x1a, 0x67, 0xc8, 0x94, 0x23, 0xfc, 0xb9, 0xc6, 0x88, 0x32, 0xeb, 0x44, 0x6b, 0x9e, 0xfc, 0x65,
+	0x6a, 0x9e, 0xe0, 0x24, 0x85, 0xe8, 0x49, 0x12, 0x77, 0xa8, 0xf8, 0x5e, 0x77, 0x08, 0x7d, 0x04,
+	0xeb, 0xa6, 0x41, 0xa6, 0x8e, 0x4d, 0x79, 0xbe, 0x3c, 0x21, 0xf3, 0x66, 0x89, 0x6b, 0xaf, 0x47,
+	0xc8, 0xcf, 0xc8, 0xfc, 0x02, 0x77, 0x96, 0xb3, 0xdd, 0x89, 0x7b, 0x80, 0xa2, 0x56, 0x0b, 0x3a,
+	0x25, 0x61, 0x7c, 0xe5, 0x72, 0xc6, 0xef, 0xf3, 0x0e, 0x27, 0x66, 0xf9, 0x0b, 0xae, 0x54, 0xc4,
+	0x29, 0xb9, 0xd8, 0x98, 0x6d, 0x02, 0x1b, 0xac, 0x91, 0xe6, 0x7a, 0x96, 0x8f, 0xc1, 0x62, 0x5d,
+	0x62, 0xee, 0xc2, 0x2e, 0x31, 0x9f, 0xec, 0x12, 0x2d, 0x40, 0xd1, 0x9d, 0x82, 0xd6, 0xb8, 0xc8,
+	0x31, 0xca, 0xfe, 0x30, 0xfb, 0xdc, 0x42, 0xee, 0xb2, 0x2d, 0x22, 0xde, 0x85, 0x4a, 0xc7, 0x90,
+	0x27, 0xba, 0x05, 0x6b, 0xba, 0x6d, 0x51, 0xf6, 0xdd, 0x09, 0x99, 0xcb, 0xd7, 0xaf, 0x2a, 0x68,
+	0xcf, 0xc8, 0xdc, 0xc3, 0x9f, 0x02, 0x74, 0x8c, 0x00, 0xd7, 0x2d, 0xc8, 0x6b, 0x86, 0x04, 0xb5,
+	0x9e, 0x88, 0x41, 0x95, 0xf1, 0xf0, 0x63, 0xc8, 0x75, 0x0c, 0xa6, 0x99, 0x45, 0x8e, 0x4b, 0x74,
+	0x3a, 0x9e, 0xb9, 0xf2, 0x46, 0x55, 0x25, 0xed, 0xc8, 0xe5, 0xed, 0x10, 0xdb, 0x45, 0x16, 0x1e,
+	0xec, 0x77, 0xfb, 0x3b, 0x05, 0xaa, 0x2c, 0x93, 0x8a, 0xc8, 0x40, 0x9f, 0xf3, 0x72, 0x86, 0x27,
+	0xdf, 0xed, 0x64, 0xc4, 0x47, 0x46, 0xb6, 0xad, 0x78, 0x4a, 0xf3, 0x67, 0x9a, 0x2b, 0xe8, 0x31,
+	0x94, 0xc4, 0x5c, 0x35, 0xf1, 0x75, 0x7c, 0xda, 0xda, 0xda, 0x58, 0xc8, 0xe4, 0x78, 0x05, 0xfd,
+	0x14, 0x2a, 0xc1, 0x04, 0x17, 0x5d, 0x5f, 0xd4, 0x1f, 0x55, 0x90, 0xba, 0x7d, 0xfb, 0xd7, 0x0a,
+	0x6c, 0xc5, 0x27, 0x9f, 0xf2, 0x58, 0xbf, 0x84, 0x0f, 0x52, 0xc6, 0xa2, 0xe8, 0xa3, 0x98, 0x9a,
+	0xec, 0x81, 0x6c, 0xeb, 0xde, 0x72, 0x41, 0xdf, 0x61, 0x78, 0xa5, 0xfd, 0xc7, 0x3c, 0x6c, 0x89,
+	0x81, 0x82, 0x98, 0x84, 0x4a, 0x14, 0x7b, 0xb0, 0x16, 0x9d, 0x16, 0xa1, 0x94, 0x53, 0xb4, 0x6e,
+	0x2d, 0xec, 0x94, 0x1c, 0x66, 0xe0, 0x15, 0xd4, 0x03, 0x08, 0xe7, 0x3b, 0xe8, 0x46, 0xd2, 0xd4,
+	0xf1, 0x29, 0x52, 0x2b, 0x75, 0xd6, 0x81, 0x57, 0x90, 0x0a, 0xd5, 0x50, 0xd8, 0x43, 0x37, 0x33,
+	0xd4, 0x04, 0x46, 0xd8, 0xc9, 0x16, 0x08, 0x90, 0x7d, 0x03, 0xf5, 0xf8, 0x08, 0x06, 0xe1, 0x78,
+	0x7d, 0x9d, 0x36, 0x2b, 0x6a, 0xdd, 0xbe, 0x50, 0x26, 0x50, 0x7e, 0x00, 0x6b, 0xd1, 0xe1, 0x34,
+	0x8a, 0x03, 0x4a, 0x99, 0x5b, 0xb7, 0xae, 0x66, 0x0e, 0xa6, 0xf1, 0xca, 0x43, 0xa5, 0xfd, 0xf7,
+	0x1c, 0xb4, 0xe2, 0xae, 0xea, 0x18, 0x53, 0x33, 0x88, 0x9a, 0xaf, 0xa0, 0x16, 0x9b, 0x0b, 0xa3,
+	0x5b, 0xc9, 0xd4, 0xbd, 0x30, 0xeb, 0xcd, 0x34, 0xf6, 0x57, 0x50, 0x8b, 0xcd, 0x86, 0x13, 0xba,
+	0xd2, 0xe6, 0xc6, 0x99, 0xba, 0x9e, 0x42, 0x2d, 0x36, 0x1f, 0x4e, 0xe8, 0x4a, 0x9b, 0x1d, 0x67,
+	0x5c, 0xd8, 0x03, 0x80, 0x70, 0xc0, 0x9b, 0x08, 0xa4, 0x85, 0xd1, 0x72, 0xeb, 0x66, 0x26, 0x3f,
+	0x08, 0xfe, 0xef, 0x73, 0xb0, 0x3e, 0x8c, 0xbf, 0x36, 0x68, 0x00, 0x65, 0x39, 0x38, 0x42, 0xd7,
+	0x92, 0x31, 0x14, 0x9d, 0x8d, 0xb5, 0xae, 0x67, 0x70, 0x83, 0x08, 0xd8, 0x87, 0x4a, 0x30, 0xc0,
+	0x48, 0xe4, 0x88, 0xe4, 0xbc, 0xa5, 0x75, 0x23, 0x8b, 0x1d, 0x68, 0x7b, 0xc5, 0x67, 0xae, 0x89,
+	0xce, 0xfb, 0x4e, 0x12, 0x43, 0xea, 0x34, 0xa0, 0xb5, 0x7d, 0x41, 0xdb, 0x88, 0x57, 0xd0, 0x10,
+	0x6a, 0xb1, 0x59, 0x40, 0xc2, 0x45, 0x69, 0x73, 0x82, 0x25, 0x2a, 0x1f, 0x2a, 0xed, 0xbf, 0x2a,
+	0xb0, 0x2e, 0x2b, 0x14, 0x69, 0xdc, 0x6f, 0xe0, 0x4a, 0x7a, 0xcf, 0x95, 0x9a, 0x5d, 0x1e, 0x2c,
+	0x1c, 0x2e, 0xbb, 0x59, 0xc3, 0x2b, 0x68, 0x0f, 0x4a, 0x7e, 0xff, 0x45, 0xd1, 0xdd, 0x78, 0xe8,
+	0x67, 0x75, 0x67, 0xad, 0x94, 0x5a, 0x17, 0xaf, 0xb4, 0x8f, 0xa0, 0x7e, 0xa8, 0xcd, 0xf9, 0x71,
+	0x04, 0xee, 0x2e, 0x14, 0xfd, 0x06, 0x01, 0xc5, 0x67, 0x0b, 0xb1, 0x86, 0xa5, 0xb5, 0x9d, 0xca,
+	0x0b, 0xa2, 0x6d, 0x02, 0x6b, 0x7d, 0x56, 0x68, 0x49, 0xa5, 0x5f, 0xc3, 0x56, 0x6a, 0xbd, 0x89,
+	0xee, 0x27, 0x12, 0x4c, 0x76, 0x4d, 0x9a, 0xf1, 0xb4, 0xfc, 0x9b, 0x99, 0x7e, 0x42, 0xf4, 0x13,
+	0x7b, 0x16, 0x1c, 0xe1, 0x00, 0x20, 0x2c, 0xa0, 0x12, 0x97, 0x67, 0xa1, 0x1e, 0x6d, 0xdd, 0xcc,
+	0xe4, 0x47, 0xd2, 0x7a, 0x59, 0xd6, 0x52, 0x8b, 0x17, 0x25, 0xa6, 0x2c, 0xb3, 0x3c, 0xf1, 0xef,
+	0x74, 0x58, 0xe0, 0x24, 0x60, 0x2d, 0xd4, 0x58, 0xad, 0x9b, 0x99, 0xfc, 0xc0, 0xca, 0x4f, 0x59,
+	0x05, 0x23, 0x0f, 0xfd, 0x18, 0x8a, 0x7b, 0x6c, 0x96, 0xe1, 0xa1, 0x2b, 0xc9, 0x6a, 0x44, 0x68,
+	0xfc, 0x70, 0x81, 0x2e, 0x35, 0xbd, 0x2e, 0xf2, 0xff, 0x2a, 0xff, 0xf0, 0x3f, 0x03, 0x00, 0x12,
+	0xf6, 0x59, 0x70, 0x63, 0x1e, 0x00, 0x00,
 }
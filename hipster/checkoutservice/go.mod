@@ -6,11 +6,14 @@ require (
 	cloud.google.com/go v0.65.0
 	contrib.go.opencensus.io/exporter/jaeger v0.2.0
 	contrib.go.opencensus.io/exporter/stackdriver v0.5.0
+	github.com/GoogleCloudPlatform/microservices-demo/src/observability v0.0.0
 	github.com/golang/protobuf v1.4.3
 	github.com/google/uuid v1.1.2
 	github.com/konsorten/go-windows-terminal-sequences v1.0.2 // indirect
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/sirupsen/logrus v1.4.2
 	github.com/uber/jaeger-client-go v2.21.1+incompatible // indirect
+	go.etcd.io/bbolt v1.3.5
 	go.opencensus.io v0.22.4
 	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.15.1
 	go.opentelemetry.io/otel v0.15.0
@@ -19,5 +22,9 @@ require (
 	go.opentelemetry.io/otel/exporters/trace/jaeger v0.15.0
 	go.opentelemetry.io/otel/sdk v0.15.0
 	golang.org/x/net v0.0.0-20200822124328-c89045814202
+	golang.org/x/sync v0.0.0-20200625203802-6e8e738ad208
+	google.golang.org/genproto v0.0.0-20200904004341-0bd0a958aa1d
 	google.golang.org/grpc v1.34.0
 )
+
+replace github.com/GoogleCloudPlatform/microservices-demo/src/observability => ../observability
@@ -0,0 +1,314 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+)
+
+const (
+	// idempotencyKeyHeader is the request metadata that carries the
+	// idempotency key when it is not set in PlaceOrderRequest.
+	idempotencyKeyHeader = "idempotency-key"
+
+	defaultIdempotencyWindow = 24 * time.Hour
+
+	// idempotencySweepInterval is how often Put removes every expired
+	// result, so that stores do not grow with keys that are never read
+	// again.
+	idempotencySweepInterval = time.Minute
+)
+
+// idempotencyStore remembers the result of completed orders by idempotency
+// key for a limited time.
+type idempotencyStore interface {
+	// Get returns the order stored for key, if it has not expired.
+	Get(key string) (*pb.OrderResult, bool, error)
+	// Put stores order for key until ttl elapses.
+	Put(key string, order *pb.OrderResult, ttl time.Duration) error
+	Close() error
+}
+
+// idempotencyKey returns the idempotency key of req, scoped to the user so
+// that keys chosen by different users never collide. It returns "" when the
+// request carries no key.
+func idempotencyKey(ctx context.Context, req *pb.PlaceOrderRequest) string {
+	key := req.GetIdempotencyKey()
+	if key == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(idempotencyKeyHeader); len(v) > 0 {
+				key = v[0]
+			}
+		}
+	}
+	if key == "" {
+		return ""
+	}
+	return req.GetUserId() + "/" + key
+}
+
+// newIdempotencyStoreFromEnv creates the store selected by the
+// IDEMPOTENCY_STORE environment variable ("memory" or "bolt").
+func newIdempotencyStoreFromEnv() (idempotencyStore, error) {
+	switch kind := os.Getenv("IDEMPOTENCY_STORE"); kind {
+	case "", "memory":
+		return newMemoryIdempotencyStore(), nil
+	case "bolt":
+		path := os.Getenv("IDEMPOTENCY_DB_PATH")
+		if path == "" {
+			path = "idempotency.db"
+		}
+		return newBoltIdempotencyStore(path)
+	default:
+		return nil, fmt.Errorf("unknown idempotency store %q", kind)
+	}
+}
+
+// idempotencyWindowFromEnv parses IDEMPOTENCY_WINDOW as a time.Duration.
+func idempotencyWindowFromEnv() (time.Duration, error) {
+	s := os.Getenv("IDEMPOTENCY_WINDOW")
+	if s == "" {
+		return defaultIdempotencyWindow, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse IDEMPOTENCY_WINDOW (%s) as time.Duration: %+v", s, err)
+	}
+	return d, nil
+}
+
+type memoryEntry struct {
+	order     *pb.OrderResult
+	expiresAt time.Time
+}
+
+// memoryIdempotencyStore keeps results in process memory. Results are lost
+// when the service restarts.
+type memoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	now       func() time.Time
+	nextSweep time.Time
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+func (s *memoryIdempotencyStore) Get(key string) (*pb.OrderResult, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !s.now().Before(e.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return e.order, true, nil
+}
+
+func (s *memoryIdempotencyStore) Put(key string, order *pb.OrderResult, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if !now.Before(s.nextSweep) {
+		for k, e := range s.entries {
+			if !now.Before(e.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(idempotencySweepInterval)
+	}
+	s.entries[key] = memoryEntry{order: order, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Close() error { return nil }
+
+var idempotencyBucket = []byte("idempotency")
+
+// boltIdempotencyStore keeps results in a BoltDB file so that they survive
+// restarts. Each value is the expiry time in Unix nanoseconds followed by
+// the serialized OrderResult.
+type boltIdempotencyStore struct {
+	db  *bolt.DB
+	now func() time.Time
+
+	mu        sync.Mutex
+	nextSweep time.Time
+}
+
+func newBoltIdempotencyStore(path string) (*boltIdempotencyStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open idempotency database %s: %+v", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(idempotencyBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create idempotency bucket: %+v", err)
+	}
+	return &boltIdempotencyStore{db: db, now: time.Now}, nil
+}
+
+func (s *boltIdempotencyStore) Get(key string) (*pb.OrderResult, bool, error) {
+	var order *pb.OrderResult
+	expired := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(idempotencyBucket).Get([]byte(key))
+		if len(v) < 8 {
+			return nil
+		}
+		expiresAt := time.Unix(0, int64(binary.BigEndian.Uint64(v[:8])))
+		if !s.now().Before(expiresAt) {
+			expired = true
+			return nil
+		}
+		order = new(pb.OrderResult)
+		return proto.Unmarshal(v[8:], order)
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read idempotency key: %+v", err)
+	}
+	if expired {
+		err = s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(idempotencyBucket).Delete([]byte(key))
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to delete expired idempotency key: %+v", err)
+		}
+	}
+	return order, order != nil, nil
+}
+
+func (s *boltIdempotencyStore) Put(key string, order *pb.OrderResult, ttl time.Duration) error {
+	b, err := proto.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to serialize order: %+v", err)
+	}
+	now := s.now()
+	v := make([]byte, 8+len(b))
+	binary.BigEndian.PutUint64(v[:8], uint64(now.Add(ttl).UnixNano()))
+	copy(v[8:], b)
+
+	s.mu.Lock()
+	sweep := !now.Before(s.nextSweep)
+	if sweep {
+		s.nextSweep = now.Add(idempotencySweepInterval)
+	}
+	s.mu.Unlock()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(idempotencyBucket)
+		if sweep {
+			if err := sweepExpired(bucket, now); err != nil {
+				return fmt.Errorf("failed to delete expired idempotency keys: %+v", err)
+			}
+		}
+		return bucket.Put([]byte(key), v)
+	})
+}
+
+// sweepExpired deletes every result in bucket that expired by now.
+func sweepExpired(bucket *bolt.Bucket, now time.Time) error {
+	// Deleting while iterating makes the cursor skip keys, so the expired
+	// keys are collected first.
+	var expired [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		if len(v) < 8 || !now.Before(time.Unix(0, int64(binary.BigEndian.Uint64(v[:8])))) {
+			expired = append(expired, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range expired {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *boltIdempotencyStore) Close() error { return s.db.Close() }
+
+// keyedMutex serializes requests that share an idempotency key, so that a
+// retry arriving while the first request is still running waits for its
+// result instead of placing a second order.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is held while its channel holds a value; a channel rather than
+// a sync.Mutex so that waiting for it can be abandoned.
+type keyedLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+// lock acquires the lock for key and returns the function that releases it.
+// It gives up and returns the error of ctx when ctx is done first.
+func (m *keyedMutex) lock(ctx context.Context, key string) (func(), error) {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{ch: make(chan struct{}, 1)}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	select {
+	case l.ch <- struct{}{}:
+	case <-ctx.Done():
+		m.release(key, l)
+		return nil, ctx.Err()
+	}
+	return func() {
+		<-l.ch
+		m.release(key, l)
+	}, nil
+}
+
+// release drops a reference to the lock of key.
+func (m *keyedMutex) release(key string, l *keyedLock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(m.locks, key)
+	}
+}
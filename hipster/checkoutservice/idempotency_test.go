@@ -0,0 +1,175 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	withHeader := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(idempotencyKeyHeader, "from-header"))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  *pb.PlaceOrderRequest
+		want string
+	}{
+		{"none", context.Background(), &pb.PlaceOrderRequest{UserId: "u1"}, ""},
+		{"field", context.Background(), &pb.PlaceOrderRequest{UserId: "u1", IdempotencyKey: "k"}, "u1/k"},
+		{"metadata", withHeader, &pb.PlaceOrderRequest{UserId: "u1"}, "u1/from-header"},
+		{"field wins", withHeader, &pb.PlaceOrderRequest{UserId: "u2", IdempotencyKey: "k"}, "u2/k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idempotencyKey(tt.ctx, tt.req); got != tt.want {
+				t.Errorf("idempotencyKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// testIdempotencyStore checks s, whose clock is set by setNow and whose
+// number of stored results, expired or not, is returned by size.
+func testIdempotencyStore(t *testing.T, s idempotencyStore, setNow func(time.Time), size func() int) {
+	now := time.Unix(1600000000, 0)
+	setNow(now)
+	order := &pb.OrderResult{OrderId: "order-1", ShippingTrackingId: "tracking-1"}
+
+	if _, ok, err := s.Get("u/k"); err != nil || ok {
+		t.Fatalf("Get() on empty store = %v, %v; want no result", ok, err)
+	}
+	if err := s.Put("u/k", order, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := s.Get("u/k")
+	if err != nil || !ok {
+		t.Fatalf("Get() = %v, %v; want stored order", ok, err)
+	}
+	if !proto.Equal(got, order) {
+		t.Errorf("Get() = %v, want %v", got, order)
+	}
+
+	setNow(now.Add(time.Minute))
+	if _, ok, err := s.Get("u/k"); err != nil || ok {
+		t.Errorf("Get() after window = %v, %v; want no result", ok, err)
+	}
+
+	// Results that are never read again are removed by a later Put.
+	for _, key := range []string{"u/a", "u/b"} {
+		if err := s.Put(key, order, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setNow(now.Add(time.Minute + idempotencySweepInterval))
+	if err := s.Put("u/c", order, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if n := size(); n != 1 {
+		t.Errorf("store holds %d results after the others expired, want 1", n)
+	}
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	s := newMemoryIdempotencyStore()
+	testIdempotencyStore(t, s, func(now time.Time) { s.now = func() time.Time { return now } },
+		func() int { return len(s.entries) })
+}
+
+func TestBoltIdempotencyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "idempotency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := newBoltIdempotencyStore(filepath.Join(dir, "idempotency.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	testIdempotencyStore(t, s, func(now time.Time) { s.now = func() time.Time { return now } },
+		func() int {
+			var n int
+			s.db.View(func(tx *bolt.Tx) error {
+				n = tx.Bucket(idempotencyBucket).Stats().KeyN
+				return nil
+			})
+			return n
+		})
+}
+
+func mustLock(t *testing.T, m *keyedMutex, key string) func() {
+	unlock, err := m.lock(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return unlock
+}
+
+func TestKeyedMutex(t *testing.T) {
+	m := newKeyedMutex()
+	unlock := mustLock(t, m, "a")
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := m.lock(context.Background(), "a")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer unlock()
+		close(acquired)
+	}()
+	mustLock(t, m, "b")() // other keys are not blocked
+
+	select {
+	case <-acquired:
+		t.Fatal("second lock on the same key acquired while held")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	<-acquired
+}
+
+func TestKeyedMutexContext(t *testing.T) {
+	m := newKeyedMutex()
+	unlock := mustLock(t, m, "a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := m.lock(ctx, "a"); err != context.DeadlineExceeded {
+		t.Fatalf("lock() of a held key = %v, want %v", err, context.DeadlineExceeded)
+	}
+	unlock()
+
+	// The abandoned wait must not leave the key locked or referenced.
+	mustLock(t, m, "a")()
+	if n := len(m.locks); n != 0 {
+		t.Errorf("%d locks left after every lock was released, want 0", n)
+	}
+}
@@ -18,42 +18,31 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
-	"go.opentelemetry.io/otel/exporters/stdout"
-	"go.opentelemetry.io/otel/exporters/trace/jaeger"
-	"go.opentelemetry.io/otel/propagation"
-
-	"go.opentelemetry.io/otel/label"
-	"go.opentelemetry.io/otel/sdk/resource"
-	"go.opentelemetry.io/otel/semconv"
-
-	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
-	"go.opentelemetry.io/otel/sdk/trace"
-
 	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
 	money "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/money"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
 	usdCurrency = "USD"
+
+	// defaultShippingServiceLevel is shipped when an order names no level.
+	defaultShippingServiceLevel = "standard"
 )
 
 var log *logrus.Logger
-var serviceName string
-var serviceNameSpace string
 
 func init() {
 	log = logrus.New()
@@ -67,17 +56,49 @@ func init() {
 		TimestampFormat: time.RFC3339Nano,
 	}
 	log.Out = os.Stdout
+	log.AddHook(observability.LogHook{})
 }
 
 type checkoutService struct {
 	productCatalogSvcAddr string
-	cartSvcAddr           string
-	currencySvcAddr       string
-	shippingSvcAddr       string
-	emailSvcAddr          string
-	paymentSvcAddr        string
+	productCatalogSvcConn *grpc.ClientConn
+
+	cartSvcAddr string
+	cartSvcConn *grpc.ClientConn
+
+	currencySvcAddr string
+	currencySvcConn *grpc.ClientConn
+
+	shippingSvcAddr string
+	shippingSvcConn *grpc.ClientConn
+
+	emailSvcAddr string
+	emailSvcConn *grpc.ClientConn
+
+	paymentSvcAddr string
+	paymentSvcConn *grpc.ClientConn
+
+	payments paymentVoider
+	sagas    *sagaLog
+
+	idempotency       idempotencyStore
+	idempotencyWindow time.Duration
+	orderLocks        *keyedMutex
+
+	orders orderRepository
+
+	// fanOutLimit bounds concurrent per-item downstream calls; zero means
+	// defaultFanOutLimit.
+	fanOutLimit int
+
+	// stopMonitors cancels the connection state monitors started by the
+	// constructor.
+	stopMonitors context.CancelFunc
 }
 
+// checkoutserviceConstructor creates the service and dials every downstream
+// service once. The connections are shared by all requests and are released
+// by close.
 func checkoutserviceConstructor(productCatalogSvcAddr string, currencySvcAddr string, cartSvcAddr string, shippingSvcAddr string, paymentSvcAddr string, emailSvcAddr string) *checkoutService {
 	obj := new(checkoutService)
 
@@ -88,109 +109,57 @@ func checkoutserviceConstructor(productCatalogSvcAddr string, currencySvcAddr st
 	obj.paymentSvcAddr = paymentSvcAddr
 	obj.emailSvcAddr = emailSvcAddr
 
+	obj.payments = loggingPaymentVoider{}
+	obj.sagas = newSagaLog(maxRecordedSagas)
+	obj.idempotency = newMemoryIdempotencyStore()
+	obj.idempotencyWindow = defaultIdempotencyWindow
+	obj.orderLocks = newKeyedMutex()
+	obj.orders = newMemoryOrderRepository()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	obj.stopMonitors = cancel
+
+	mustConnGRPC(ctx, &obj.productCatalogSvcConn, obj.productCatalogSvcAddr)
+	mustConnGRPC(ctx, &obj.currencySvcConn, obj.currencySvcAddr)
+	mustConnGRPC(ctx, &obj.cartSvcConn, obj.cartSvcAddr)
+	mustConnGRPC(ctx, &obj.shippingSvcConn, obj.shippingSvcAddr)
+	mustConnGRPC(ctx, &obj.paymentSvcConn, obj.paymentSvcAddr)
+	mustConnGRPC(ctx, &obj.emailSvcConn, obj.emailSvcAddr)
+
 	return obj
 }
-func detectResource() (*resource.Resource, error) {
-	var instID label.KeyValue
-	if host, ok := os.LookupEnv("HOSTNAME"); ok && host != "" {
-		instID = semconv.ServiceInstanceIDKey.String(host)
-	} else {
-		instID = semconv.ServiceInstanceIDKey.String(uuid.New().String())
-	}
-    hostName:=os.Getenv("MY_POD_NAME")
-   hostIp:=os.Getenv("MY_POD_IP")
-   resourceType:=os.Getenv("RESOURCE_TYPE")
-   return resource.New(
-      context.Background(),
-      resource.WithAttributes(
-         instID,
-         semconv.ServiceNameKey.String(serviceName),
-         semconv.HostNameKey.String(hostName),
-         label.String("service.namespace",serviceNameSpace),
-         label.String("ip",hostIp),
-         label.String("resource.type",resourceType),
-      ),
-   )
-}
-func spanExporter() (exporttrace.SpanExporter, error) {
-
-	var user = os.Getenv("JAEGER_USER")
-	var password = os.Getenv("JAEGER_PASSWORD")
-	export_type := os.Getenv("EXPORT_TYPE")
-	if export_type == "JAEGER" {
-		log.Info("exporting with JAEGER logger")
-		addr1 := os.Getenv("JAEGER_ENDPOINT")
-		return jaeger.NewRawExporter(
-			jaeger.WithCollectorEndpoint(addr1,jaeger.WithUsername(user),jaeger.WithPassword(password)),
-			jaeger.WithProcess(jaeger.Process{
-				ServiceName: serviceName,
-			}),
-		)
-	}
-	if export_type == "OTLP" {
-		log.Info("exporting with OTLP logger")
-		if os.Getenv("OTLP_ENDPOINT") != "" {
-			addr1 := os.Getenv("OTLP_ENDPOINT")
-			return otlp.NewExporter(
-				context.Background(),
-				otlp.WithInsecure(),
-				otlp.WithAddress(addr1),
-			)
-		}
-	}
 
-	log.Info("exporting with STDOUT logger")
-	return stdout.NewExporter(
-		stdout.WithPrettyPrint(),
-		stdout.WithWriter(log.Writer()),
-	)
-}
-func initTracing() {
-	if os.Getenv("DISABLE_TRACING") != "" {
+// initTracing installs the global tracer provider described by cfg and
+// returns a function that flushes and stops it.
+func initTracing(cfg observability.Config) (shutdown func(context.Context) error) {
+	if cfg.Disabled {
 		log.Info("tracing disabled")
-		return
+	} else {
+		log.WithField("exporter", cfg.Exporter).Info("tracing enabled")
 	}
-
-	res, err := detectResource()
-	if err != nil {
-		log.WithError(err).Fatal("failed to detect environment resource")
+	if cfg.Exporter == "" || cfg.Exporter == observability.ExporterStdout {
+		cfg.StdoutWriter = log.Writer()
 	}
-
-	exp, err := spanExporter()
+	shutdown, err := observability.Setup(context.Background(), cfg)
 	if err != nil {
-		log.WithError(err).Fatal("failed to initialize Span exporter")
-		return
+		log.WithError(err).Fatal("failed to initialize tracing")
 	}
-
-	log.Info("tracing enabled")
-	otel.SetTracerProvider(
-		trace.NewTracerProvider(
-			trace.WithConfig(
-				trace.Config{
-					DefaultSampler: trace.AlwaysSample(),
-					Resource:       res,
-				},
-			),
-			trace.WithSpanProcessor(
-				trace.NewBatchSpanProcessor(exp),
-			),
-		),
-	)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return shutdown
 }
 
 func main() {
 
-    if serviceName=os.Getenv("SERVICE_NAME");serviceName==""{
-        serviceName = "checkout-service"
-    }
-
-    if serviceNameSpace=os.Getenv("SERVICE_NAMESPACE");serviceNameSpace==""{
-        serviceNameSpace = "hipster"
-    }
-
 	var port = os.Getenv("PORT")
-	initTracing()
+	telemetryConfig, err := observability.ConfigFromEnv("checkout-service")
+	if err != nil {
+		log.WithError(err).Fatal("invalid telemetry configuration")
+	}
+	shutdownTracing := initTracing(telemetryConfig)
+	shutdownMetrics := initMetrics(telemetryConfig)
+	shutdownTimeout, err := observability.ShutdownTimeoutFromEnv()
+	if err != nil {
+		log.Fatalf("failed to parse SHUTDOWN_TIMEOUT as time.Duration: %+v", err)
+	}
 
 	var PRODUCT_CATALOG_SERVICE_ADDR = os.Getenv("PRODUCT_CATALOG_SERVICE_ADDR") // "productcatlog:4000"
 	var CURRENCY_SERVICE_ADDR = os.Getenv("CURRENCY_SERVICE_ADDR")               //"currency:9000"
@@ -201,7 +170,31 @@ func main() {
 
 	svc := checkoutserviceConstructor(PRODUCT_CATALOG_SERVICE_ADDR, CURRENCY_SERVICE_ADDR, CART_SERVICE_ADDR, SHIPPING_SERVICE_ADDR, PAYMENT_SERVICE_ADDR, EMAIL_SERVICE_ADDR)
 
-	log.Infof("service config: %+v", svc)
+	idempotency, err := newIdempotencyStoreFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	svc.idempotency = idempotency
+	if svc.idempotencyWindow, err = idempotencyWindowFromEnv(); err != nil {
+		log.Fatal(err)
+	}
+	if svc.orders, err = newOrderRepositoryFromEnv(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"productcatalog": svc.productCatalogSvcAddr,
+		"currency":       svc.currencySvcAddr,
+		"cart":           svc.cartSvcAddr,
+		"shipping":       svc.shippingSvcAddr,
+		"payment":        svc.paymentSvcAddr,
+		"email":          svc.emailSvcAddr,
+	}).Info("service config")
+
+	var debugSrv *http.Server
+	if debugPort := os.Getenv("DEBUG_PORT"); debugPort != "" {
+		debugSrv = serveDebug(debugPort, svc)
+	}
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
 	if err != nil {
@@ -210,15 +203,51 @@ func main() {
 
 	var srv *grpc.Server
 	srv = grpc.NewServer(
-		grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
+		grpc.ChainUnaryInterceptor(observability.UnaryServerInterceptors(log)...),
+		grpc.ChainStreamInterceptor(observability.StreamServerInterceptors(log)...),
 	)
 
+	hs := svc.newHealthServer()
 	pb.RegisterCheckoutServiceServer(srv, svc)
-	healthpb.RegisterHealthServer(srv, svc)
+	healthpb.RegisterHealthServer(srv, hs)
 	log.Infof("starting to listen on tcp: %q", lis.Addr().String())
-	err = srv.Serve(lis)
-	log.Fatal(err)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// On SIGTERM, fail health checks so that no new orders are routed here,
+	// let in-flight orders finish, then flush the spans and metrics they
+	// produced.
+	observability.WaitForShutdownSignal(log)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	hs.Shutdown()
+	observability.GracefulStop(ctx, log, srv)
+	if debugSrv != nil {
+		if err := debugSrv.Shutdown(ctx); err != nil {
+			log.WithError(err).Warn("failed to stop debug server")
+		}
+	}
+	svc.close()
+	observability.FlushTelemetry(log, shutdownTracing, shutdownMetrics)
+	log.Info("shutdown complete")
+}
+
+// serveDebug serves the debugging endpoints of svc over HTTP in the
+// background.
+func serveDebug(port string, svc *checkoutService) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/sagas", svc.sagas)
+	srv := &http.Server{Addr: ":" + port, Handler: mux}
+	log.Infof("starting debug server on :%s", port)
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.WithError(err).Error("debug server stopped")
+		}
+	}()
+	return srv
 }
 
 func mustMapEnv(target *string, envKey string) {
@@ -229,24 +258,55 @@ func mustMapEnv(target *string, envKey string) {
 	*target = v
 }
 
-func (cs *checkoutService) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
-	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
-}
-
-func (cs *checkoutService) Watch(req *healthpb.HealthCheckRequest, ws healthpb.Health_WatchServer) error {
-	return status.Errorf(codes.Unimplemented, "health check via Watch not implemented")
-}
-
 func (cs *checkoutService) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+	log := observability.LoggerFromContext(ctx, log)
 	log.Infof("[PlaceOrder] user_id=%q user_currency=%q", req.UserId, req.UserCurrency)
 
+	key := idempotencyKey(ctx, req)
+	if key == "" {
+		return cs.placeOrder(ctx, req)
+	}
+
+	unlock, err := cs.orderLocks.lock(ctx, key)
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	defer unlock()
+	order, ok, err := cs.idempotency.Get(key)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to look up idempotency key: %+v", err)
+	}
+	if ok {
+		log.WithField("order_id", order.GetOrderId()).Info("replaying order for repeated idempotency key")
+		return &pb.PlaceOrderResponse{Order: order}, nil
+	}
+
+	resp, err := cs.placeOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.idempotency.Put(key, resp.GetOrder(), cs.idempotencyWindow); err != nil {
+		log.WithError(err).Warn("failed to store order for idempotency key")
+	}
+	return resp, nil
+}
+
+// placeOrder places a new order for the cart of the requesting user.
+func (cs *checkoutService) placeOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+	log := observability.LoggerFromContext(ctx, log)
 	orderID, err := uuid.NewUUID()
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to generate order uuid")
 	}
 
-	prep, err := cs.prepareOrderItemsAndShippingQuoteFromCart(ctx, req.UserId, req.UserCurrency, req.Address)
-	if err != nil {
+	shippingLevel := req.GetShippingServiceLevel()
+	if shippingLevel == "" {
+		shippingLevel = defaultShippingServiceLevel
+	}
+	prep, err := cs.prepareOrderItemsAndShippingQuoteFromCart(ctx, req.UserId, req.UserCurrency, req.Address, shippingLevel)
+	if status.Code(err) == codes.InvalidArgument {
+		return nil, err
+	} else if err != nil {
 		return nil, status.Errorf(codes.Internal, err.Error())
 	}
 
@@ -259,54 +319,109 @@ func (cs *checkoutService) PlaceOrder(ctx context.Context, req *pb.PlaceOrderReq
 		total = money.Must(money.Sum(total, multPrice))
 	}
 
-	txID, err := cs.chargeCard(ctx, &total, req.CreditCard)
+	sg := newSaga(orderID.String())
+	defer cs.sagas.record(sg)
+
+	var txID string
+	err = sg.run(ctx, "charge_card", func(ctx context.Context) error {
+		var err error
+		txID, err = cs.chargeCard(ctx, &total, req.CreditCard)
+		return err
+	}, func(ctx context.Context) error {
+		return cs.payments.Void(ctx, txID, &total)
+	})
 	if err != nil {
+		cs.rollback(sg)
 		return nil, status.Errorf(codes.Internal, "failed to charge card: %+v", err)
 	}
 	log.Infof("payment went through (transaction_id: %s)", txID)
 
-	shippingTrackingID, err := cs.shipOrder(ctx, req.Address, prep.cartItems)
+	var (
+		shippingTrackingID string
+		shippingAddress    = req.Address
+		packages           []*pb.Package
+	)
+	err = sg.run(ctx, "ship_order", func(ctx context.Context) error {
+		resp, err := cs.shipOrder(ctx, req.Address, prep.cartItems, shippingLevel)
+		if err != nil {
+			return err
+		}
+		shippingTrackingID = resp.GetTrackingId()
+		packages = resp.GetPackages()
+		if resp.GetAddress() != nil {
+			shippingAddress = resp.GetAddress()
+		}
+		return nil
+	}, nil)
 	if err != nil {
+		cs.rollback(sg)
 		return nil, status.Errorf(codes.Unavailable, "shipping error: %+v", err)
 	}
 
-	_ = cs.emptyUserCart(ctx, req.UserId)
+	// The order is committed once it ships: nothing after this point undoes
+	// it, which is why shipping has no compensation. Failing to empty the
+	// cart or to send the confirmation is only logged.
+	if err := sg.run(ctx, "empty_cart", func(ctx context.Context) error {
+		return cs.emptyUserCart(ctx, req.UserId)
+	}, nil); err != nil {
+		log.Warnf("failed to empty cart of user %q: %+v", req.UserId, err)
+	}
 
 	orderResult := &pb.OrderResult{
 		OrderId:            orderID.String(),
 		ShippingTrackingId: shippingTrackingID,
 		ShippingCost:       prep.shippingCostLocalized,
-		ShippingAddress:    req.Address,
+		ShippingAddress:    shippingAddress,
 		Items:              prep.orderItems,
+
+		ShippingServiceLevel: shippingLevel,
+		Packages:             packages,
 	}
+	cs.saveOrder(ctx, req.UserId, orderResult)
 
-	if err := cs.sendOrderConfirmation(ctx, req.Email, orderResult); err != nil {
+	if err := sg.run(ctx, "send_confirmation", func(ctx context.Context) error {
+		return cs.sendOrderConfirmation(ctx, req.Email, orderResult)
+	}, nil); err != nil {
 		log.Warnf("failed to send order confirmation to %q: %+v", req.Email, err)
 	} else {
 		log.Infof("order confirmation email sent to %q", req.Email)
 	}
+	sg.complete()
+	recordOrder(ctx, total)
+
 	resp := &pb.PlaceOrderResponse{Order: orderResult}
 	return resp, nil
 }
 
+// rollback undoes the completed steps of a failed order.
+func (cs *checkoutService) rollback(sg *saga) {
+	if err := sg.rollback(); err != nil {
+		log.WithField("order_id", sg.orderID).Errorf("order compensation incomplete: %+v", err)
+		return
+	}
+	log.WithField("order_id", sg.orderID).Info("order compensated")
+}
+
 type orderPrep struct {
 	orderItems            []*pb.OrderItem
 	cartItems             []*pb.CartItem
 	shippingCostLocalized *pb.Money
 }
 
-func (cs *checkoutService) prepareOrderItemsAndShippingQuoteFromCart(ctx context.Context, userID, userCurrency string, address *pb.Address) (orderPrep, error) {
+func (cs *checkoutService) prepareOrderItemsAndShippingQuoteFromCart(ctx context.Context, userID, userCurrency string, address *pb.Address, shippingLevel string) (orderPrep, error) {
 	var out orderPrep
 	cartItems, err := cs.getUserCart(ctx, userID)
 	if err != nil {
 		return out, fmt.Errorf("cart failure: %+v", err)
 	}
-	orderItems, err := cs.prepOrderItems(ctx, cartItems, userCurrency)
+	orderItems, subtotalUSD, err := cs.prepOrderItems(ctx, cartItems, userCurrency)
 	if err != nil {
 		return out, fmt.Errorf("failed to prepare order: %+v", err)
 	}
-	shippingUSD, err := cs.quoteShipping(ctx, address, cartItems)
-	if err != nil {
+	shippingUSD, err := cs.quoteShipping(ctx, address, cartItems, subtotalUSD, shippingLevel)
+	if status.Code(err) == codes.InvalidArgument {
+		return out, err
+	} else if err != nil {
 		return out, fmt.Errorf("shipping quote failure: %+v", err)
 	}
 	shippingPrice, err := cs.convertCurrency(ctx, shippingUSD, userCurrency)
@@ -320,42 +435,25 @@ func (cs *checkoutService) prepareOrderItemsAndShippingQuoteFromCart(ctx context
 	return out, nil
 }
 
-func (cs *checkoutService) quoteShipping(ctx context.Context, address *pb.Address, items []*pb.CartItem) (*pb.Money, error) {
-	/*conn, err := grpc.DialContext(ctx, cs.shippingSvcAddr,
-	grpc.WithInsecure())*/
-	conn, err := grpc.Dial(cs.shippingSvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("could not connect shipping service: %+v", err)
-	}
-	defer conn.Close()
-
-	shippingQuote, err := pb.NewShippingServiceClient(conn).
+func (cs *checkoutService) quoteShipping(ctx context.Context, address *pb.Address, items []*pb.CartItem, subtotalUSD *pb.Money, level string) (*pb.Money, error) {
+	shippingQuote, err := pb.NewShippingServiceClient(cs.shippingSvcConn).
 		GetQuote(ctx, &pb.GetQuoteRequest{
-			Address: address,
-			Items:   items})
-	if err != nil {
+			Address:      address,
+			Items:        items,
+			SubtotalUsd:  subtotalUSD,
+			ServiceLevel: level})
+	if status.Code(err) == codes.InvalidArgument {
+		// The address or service level is the customer's to fix, so the
+		// status and its field violations are passed on as they are.
+		return nil, err
+	} else if err != nil {
 		return nil, fmt.Errorf("failed to get shipping quote: %+v", err)
 	}
 	return shippingQuote.GetCostUsd(), nil
 }
 
 func (cs *checkoutService) getUserCart(ctx context.Context, userID string) ([]*pb.CartItem, error) {
-	//conn, err := grpc.DialContext(ctx, cs.cartSvcAddr, grpc.WithInsecure())
-
-	conn, err := grpc.Dial(cs.cartSvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("could not connect cart service: %+v", err)
-	}
-	defer conn.Close()
-
-	cart, err := pb.NewCartServiceClient(conn).GetCart(ctx, &pb.GetCartRequest{UserId: userID})
+	cart, err := pb.NewCartServiceClient(cs.cartSvcConn).GetCart(ctx, &pb.GetCartRequest{UserId: userID})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user cart during checkout: %+v", err)
 	}
@@ -363,69 +461,67 @@ func (cs *checkoutService) getUserCart(ctx context.Context, userID string) ([]*p
 }
 
 func (cs *checkoutService) emptyUserCart(ctx context.Context, userID string) error {
-	//conn, err := grpc.DialContext(ctx, cs.cartSvcAddr, grpc.WithInsecure())
-
-	conn, err := grpc.Dial(cs.cartSvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
-
-	if err != nil {
-		return fmt.Errorf("could not connect cart service: %+v", err)
-	}
-	defer conn.Close()
-
-	if _, err = pb.NewCartServiceClient(conn).EmptyCart(ctx, &pb.EmptyCartRequest{UserId: userID}); err != nil {
+	if _, err := pb.NewCartServiceClient(cs.cartSvcConn).EmptyCart(ctx, &pb.EmptyCartRequest{UserId: userID}); err != nil {
 		return fmt.Errorf("failed to empty user cart during checkout: %+v", err)
 	}
 	return nil
 }
 
-func (cs *checkoutService) prepOrderItems(ctx context.Context, items []*pb.CartItem, userCurrency string) ([]*pb.OrderItem, error) {
-	out := make([]*pb.OrderItem, len(items))
-
-	//conn, err := grpc.DialContext(ctx, cs.productCatalogSvcAddr, grpc.WithInsecure())
-
-	conn, err := grpc.Dial(cs.productCatalogSvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
-
+// prepOrderItems prices items in userCurrency. It also returns their total
+// in USD, which free shipping is based on.
+func (cs *checkoutService) prepOrderItems(ctx context.Context, items []*pb.CartItem, userCurrency string) ([]*pb.OrderItem, *pb.Money, error) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.GetProductId()
+	}
+	products, err := cs.getProducts(ctx, ids)
 	if err != nil {
-		return nil, fmt.Errorf("could not connect product catalog service: %+v", err)
+		return nil, nil, err
+	}
+	subtotalUSD := pb.Money{CurrencyCode: usdCurrency}
+	for _, item := range items {
+		price := products[item.GetProductId()].GetPriceUsd()
+		subtotalUSD = money.Must(money.Sum(subtotalUSD, money.MultiplySlow(*price, uint32(item.GetQuantity()))))
 	}
-	defer conn.Close()
-	cl := pb.NewProductCatalogServiceClient(conn)
 
-	for i, item := range items {
-		product, err := cl.GetProduct(ctx, &pb.GetProductRequest{Id: item.GetProductId()})
-		if err != nil {
-			return nil, fmt.Errorf("failed to get product #%q", item.GetProductId())
-		}
-		price, err := cs.convertCurrency(ctx, product.GetPriceUsd(), userCurrency)
+	out := make([]*pb.OrderItem, len(items))
+	err = fanOut(ctx, len(items), cs.fanOutLimit, func(ctx context.Context, i int) error {
+		item := items[i]
+		price, err := cs.convertCurrency(ctx, products[item.GetProductId()].GetPriceUsd(), userCurrency)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert price of %q to %s", item.GetProductId(), userCurrency)
+			return fmt.Errorf("failed to convert price of %q to %s", item.GetProductId(), userCurrency)
 		}
 		out[i] = &pb.OrderItem{
 			Item: item,
 			Cost: price}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-	return out, nil
+	return out, &subtotalUSD, nil
 }
 
-func (cs *checkoutService) convertCurrency(ctx context.Context, from *pb.Money, toCurrency string) (*pb.Money, error) {
-	//conn, err := grpc.DialContext(ctx, cs.currencySvcAddr, grpc.WithInsecure())
-
-	conn, err := grpc.Dial(cs.currencySvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
-
+// getProducts looks up all ids in one round trip and returns the products
+// keyed by ID. An ID missing from the catalog is an error.
+func (cs *checkoutService) getProducts(ctx context.Context, ids []string) (map[string]*pb.Product, error) {
+	resp, err := pb.NewProductCatalogServiceClient(cs.productCatalogSvcConn).
+		GetProducts(ctx, &pb.GetProductsRequest{Ids: ids})
 	if err != nil {
-		return nil, fmt.Errorf("could not connect currency service: %+v", err)
+		return nil, fmt.Errorf("failed to get products: %+v", err)
+	}
+	if missing := resp.GetMissingIds(); len(missing) > 0 {
+		return nil, fmt.Errorf("failed to get product #%q", missing[0])
 	}
-	defer conn.Close()
-	result, err := pb.NewCurrencyServiceClient(conn).Convert(context.TODO(), &pb.CurrencyConversionRequest{
+	out := make(map[string]*pb.Product, len(resp.GetProducts()))
+	for _, p := range resp.GetProducts() {
+		out[p.GetId()] = p
+	}
+	return out, nil
+}
+
+func (cs *checkoutService) convertCurrency(ctx context.Context, from *pb.Money, toCurrency string) (*pb.Money, error) {
+	result, err := pb.NewCurrencyServiceClient(cs.currencySvcConn).Convert(ctx, &pb.CurrencyConversionRequest{
 		From:   from,
 		ToCode: toCurrency})
 	if err != nil {
@@ -435,19 +531,7 @@ func (cs *checkoutService) convertCurrency(ctx context.Context, from *pb.Money,
 }
 
 func (cs *checkoutService) chargeCard(ctx context.Context, amount *pb.Money, paymentInfo *pb.CreditCardInfo) (string, error) {
-	//conn, err := grpc.DialContext(ctx, cs.paymentSvcAddr, grpc.WithInsecure())
-
-	conn, err := grpc.Dial(cs.paymentSvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to connect payment service: %+v", err)
-	}
-	defer conn.Close()
-
-	paymentResp, err := pb.NewPaymentServiceClient(conn).Charge(ctx, &pb.ChargeRequest{
+	paymentResp, err := pb.NewPaymentServiceClient(cs.paymentSvcConn).Charge(ctx, &pb.ChargeRequest{
 		Amount:     amount,
 		CreditCard: paymentInfo})
 	if err != nil {
@@ -457,40 +541,21 @@ func (cs *checkoutService) chargeCard(ctx context.Context, amount *pb.Money, pay
 }
 
 func (cs *checkoutService) sendOrderConfirmation(ctx context.Context, email string, order *pb.OrderResult) error {
-	//conn, err := grpc.DialContext(ctx, cs.emailSvcAddr, grpc.WithInsecure())
-
-	conn, err := grpc.Dial(cs.emailSvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to connect email service: %+v", err)
-	}
-	defer conn.Close()
-	_, err = pb.NewEmailServiceClient(conn).SendOrderConfirmation(ctx, &pb.SendOrderConfirmationRequest{
+	_, err := pb.NewEmailServiceClient(cs.emailSvcConn).SendOrderConfirmation(ctx, &pb.SendOrderConfirmationRequest{
 		Email: email,
 		Order: order})
 	return err
 }
 
-func (cs *checkoutService) shipOrder(ctx context.Context, address *pb.Address, items []*pb.CartItem) (string, error) {
-	//conn, err := grpc.DialContext(ctx, cs.shippingSvcAddr, grpc.WithInsecure())
-	conn, err := grpc.Dial(cs.shippingSvcAddr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
+// shipOrder returns the packages of the shipment and the address it ships
+// to, as normalized by the shipping service.
+func (cs *checkoutService) shipOrder(ctx context.Context, address *pb.Address, items []*pb.CartItem, level string) (*pb.ShipOrderResponse, error) {
+	resp, err := pb.NewShippingServiceClient(cs.shippingSvcConn).ShipOrder(ctx, &pb.ShipOrderRequest{
+		Address:      address,
+		Items:        items,
+		ServiceLevel: level})
 	if err != nil {
-		return "", fmt.Errorf("failed to connect email service: %+v", err)
+		return nil, fmt.Errorf("shipment failed: %+v", err)
 	}
-	defer conn.Close()
-	resp, err := pb.NewShippingServiceClient(conn).ShipOrder(ctx, &pb.ShipOrderRequest{
-		Address: address,
-		Items:   items})
-	if err != nil {
-		return "", fmt.Errorf("shipment failed: %+v", err)
-	}
-	return resp.GetTrackingId(), nil
+	return resp, nil
 }
-
-// TODO: Dial and create client once, reuse.
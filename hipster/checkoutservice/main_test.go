@@ -0,0 +1,166 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+)
+
+type fakeCart struct {
+	pb.CartServiceServer
+}
+
+func (fakeCart) GetCart(ctx context.Context, req *pb.GetCartRequest) (*pb.Cart, error) {
+	return &pb.Cart{UserId: req.GetUserId(), Items: []*pb.CartItem{{ProductId: "OLJCESPC7Z", Quantity: 1}}}, nil
+}
+
+// invalidAddressShipping rejects every address as the shipping service
+// does a postal code that does not match its country.
+type invalidAddressShipping struct {
+	pb.ShippingServiceServer
+}
+
+func (invalidAddressShipping) GetQuote(ctx context.Context, req *pb.GetQuoteRequest) (*pb.GetQuoteResponse, error) {
+	st, err := status.New(codes.InvalidArgument, "invalid address").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "address.postal_code", Description: "postal code must look like 94043"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, st.Err()
+}
+
+func TestPlaceOrderPassesOnInvalidAddress(t *testing.T) {
+	cs := &checkoutService{
+		productCatalogSvcConn: dialFake(t, func(s *grpc.Server) { pb.RegisterProductCatalogServiceServer(s, fakeCatalog{}) }),
+		cartSvcConn:           dialFake(t, func(s *grpc.Server) { pb.RegisterCartServiceServer(s, fakeCart{}) }),
+		currencySvcConn:       dialFake(t, func(s *grpc.Server) { pb.RegisterCurrencyServiceServer(s, fakeCurrency{}) }),
+		shippingSvcConn:       dialFake(t, func(s *grpc.Server) { pb.RegisterShippingServiceServer(s, invalidAddressShipping{}) }),
+	}
+	_, err := cs.PlaceOrder(context.Background(), &pb.PlaceOrderRequest{
+		UserId:       "u1",
+		UserCurrency: "USD",
+		Address:      &pb.Address{Country: "US", PostalCode: "123"},
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("PlaceOrder() = %v, want InvalidArgument", err)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("PlaceOrder() details = %v, want the shipping service's", details)
+	}
+	br, ok := details[0].(*errdetails.BadRequest)
+	if !ok || br.GetFieldViolations()[0].GetField() != "address.postal_code" {
+		t.Errorf("PlaceOrder() details = %v, want a violation of address.postal_code", details)
+	}
+}
+
+// fakePayment accepts every charge and records the last amount.
+type fakePayment struct {
+	pb.PaymentServiceServer
+
+	mu      sync.Mutex
+	charged *pb.Money
+}
+
+func (p *fakePayment) Charge(ctx context.Context, req *pb.ChargeRequest) (*pb.ChargeResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.charged = req.GetAmount()
+	return &pb.ChargeResponse{TransactionId: "tx-1"}, nil
+}
+
+// failingShipping quotes every shipment but fails to ship any.
+type failingShipping struct {
+	pb.ShippingServiceServer
+}
+
+func (failingShipping) GetQuote(ctx context.Context, req *pb.GetQuoteRequest) (*pb.GetQuoteResponse, error) {
+	return &pb.GetQuoteResponse{CostUsd: &pb.Money{CurrencyCode: "USD", Units: 8, Nanos: 990000000}}, nil
+}
+
+func (failingShipping) ShipOrder(ctx context.Context, req *pb.ShipOrderRequest) (*pb.ShipOrderResponse, error) {
+	return nil, status.Error(codes.Unavailable, "no carrier available")
+}
+
+// recordingVoider records the transactions it voids.
+type recordingVoider struct {
+	mu     sync.Mutex
+	voided map[string]*pb.Money
+}
+
+func (v *recordingVoider) Void(ctx context.Context, transactionID string, amount *pb.Money) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.voided[transactionID] = amount
+	return nil
+}
+
+func TestPlaceOrderVoidsPaymentWhenShippingFails(t *testing.T) {
+	payment := &fakePayment{}
+	payments := &recordingVoider{voided: make(map[string]*pb.Money)}
+	cs := &checkoutService{
+		productCatalogSvcConn: dialFake(t, func(s *grpc.Server) { pb.RegisterProductCatalogServiceServer(s, fakeCatalog{}) }),
+		cartSvcConn:           dialFake(t, func(s *grpc.Server) { pb.RegisterCartServiceServer(s, fakeCart{}) }),
+		currencySvcConn:       dialFake(t, func(s *grpc.Server) { pb.RegisterCurrencyServiceServer(s, fakeCurrency{}) }),
+		shippingSvcConn:       dialFake(t, func(s *grpc.Server) { pb.RegisterShippingServiceServer(s, failingShipping{}) }),
+		paymentSvcConn:        dialFake(t, func(s *grpc.Server) { pb.RegisterPaymentServiceServer(s, payment) }),
+		payments:              payments,
+		sagas:                 newSagaLog(maxRecordedSagas),
+	}
+	_, err := cs.PlaceOrder(context.Background(), &pb.PlaceOrderRequest{
+		UserId:       "u1",
+		UserCurrency: "USD",
+		Address:      &pb.Address{Country: "US", PostalCode: "94043"},
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("PlaceOrder() = %v, want Unavailable", err)
+	}
+
+	amount, ok := payments.voided["tx-1"]
+	if !ok {
+		t.Fatalf("voided transactions = %v, want tx-1", payments.voided)
+	}
+	if !proto.Equal(amount, payment.charged) {
+		t.Errorf("voided amount = %v, want the charged %v", amount, payment.charged)
+	}
+
+	sagas := cs.sagas.list()
+	if len(sagas) != 1 {
+		t.Fatalf("recorded %d sagas, want 1", len(sagas))
+	}
+	snap := sagas[0].snapshot()
+	if snap.State != sagaCompensated {
+		t.Errorf("saga state = %s, want %s", snap.State, sagaCompensated)
+	}
+	for _, step := range snap.Steps {
+		if step.Name == "empty_cart" || step.Name == "send_confirmation" {
+			t.Errorf("step %s ran for an order that did not ship", step.Name)
+		}
+	}
+}
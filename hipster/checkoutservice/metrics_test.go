@@ -0,0 +1,64 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+)
+
+func TestRecordOrder(t *testing.T) {
+	handler, shutdown, err := observability.SetupMetrics(context.Background(), observability.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	ctx := context.Background()
+	recordOrder(ctx, pb.Money{CurrencyCode: "EUR", Units: 10, Nanos: 500000000})
+	recordOrder(ctx, pb.Money{CurrencyCode: "EUR", Units: 4, Nanos: 250000000})
+	recordOrder(ctx, pb.Money{CurrencyCode: "USD", Units: 3})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(w.Body)
+	for _, want := range [][2]string{
+		{`orders_placed{currency="EUR"`, "} 2"},
+		{`orders_value{currency="EUR"`, "} 14.75"},
+		{`orders_placed{currency="USD"`, "} 1"},
+		{`orders_value{currency="USD"`, "} 3"},
+	} {
+		if !hasSample(string(body), want[0], want[1]) {
+			t.Errorf("/metrics has no sample %s...%s:\n%s", want[0], want[1], body)
+		}
+	}
+}
+
+// hasSample reports whether a line of body starts with prefix and ends with
+// suffix.
+func hasSample(body, prefix, suffix string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, prefix) && strings.HasSuffix(line, suffix) {
+			return true
+		}
+	}
+	return false
+}
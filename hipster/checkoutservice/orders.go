@@ -0,0 +1,314 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+)
+
+const (
+	defaultOrdersPageSize = 10
+	maxOrdersPageSize     = 100
+
+	// orderSaveTimeout bounds saving a placed order. The order is saved on
+	// a fresh context so that it is kept even when the client gives up
+	// after its card was charged.
+	orderSaveTimeout = 5 * time.Second
+)
+
+var errOrderNotFound = errors.New("order not found")
+
+// storedOrder is an order together with the user that placed it.
+type storedOrder struct {
+	// Seq orders the records of a repository by insertion time.
+	Seq      int64
+	UserID   string
+	PlacedAt time.Time
+	Order    *pb.OrderResult
+}
+
+// orderRepository persists placed orders.
+type orderRepository interface {
+	// Save stores a newly placed order.
+	Save(ctx context.Context, userID string, order *pb.OrderResult) error
+	// Get returns the order with the given ID or errOrderNotFound.
+	Get(ctx context.Context, orderID string) (*storedOrder, error)
+	// List returns up to limit orders of userID, most recent first, that
+	// were stored before the order with sequence number before. A before of
+	// 0 starts with the most recent order.
+	List(ctx context.Context, userID string, before int64, limit int) ([]*storedOrder, error)
+	Close() error
+}
+
+// newOrderRepositoryFromEnv creates the repository selected by the
+// ORDER_STORE environment variable ("memory", "json" or "sqlite").
+func newOrderRepositoryFromEnv() (orderRepository, error) {
+	path := os.Getenv("ORDER_STORE_PATH")
+	switch kind := os.Getenv("ORDER_STORE"); kind {
+	case "", "memory":
+		return newMemoryOrderRepository(), nil
+	case "json":
+		if path == "" {
+			path = "orders.json"
+		}
+		return newJSONOrderRepository(path)
+	case "sqlite":
+		if path == "" {
+			path = "orders.db"
+		}
+		return newSQLiteOrderRepository(path)
+	default:
+		return nil, fmt.Errorf("unknown order store %q", kind)
+	}
+}
+
+// memoryOrderRepository keeps orders in process memory.
+type memoryOrderRepository struct {
+	mu      sync.RWMutex
+	seq     int64
+	byID    map[string]*storedOrder
+	byUser  map[string][]*storedOrder
+	onWrite func() error
+}
+
+func newMemoryOrderRepository() *memoryOrderRepository {
+	return &memoryOrderRepository{
+		byID:   make(map[string]*storedOrder),
+		byUser: make(map[string][]*storedOrder),
+	}
+}
+
+// add indexes rec. It assigns the next sequence number unless rec already
+// has one. The caller must hold the write lock.
+func (r *memoryOrderRepository) add(rec *storedOrder) {
+	if rec.Seq == 0 {
+		r.seq++
+		rec.Seq = r.seq
+	} else if rec.Seq > r.seq {
+		r.seq = rec.Seq
+	}
+	r.byID[rec.Order.GetOrderId()] = rec
+	r.byUser[rec.UserID] = append(r.byUser[rec.UserID], rec)
+}
+
+func (r *memoryOrderRepository) Save(ctx context.Context, userID string, order *pb.OrderResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byID[order.GetOrderId()]; ok {
+		return fmt.Errorf("order %s already exists", order.GetOrderId())
+	}
+	seq := r.seq
+	rec := &storedOrder{UserID: userID, PlacedAt: time.Now(), Order: order}
+	r.add(rec)
+	if r.onWrite == nil {
+		return nil
+	}
+	if err := r.onWrite(); err != nil {
+		// The order was not persisted, so it must not be visible either.
+		r.remove(rec)
+		r.seq = seq
+		return err
+	}
+	return nil
+}
+
+// remove unindexes rec, which must be the last order added. The caller must
+// hold the write lock.
+func (r *memoryOrderRepository) remove(rec *storedOrder) {
+	delete(r.byID, rec.Order.GetOrderId())
+	if recs := r.byUser[rec.UserID]; len(recs) > 1 {
+		r.byUser[rec.UserID] = recs[:len(recs)-1]
+	} else {
+		delete(r.byUser, rec.UserID)
+	}
+}
+
+func (r *memoryOrderRepository) Get(ctx context.Context, orderID string) (*storedOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.byID[orderID]
+	if !ok {
+		return nil, errOrderNotFound
+	}
+	return rec, nil
+}
+
+func (r *memoryOrderRepository) List(ctx context.Context, userID string, before int64, limit int) ([]*storedOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	recs := r.byUser[userID]
+	var out []*storedOrder
+	for i := len(recs) - 1; i >= 0 && len(out) < limit; i-- {
+		if before == 0 || recs[i].Seq < before {
+			out = append(out, recs[i])
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryOrderRepository) Close() error { return nil }
+
+// jsonOrder is the representation of a storedOrder in the JSON file.
+type jsonOrder struct {
+	Seq      int64           `json:"seq"`
+	UserID   string          `json:"user_id"`
+	PlacedAt time.Time       `json:"placed_at"`
+	Order    json.RawMessage `json:"order"`
+}
+
+// jsonOrderRepository keeps orders in memory and rewrites a JSON file after
+// every change. It suits small installations and local development.
+type jsonOrderRepository struct {
+	*memoryOrderRepository
+	path string
+}
+
+func newJSONOrderRepository(path string) (*jsonOrderRepository, error) {
+	r := &jsonOrderRepository{memoryOrderRepository: newMemoryOrderRepository(), path: path}
+	r.onWrite = r.flush
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read order file %s: %+v", path, err)
+	}
+	var recs []jsonOrder
+	if err := json.Unmarshal(b, &recs); err != nil {
+		return nil, fmt.Errorf("failed to parse order file %s: %+v", path, err)
+	}
+	for _, rec := range recs {
+		order := new(pb.OrderResult)
+		if err := jsonpb.Unmarshal(bytes.NewReader(rec.Order), order); err != nil {
+			return nil, fmt.Errorf("failed to parse order %d in %s: %+v", rec.Seq, path, err)
+		}
+		r.add(&storedOrder{Seq: rec.Seq, UserID: rec.UserID, PlacedAt: rec.PlacedAt, Order: order})
+	}
+	return r, nil
+}
+
+// flush writes all orders to the file. The caller must hold the write lock.
+func (r *jsonOrderRepository) flush() error {
+	recs := make([]jsonOrder, 0, len(r.byID))
+	for _, rec := range r.byID {
+		var buf bytes.Buffer
+		if err := (&jsonpb.Marshaler{}).Marshal(&buf, rec.Order); err != nil {
+			return fmt.Errorf("failed to serialize order %s: %+v", rec.Order.GetOrderId(), err)
+		}
+		recs = append(recs, jsonOrder{Seq: rec.Seq, UserID: rec.UserID, PlacedAt: rec.PlacedAt, Order: buf.Bytes()})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Seq < recs[j].Seq })
+	b, err := json.MarshalIndent(recs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash never leaves a
+	// truncated file behind.
+	tmp, err := ioutil.TempFile(filepath.Dir(r.path), filepath.Base(r.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write order file: %+v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write order file: %+v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write order file: %+v", err)
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// saveOrder records a placed order. The order has already been paid for and
+// shipped at this point, so a failure is logged rather than returned.
+func (cs *checkoutService) saveOrder(ctx context.Context, userID string, order *pb.OrderResult) {
+	log := observability.LoggerFromContext(ctx, log)
+	ctx, cancel := context.WithTimeout(context.Background(), orderSaveTimeout)
+	defer cancel()
+	if err := cs.orders.Save(ctx, userID, order); err != nil {
+		log.WithError(err).WithField("order_id", order.GetOrderId()).Error("failed to save order")
+	}
+}
+
+func (cs *checkoutService) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.OrderResult, error) {
+	log := observability.LoggerFromContext(ctx, log)
+	log.Infof("[GetOrder] order_id=%q", req.GetOrderId())
+	if req.GetUserId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+	rec, err := cs.orders.Get(ctx, req.GetOrderId())
+	if err == errOrderNotFound || (err == nil && rec.UserID != req.GetUserId()) {
+		return nil, status.Errorf(codes.NotFound, "no order with ID %s", req.GetOrderId())
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get order: %+v", err)
+	}
+	return rec.Order, nil
+}
+
+func (cs *checkoutService) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	log := observability.LoggerFromContext(ctx, log)
+	log.Infof("[ListOrders] user_id=%q", req.GetUserId())
+	if req.GetUserId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultOrdersPageSize
+	} else if pageSize > maxOrdersPageSize {
+		pageSize = maxOrdersPageSize
+	}
+	var before int64
+	if token := req.GetPageToken(); token != "" {
+		v, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || v <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token %q", token)
+		}
+		before = v
+	}
+
+	// Fetch one extra order to find out whether there is a next page.
+	recs, err := cs.orders.List(ctx, req.GetUserId(), before, pageSize+1)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list orders: %+v", err)
+	}
+	resp := new(pb.ListOrdersResponse)
+	if len(recs) > pageSize {
+		recs = recs[:pageSize]
+		resp.NextPageToken = strconv.FormatInt(recs[pageSize-1].Seq, 10)
+	}
+	for _, rec := range recs {
+		resp.Orders = append(resp.Orders, rec.Order)
+	}
+	return resp, nil
+}
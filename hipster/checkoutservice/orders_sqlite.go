@@ -0,0 +1,127 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	_ "github.com/mattn/go-sqlite3"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+)
+
+const sqliteOrdersSchema = `
+CREATE TABLE IF NOT EXISTS orders (
+	seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+	order_id  TEXT NOT NULL UNIQUE,
+	user_id   TEXT NOT NULL,
+	placed_at INTEGER NOT NULL,
+	order_pb  BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS orders_by_user ON orders (user_id, seq);
+`
+
+// sqliteOrderRepository stores orders in a SQLite database. Orders are
+// kept as serialized OrderResult messages next to the columns used for
+// lookups.
+type sqliteOrderRepository struct {
+	db *sql.DB
+}
+
+func newSQLiteOrderRepository(path string) (*sqliteOrderRepository, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open order database %s: %+v", path, err)
+	}
+	// SQLite allows a single writer; serializing access through one
+	// connection avoids "database is locked" errors.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteOrdersSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create order schema: %+v", err)
+	}
+	return &sqliteOrderRepository{db: db}, nil
+}
+
+func (r *sqliteOrderRepository) Save(ctx context.Context, userID string, order *pb.OrderResult) error {
+	b, err := proto.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to serialize order: %+v", err)
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO orders (order_id, user_id, placed_at, order_pb) VALUES (?, ?, ?, ?)`,
+		order.GetOrderId(), userID, time.Now().UnixNano(), b)
+	return err
+}
+
+func (r *sqliteOrderRepository) Get(ctx context.Context, orderID string) (*storedOrder, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT seq, user_id, placed_at, order_pb FROM orders WHERE order_id = ?`, orderID)
+	rec, err := scanOrder(row)
+	if err == sql.ErrNoRows {
+		return nil, errOrderNotFound
+	}
+	return rec, err
+}
+
+func (r *sqliteOrderRepository) List(ctx context.Context, userID string, before int64, limit int) ([]*storedOrder, error) {
+	query := `SELECT seq, user_id, placed_at, order_pb FROM orders WHERE user_id = ? ORDER BY seq DESC LIMIT ?`
+	args := []interface{}{userID, limit}
+	if before > 0 {
+		query = `SELECT seq, user_id, placed_at, order_pb FROM orders WHERE user_id = ? AND seq < ? ORDER BY seq DESC LIMIT ?`
+		args = []interface{}{userID, before, limit}
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*storedOrder
+	for rows.Next() {
+		rec, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteOrderRepository) Close() error { return r.db.Close() }
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrder(s scanner) (*storedOrder, error) {
+	var (
+		rec      storedOrder
+		placedAt int64
+		b        []byte
+	)
+	if err := s.Scan(&rec.Seq, &rec.UserID, &placedAt, &b); err != nil {
+		return nil, err
+	}
+	rec.PlacedAt = time.Unix(0, placedAt)
+	rec.Order = new(pb.OrderResult)
+	if err := proto.Unmarshal(b, rec.Order); err != nil {
+		return nil, fmt.Errorf("failed to parse order %d: %+v", rec.Seq, err)
+	}
+	return &rec, nil
+}
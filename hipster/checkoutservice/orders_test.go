@@ -0,0 +1,251 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/checkoutservice/genproto"
+)
+
+func testOrder(id string) *pb.OrderResult {
+	return &pb.OrderResult{
+		OrderId:            id,
+		ShippingTrackingId: "TRACK-" + id,
+		ShippingCost:       &pb.Money{CurrencyCode: "USD", Units: 8, Nanos: 990000000},
+		Items: []*pb.OrderItem{{
+			Item: &pb.CartItem{ProductId: "OLJCESPC7Z", Quantity: 2},
+			Cost: &pb.Money{CurrencyCode: "USD", Units: 67, Nanos: 990000000},
+		}},
+		Packages: []*pb.Package{
+			{TrackingId: "TRACK-" + id, Box: "large", Items: []*pb.CartItem{{ProductId: "OLJCESPC7Z", Quantity: 1}}, WeightKg: 6},
+			{TrackingId: "TRACK2-" + id, Box: "large", Items: []*pb.CartItem{{ProductId: "OLJCESPC7Z", Quantity: 1}}, WeightKg: 6},
+		},
+	}
+}
+
+func testOrderRepository(t *testing.T, r orderRepository) {
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		if err := r.Save(ctx, "alice", testOrder(fmt.Sprintf("a%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Save(ctx, "bob", testOrder("b1")); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := r.Get(ctx, "a2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.UserID != "alice" || !proto.Equal(rec.Order, testOrder("a2")) {
+		t.Errorf("Get(a2) = %s %v, want alice %v", rec.UserID, rec.Order, testOrder("a2"))
+	}
+	if _, err := r.Get(ctx, "missing"); err != errOrderNotFound {
+		t.Errorf("Get(missing) error = %v, want %v", err, errOrderNotFound)
+	}
+
+	page, err := r.List(ctx, "alice", 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := orderIDs(page); fmt.Sprint(got) != "[a3 a2]" {
+		t.Errorf("first page = %v, want [a3 a2]", got)
+	}
+	page, err = r.List(ctx, "alice", page[1].Seq, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := orderIDs(page); fmt.Sprint(got) != "[a1]" {
+		t.Errorf("second page = %v, want [a1]", got)
+	}
+}
+
+func orderIDs(recs []*storedOrder) []string {
+	var out []string
+	for _, rec := range recs {
+		out = append(out, rec.Order.GetOrderId())
+	}
+	return out
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestMemoryOrderRepository(t *testing.T) {
+	testOrderRepository(t, newMemoryOrderRepository())
+}
+
+func TestJSONOrderRepository(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "orders.json")
+
+	r, err := newJSONOrderRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testOrderRepository(t, r)
+
+	// The orders must survive reopening the file, and new orders must not
+	// reuse sequence numbers.
+	r, err = newJSONOrderRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Save(context.Background(), "alice", testOrder("a4")); err != nil {
+		t.Fatal(err)
+	}
+	page, err := r.List(context.Background(), "alice", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := orderIDs(page); fmt.Sprint(got) != "[a4 a3 a2 a1]" {
+		t.Errorf("orders after reopen = %v, want [a4 a3 a2 a1]", got)
+	}
+}
+
+func TestJSONOrderRepositoryFailedWrite(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	r, err := newJSONOrderRepository(filepath.Join(dir, "orders.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := r.Save(ctx, "alice", testOrder("a1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes fail once the directory is gone.
+	r.path = filepath.Join(dir, "missing", "orders.json")
+	if err := r.Save(ctx, "alice", testOrder("a2")); err == nil {
+		t.Fatal("Save() succeeded although the file could not be written")
+	}
+	if err := r.Save(ctx, "bob", testOrder("b1")); err == nil {
+		t.Fatal("Save() succeeded although the file could not be written")
+	}
+	if _, err := r.Get(ctx, "a2"); err != errOrderNotFound {
+		t.Errorf("Get(a2) error = %v, want %v", err, errOrderNotFound)
+	}
+	page, err := r.List(ctx, "alice", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := orderIDs(page); fmt.Sprint(got) != "[a1]" {
+		t.Errorf("orders after failed save = %v, want [a1]", got)
+	}
+	if page, _ := r.List(ctx, "bob", 0, 10); len(page) != 0 {
+		t.Errorf("orders of bob after failed save = %v, want none", orderIDs(page))
+	}
+
+	r.path = filepath.Join(dir, "orders.json")
+	if err := r.Save(ctx, "alice", testOrder("a2")); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := r.Get(ctx, "a2"); err != nil || rec.Seq != 2 {
+		t.Errorf("Get(a2) = %v, %v; want sequence number 2", rec, err)
+	}
+}
+
+func TestSQLiteOrderRepository(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	r, err := newSQLiteOrderRepository(filepath.Join(dir, "orders.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	testOrderRepository(t, r)
+}
+
+func TestSaveOrderAfterCancel(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	r, err := newSQLiteOrderRepository(filepath.Join(dir, "orders.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	cs := &checkoutService{orders: r}
+
+	// The client gave up after the card was charged; the order must be kept.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cs.saveOrder(ctx, "alice", testOrder("a1"))
+	if _, err := r.Get(context.Background(), "a1"); err != nil {
+		t.Errorf("Get() of an order saved after the request was cancelled = %v", err)
+	}
+}
+
+func TestGetAndListOrders(t *testing.T) {
+	ctx := context.Background()
+	cs := &checkoutService{orders: newMemoryOrderRepository()}
+	for i := 1; i <= 3; i++ {
+		cs.saveOrder(ctx, "alice", testOrder(fmt.Sprintf("a%d", i)))
+	}
+
+	if _, err := cs.GetOrder(ctx, &pb.GetOrderRequest{OrderId: "a1", UserId: "alice"}); err != nil {
+		t.Errorf("GetOrder() of own order failed: %v", err)
+	}
+	_, err := cs.GetOrder(ctx, &pb.GetOrderRequest{OrderId: "a1", UserId: "mallory"})
+	if got, want := status.Code(err), codes.NotFound; got != want {
+		t.Errorf("GetOrder() of foreign order code = %s, want %s", got, want)
+	}
+	_, err = cs.GetOrder(ctx, &pb.GetOrderRequest{OrderId: "a1"})
+	if got, want := status.Code(err), codes.InvalidArgument; got != want {
+		t.Errorf("GetOrder() without a user code = %s, want %s", got, want)
+	}
+
+	var got []string
+	token := ""
+	for {
+		resp, err := cs.ListOrders(ctx, &pb.ListOrdersRequest{UserId: "alice", PageSize: 2, PageToken: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range resp.GetOrders() {
+			got = append(got, o.GetOrderId())
+		}
+		if token = resp.GetNextPageToken(); token == "" {
+			break
+		}
+	}
+	if fmt.Sprint(got) != "[a3 a2 a1]" {
+		t.Errorf("listed orders %v, want [a3 a2 a1]", got)
+	}
+
+	_, err = cs.ListOrders(ctx, &pb.ListOrdersRequest{UserId: "alice", PageToken: "bogus"})
+	if got, want := status.Code(err), codes.InvalidArgument; got != want {
+		t.Errorf("ListOrders() with bad token code = %s, want %s", got, want)
+	}
+}
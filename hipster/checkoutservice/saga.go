@@ -0,0 +1,233 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// compensationTimeout bounds the time spent undoing a failed saga. The
+	// undo actions run on a fresh context so that they still happen when
+	// the caller has given up on the request.
+	compensationTimeout = 10 * time.Second
+
+	// maxRecordedSagas is the number of sagas kept for debugging.
+	maxRecordedSagas = 100
+)
+
+type sagaState string
+
+const (
+	sagaRunning            sagaState = "running"
+	sagaCompleted          sagaState = "completed"
+	sagaCompensated        sagaState = "compensated"
+	sagaCompensationFailed sagaState = "compensation_failed"
+)
+
+type stepStatus string
+
+const (
+	stepDone               stepStatus = "done"
+	stepFailed             stepStatus = "failed"
+	stepCompensated        stepStatus = "compensated"
+	stepCompensationFailed stepStatus = "compensation_failed"
+)
+
+// sagaStep is a single checkout step and the action that undoes it.
+type sagaStep struct {
+	Name   string     `json:"name"`
+	Status stepStatus `json:"status"`
+	Error  string     `json:"error,omitempty"`
+
+	compensate func(context.Context) error
+}
+
+// saga runs the steps of one order and, when the order cannot be completed,
+// undoes the steps that already succeeded in reverse order.
+type saga struct {
+	mu         sync.Mutex
+	orderID    string
+	state      sagaState
+	startedAt  time.Time
+	finishedAt time.Time
+	steps      []*sagaStep
+}
+
+func newSaga(orderID string) *saga {
+	return &saga{
+		orderID:   orderID,
+		state:     sagaRunning,
+		startedAt: time.Now(),
+	}
+}
+
+// run executes action as the step name. If the action succeeds, compensate
+// is remembered so that rollback can undo the step later; compensate may be
+// nil for steps that cannot be undone.
+func (s *saga) run(ctx context.Context, name string, action, compensate func(context.Context) error) error {
+	err := action(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	step := &sagaStep{Name: name, Status: stepDone, compensate: compensate}
+	if err != nil {
+		step.Status = stepFailed
+		step.Error = err.Error()
+	}
+	s.steps = append(s.steps, step)
+	return err
+}
+
+// complete marks the saga as finished successfully.
+func (s *saga) complete() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = sagaCompleted
+	s.finishedAt = time.Now()
+}
+
+// rollback compensates every successful step in reverse order. It keeps
+// going when a compensation fails and returns the first failure.
+func (s *saga) rollback() error {
+	ctx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var firstErr error
+	for i := len(s.steps) - 1; i >= 0; i-- {
+		step := s.steps[i]
+		if step.Status != stepDone || step.compensate == nil {
+			continue
+		}
+		if err := step.compensate(ctx); err != nil {
+			step.Status = stepCompensationFailed
+			step.Error = err.Error()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to compensate %s: %+v", step.Name, err)
+			}
+			continue
+		}
+		step.Status = stepCompensated
+	}
+	s.state = sagaCompensated
+	if firstErr != nil {
+		s.state = sagaCompensationFailed
+	}
+	s.finishedAt = time.Now()
+	return firstErr
+}
+
+// sagaSnapshot is the JSON representation of a saga.
+type sagaSnapshot struct {
+	OrderID    string     `json:"order_id"`
+	State      sagaState  `json:"state"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at,omitempty"`
+	Steps      []sagaStep `json:"steps"`
+}
+
+func (s *saga) snapshot() sagaSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := sagaSnapshot{
+		OrderID:    s.orderID,
+		State:      s.state,
+		StartedAt:  s.startedAt,
+		FinishedAt: s.finishedAt,
+		Steps:      make([]sagaStep, len(s.steps)),
+	}
+	for i, step := range s.steps {
+		out.Steps[i] = *step
+	}
+	return out
+}
+
+// sagaLog keeps the most recent sagas in memory so that their state can be
+// inspected through the debug endpoint.
+type sagaLog struct {
+	mu    sync.Mutex
+	max   int
+	order []string
+	byID  map[string]*saga
+}
+
+func newSagaLog(max int) *sagaLog {
+	return &sagaLog{max: max, byID: make(map[string]*saga)}
+}
+
+// record adds s to the log, evicting the oldest saga when the log is full.
+func (l *sagaLog) record(s *saga) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.byID[s.orderID]; !ok {
+		l.order = append(l.order, s.orderID)
+	}
+	l.byID[s.orderID] = s
+	for len(l.order) > l.max {
+		delete(l.byID, l.order[0])
+		l.order = l.order[1:]
+	}
+}
+
+// get returns the saga recorded for orderID.
+func (l *sagaLog) get(orderID string) (*saga, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.byID[orderID]
+	return s, ok
+}
+
+// list returns the recorded sagas, most recent first.
+func (l *sagaLog) list() []*saga {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]*saga, 0, len(l.order))
+	for i := len(l.order) - 1; i >= 0; i-- {
+		out = append(out, l.byID[l.order[i]])
+	}
+	return out
+}
+
+// ServeHTTP serves the recorded sagas as JSON. The order_id query parameter
+// selects a single saga.
+func (l *sagaLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body interface{}
+	if id := r.URL.Query().Get("order_id"); id != "" {
+		s, ok := l.get(id)
+		if !ok {
+			http.Error(w, fmt.Sprintf("no saga for order %q", id), http.StatusNotFound)
+			return
+		}
+		body = s.snapshot()
+	} else {
+		sagas := l.list()
+		snapshots := make([]sagaSnapshot, len(sagas))
+		for i, s := range sagas {
+			snapshots[i] = s.snapshot()
+		}
+		body = snapshots
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.WithError(err).Warn("failed to encode saga state")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSagaRollback(t *testing.T) {
+	ctx := context.Background()
+	s := newSaga("order-1")
+
+	var undone []string
+	undo := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			undone = append(undone, name)
+			return nil
+		}
+	}
+	ok := func(context.Context) error { return nil }
+
+	if err := s.run(ctx, "charge_card", ok, undo("charge_card")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.run(ctx, "reserve", ok, undo("reserve")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.run(ctx, "notify", ok, nil); err != nil {
+		t.Fatal(err)
+	}
+	shipErr := errors.New("shipping unavailable")
+	if err := s.run(ctx, "ship_order", func(context.Context) error { return shipErr }, undo("ship_order")); err != shipErr {
+		t.Fatalf("run() = %v, want %v", err, shipErr)
+	}
+	if err := s.rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"reserve", "charge_card"}; !reflect.DeepEqual(undone, want) {
+		t.Errorf("compensated %v, want %v", undone, want)
+	}
+	snap := s.snapshot()
+	if snap.State != sagaCompensated {
+		t.Errorf("state = %s, want %s", snap.State, sagaCompensated)
+	}
+	want := []stepStatus{stepCompensated, stepCompensated, stepDone, stepFailed}
+	for i, step := range snap.Steps {
+		if step.Status != want[i] {
+			t.Errorf("step %s status = %s, want %s", step.Name, step.Status, want[i])
+		}
+	}
+}
+
+func TestSagaRollbackFailure(t *testing.T) {
+	ctx := context.Background()
+	s := newSaga("order-2")
+	voidErr := errors.New("void rejected")
+	s.run(ctx, "charge_card", func(context.Context) error { return nil }, func(context.Context) error { return voidErr })
+
+	if err := s.rollback(); err == nil {
+		t.Fatal("rollback() succeeded, want error")
+	}
+	snap := s.snapshot()
+	if snap.State != sagaCompensationFailed {
+		t.Errorf("state = %s, want %s", snap.State, sagaCompensationFailed)
+	}
+	if got := snap.Steps[0]; got.Status != stepCompensationFailed || got.Error != voidErr.Error() {
+		t.Errorf("step = %+v, want status %s with error %q", got, stepCompensationFailed, voidErr)
+	}
+}
+
+func TestSagaLog(t *testing.T) {
+	l := newSagaLog(2)
+	for _, id := range []string{"a", "b", "c"} {
+		s := newSaga(id)
+		s.complete()
+		l.record(s)
+	}
+	if _, ok := l.get("a"); ok {
+		t.Error("oldest saga was not evicted")
+	}
+
+	rec := httptest.NewRecorder()
+	l.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/sagas", nil))
+	var got []sagaSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].OrderID != "c" || got[1].OrderID != "b" {
+		t.Errorf("listed sagas %+v, want c then b", got)
+	}
+
+	rec = httptest.NewRecorder()
+	l.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/sagas?order_id=a", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status for evicted saga = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
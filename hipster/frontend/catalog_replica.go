@@ -0,0 +1,148 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+)
+
+const (
+	// replicaMinBackoff and replicaMaxBackoff bound the wait before the
+	// replica reopens a catalog watch that failed.
+	replicaMinBackoff = 500 * time.Millisecond
+	replicaMaxBackoff = 30 * time.Second
+)
+
+// catalogReplica keeps a local copy of the product catalog in sync with the
+// catalog service through its WatchCatalog stream. Lookups are only answered
+// while the replica is in sync; callers fall back to RPCs otherwise.
+//
+// Committed snapshots are never modified, so they can be read without holding
+// the lock. Products added by an update are listed after existing ones.
+type catalogReplica struct {
+	mu       sync.RWMutex
+	products map[string]*pb.Product
+	order    []string
+	version  int64
+	synced   bool
+}
+
+// snapshot returns the replicated products, keyed by ID and in catalog order,
+// and whether the replica is in sync. A nil replica is never in sync.
+func (r *catalogReplica) snapshot() (products map[string]*pb.Product, order []string, ok bool) {
+	if r == nil {
+		return nil, nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.products, r.order, r.synced
+}
+
+func (r *catalogReplica) commit(products map[string]*pb.Product, order []string, version int64) {
+	snapshot := make(map[string]*pb.Product, len(products))
+	for id, p := range products {
+		snapshot[id] = p
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.products = snapshot
+	r.order = append([]string(nil), order...)
+	r.version = version
+	r.synced = true
+}
+
+func (r *catalogReplica) setUnsynced() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.synced = false
+}
+
+// run keeps the replica in sync until ctx is cancelled, reopening the watch
+// with exponential backoff whenever it fails.
+func (r *catalogReplica) run(ctx context.Context, client pb.ProductCatalogServiceClient) {
+	backoff := replicaMinBackoff
+	for {
+		synced, err := r.watch(ctx, client)
+		r.setUnsynced()
+		if ctx.Err() != nil {
+			return
+		}
+		if synced {
+			backoff = replicaMinBackoff
+		}
+		log.WithError(err).WithField("retry_in", backoff.String()).
+			Warn("catalog watch failed, falling back to catalog RPCs")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > replicaMaxBackoff {
+			backoff = replicaMaxBackoff
+		}
+	}
+}
+
+// watch applies the events of one WatchCatalog stream until it fails. Each
+// stream starts with a snapshot of the whole catalog, so the working copy
+// starts out empty. It reports whether the stream got as far as a SYNCED
+// event.
+func (r *catalogReplica) watch(ctx context.Context, client pb.ProductCatalogServiceClient) (synced bool, err error) {
+	stream, err := client.WatchCatalog(ctx, &pb.WatchCatalogRequest{})
+	if err != nil {
+		return false, err
+	}
+	products := make(map[string]*pb.Product)
+	var order []string
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			return synced, err
+		}
+		id := e.GetProduct().GetId()
+		switch e.GetType() {
+		case pb.CatalogEvent_ADDED, pb.CatalogEvent_UPDATED:
+			if _, ok := products[id]; !ok {
+				order = append(order, id)
+			}
+			products[id] = e.GetProduct()
+		case pb.CatalogEvent_REMOVED:
+			if _, ok := products[id]; ok {
+				delete(products, id)
+				order = removeID(order, id)
+			}
+		case pb.CatalogEvent_SYNCED:
+			r.commit(products, order, e.GetVersion())
+			if !synced {
+				log.WithField("version", e.GetVersion()).WithField("products", len(products)).
+					Info("catalog replica in sync")
+			}
+			synced = true
+		}
+	}
+}
+
+func removeID(ids []string, id string) []string {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+)
+
+// fakeWatchCatalog streams the events sent on its channel and fails the
+// stream, and every stream after it, once the channel is closed.
+type fakeWatchCatalog struct {
+	pb.ProductCatalogServiceServer
+	events chan *pb.CatalogEvent
+}
+
+func (f fakeWatchCatalog) WatchCatalog(req *pb.WatchCatalogRequest, stream pb.ProductCatalogService_WatchCatalogServer) error {
+	for e := range f.events {
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+	return errors.New("catalog went away")
+}
+
+func product(id string, units int64) *pb.Product {
+	return &pb.Product{Id: id, PriceUsd: &pb.Money{CurrencyCode: "USD", Units: units}}
+}
+
+// waitForReplica polls r until cond holds.
+func waitForReplica(t *testing.T, r *catalogReplica, cond func(version int64, synced bool) bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		r.mu.RLock()
+		version, synced := r.version, r.synced
+		r.mu.RUnlock()
+		if cond(version, synced) {
+			return
+		}
+	}
+	t.Fatal("timed out waiting for the catalog replica")
+}
+
+func TestCatalogReplica(t *testing.T) {
+	log = logrus.New()
+	log.Out = ioutil.Discard
+
+	events := make(chan *pb.CatalogEvent, 10)
+	conn := dialFake(t, func(s *grpc.Server) {
+		pb.RegisterProductCatalogServiceServer(s, fakeWatchCatalog{events: events})
+	})
+	fe := &frontendServer{productCatalogSvcConn: conn, catalog: new(catalogReplica)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fe.catalog.run(ctx, pb.NewProductCatalogServiceClient(conn))
+
+	events <- &pb.CatalogEvent{Type: pb.CatalogEvent_ADDED, Product: product("A", 1), Version: 1}
+	events <- &pb.CatalogEvent{Type: pb.CatalogEvent_ADDED, Product: product("B", 2), Version: 1}
+	events <- &pb.CatalogEvent{Type: pb.CatalogEvent_SYNCED, Version: 1}
+	waitForReplica(t, fe.catalog, func(v int64, synced bool) bool { return synced && v == 1 })
+
+	products, err := fe.getProducts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := []string{products[0].GetId(), products[1].GetId()}, []string{"A", "B"}; len(products) != 2 || !reflect.DeepEqual(got, want) {
+		t.Errorf("getProducts() = %v, want %v", products, want)
+	}
+
+	events <- &pb.CatalogEvent{Type: pb.CatalogEvent_UPDATED, Product: product("A", 5), Version: 2}
+	events <- &pb.CatalogEvent{Type: pb.CatalogEvent_REMOVED, Product: &pb.Product{Id: "B"}, Version: 2}
+	events <- &pb.CatalogEvent{Type: pb.CatalogEvent_ADDED, Product: product("C", 3), Version: 2}
+	events <- &pb.CatalogEvent{Type: pb.CatalogEvent_SYNCED, Version: 2}
+	waitForReplica(t, fe.catalog, func(v int64, synced bool) bool { return v == 2 })
+
+	if p, err := fe.getProduct(ctx, "A"); err != nil || p.GetPriceUsd().GetUnits() != 5 {
+		t.Errorf("getProduct(A) = %v, %v; want the updated price", p, err)
+	}
+	if _, err := fe.getProduct(ctx, "B"); err == nil {
+		t.Error("getProduct(B) found a removed product")
+	}
+	if _, err := fe.getProductsByID(ctx, []string{"A", "C"}); err != nil {
+		t.Errorf("getProductsByID(A, C): %v", err)
+	}
+
+	// Until the stream is reopened and synced again, lookups go to the
+	// catalog service.
+	close(events)
+	waitForReplica(t, fe.catalog, func(v int64, synced bool) bool { return !synced })
+	if _, _, ok := fe.catalog.snapshot(); ok {
+		t.Error("snapshot() is usable after the watch failed")
+	}
+}
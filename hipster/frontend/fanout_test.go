@@ -0,0 +1,138 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+)
+
+func TestFanOutKeepsOrder(t *testing.T) {
+	out := make([]int, 50)
+	err := fanOut(context.Background(), len(out), 4, func(ctx context.Context, i int) error {
+		time.Sleep(time.Duration(len(out)-i) * 10 * time.Microsecond)
+		out[i] = i * i
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range out {
+		if v != i*i {
+			t.Fatalf("out[%d] = %d, want %d", i, v, i*i)
+		}
+	}
+}
+
+// fakeLatency is how long each fake downstream RPC takes in the benchmarks.
+const fakeLatency = time.Millisecond
+
+type fakeCatalog struct {
+	pb.ProductCatalogServiceServer
+}
+
+func (fakeCatalog) GetProducts(ctx context.Context, req *pb.GetProductsRequest) (*pb.GetProductsResponse, error) {
+	time.Sleep(fakeLatency)
+	resp := &pb.GetProductsResponse{}
+	for _, id := range req.GetIds() {
+		resp.Products = append(resp.Products, &pb.Product{Id: id, PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 10}})
+	}
+	return resp, nil
+}
+
+type fakeCurrency struct {
+	pb.CurrencyServiceServer
+}
+
+func (fakeCurrency) Convert(ctx context.Context, req *pb.CurrencyConversionRequest) (*pb.Money, error) {
+	time.Sleep(fakeLatency)
+	return &pb.Money{CurrencyCode: req.GetToCode(), Units: req.GetFrom().GetUnits() * 2}, nil
+}
+
+// dialFake serves register on an in-memory listener and returns a client
+// connection to it.
+func dialFake(b testing.TB, register func(*grpc.Server)) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	register(srv)
+	go srv.Serve(lis)
+	b.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+type fakeRecommendations struct {
+	pb.RecommendationServiceServer
+}
+
+func (fakeRecommendations) ListRecommendations(ctx context.Context, req *pb.ListRecommendationsRequest) (*pb.ListRecommendationsResponse, error) {
+	return &pb.ListRecommendationsResponse{ProductIds: []string{"R0", "R1", "R2", "R3", "R4"}}, nil
+}
+
+func newFakeFrontend(b *testing.B, limit int) *frontendServer {
+	return &frontendServer{
+		productCatalogSvcConn: dialFake(b, func(s *grpc.Server) { pb.RegisterProductCatalogServiceServer(s, fakeCatalog{}) }),
+		currencySvcConn:       dialFake(b, func(s *grpc.Server) { pb.RegisterCurrencyServiceServer(s, fakeCurrency{}) }),
+		recommendationSvcConn: dialFake(b, func(s *grpc.Server) { pb.RegisterRecommendationServiceServer(s, fakeRecommendations{}) }),
+		fanOutLimit:           limit,
+	}
+}
+
+func benchmarkCartItemViews(b *testing.B, limit int) {
+	fe := newFakeFrontend(b, limit)
+	cart := make([]*pb.CartItem, 10)
+	for i := range cart {
+		cart[i] = &pb.CartItem{ProductId: fmt.Sprintf("P%d", i), Quantity: 1}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		items, err := fe.cartItemViews(context.Background(), cart, "EUR")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if items[3].Item.GetId() != "P3" {
+			b.Fatalf("items[3] = %s, want P3", items[3].Item.GetId())
+		}
+	}
+}
+
+func BenchmarkGetRecommendations(b *testing.B) {
+	fe := newFakeFrontend(b, 0)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := fe.getRecommendations(context.Background(), "u", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCartItemViewsSequential(b *testing.B) { benchmarkCartItemViews(b, 1) }
+func BenchmarkCartItemViewsFanOut(b *testing.B)     { benchmarkCartItemViews(b, 0) }
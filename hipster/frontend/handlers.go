@@ -16,18 +16,25 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
 	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/money"
@@ -102,6 +109,73 @@ func (fe *frontendServer) homeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (fe *frontendServer) searchHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+	query := strings.TrimSpace(r.FormValue("q"))
+	categories := r.Form["category"]
+	log.WithField("query", query).Debug("search")
+
+	currencies, err := fe.getCurrencies(r.Context())
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve currencies"), http.StatusInternalServerError)
+		return
+	}
+	res, err := fe.searchProducts(r.Context(), query, categories, r.FormValue("page_token"))
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "could not search products"), http.StatusInternalServerError)
+		return
+	}
+	cart, err := fe.getCart(r.Context(), sessionID(r))
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve cart"), http.StatusInternalServerError)
+		return
+	}
+
+	type productView struct {
+		Item  *pb.Product
+		Price *pb.Money
+	}
+	ps := make([]productView, len(res.GetResults()))
+	err = fanOut(r.Context(), len(ps), fe.fanOutLimit, func(ctx context.Context, i int) error {
+		p := res.GetResults()[i]
+		price, err := fe.convertCurrency(ctx, p.GetPriceUsd(), currentCurrency(r))
+		if err != nil {
+			return errors.Wrapf(err, "failed to do currency conversion for product %s", p.GetId())
+		}
+		ps[i] = productView{p, price}
+		return nil
+	})
+	if err != nil {
+		renderHTTPError(log, r, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Carry the query and category filter over to the next page.
+	var nextPage string
+	if token := res.GetNextPageToken(); token != "" {
+		v := url.Values{"q": {query}, "category": categories, "page_token": {token}}
+		nextPage = "/search?" + v.Encode()
+	}
+
+	if err := templates.ExecuteTemplate(w, "search", map[string]interface{}{
+		"session_id":    sessionID(r),
+		"request_id":    r.Context().Value(ctxKeyRequestID{}),
+		"user_currency": currentCurrency(r),
+		"show_currency": true,
+		"currencies":    currencies,
+		"query":         query,
+		"categories":    categories,
+		"products":      ps,
+		"total_size":    res.GetTotalSize(),
+		"next_page":     nextPage,
+		"cart_size":     cartSize(cart),
+		"platform_css":  plat.css,
+		"platform_name": plat.provider,
+	}); err != nil {
+		log.Error(err)
+	}
+}
+
 func (plat *platformDetails) setPlatformDetails(env string) {
 	if env == "aws" {
 		plat.provider = "AWS"
@@ -226,9 +300,37 @@ func (fe *frontendServer) emptyCartHandler(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusFound)
 }
 
+// checkoutForm holds the fields of the checkout form that are shown again
+// when an order is rejected, and the errors found in them by field name.
+type checkoutForm struct {
+	Email         string
+	StreetAddress string
+	PostalCode    string
+	City          string
+	State         string
+	Country       string
+	Errors        map[string]string
+}
+
+// defaultCheckoutForm fills in the checkout form for demos.
+var defaultCheckoutForm = checkoutForm{
+	Email:         "someone@example.com",
+	StreetAddress: "1600 Amphitheatre Parkway",
+	PostalCode:    "94043",
+	City:          "Mountain View",
+	State:         "CA",
+	Country:       "United States",
+}
+
 func (fe *frontendServer) viewCartHandler(w http.ResponseWriter, r *http.Request) {
 	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
 	log.Debug("view user cart")
+	fe.renderCart(w, r, defaultCheckoutForm, http.StatusOK)
+}
+
+// renderCart renders the cart and the checkout form filled in with form.
+func (fe *frontendServer) renderCart(w http.ResponseWriter, r *http.Request, form checkoutForm, code int) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
 	currencies, err := fe.getCurrencies(r.Context())
 	if err != nil {
 		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve currencies"), http.StatusInternalServerError)
@@ -246,40 +348,38 @@ func (fe *frontendServer) viewCartHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	shippingCost, err := fe.getShippingQuote(r.Context(), cart, currentCurrency(r))
+	items, err := fe.cartItemViews(r.Context(), cart, currentCurrency(r))
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to get shipping quote"), http.StatusInternalServerError)
+		renderHTTPError(log, r, w, err, http.StatusInternalServerError)
 		return
 	}
+	totalPrice := pb.Money{CurrencyCode: currentCurrency(r)}
+	subtotalUSD := pb.Money{CurrencyCode: "USD"}
+	for _, item := range items {
+		totalPrice = money.Must(money.Sum(totalPrice, *item.Price))
+		subtotalUSD = money.Must(money.Sum(subtotalUSD, money.MultiplySlow(*item.Item.GetPriceUsd(), uint32(item.Quantity))))
+	}
 
-	type cartItemView struct {
-		Item     *pb.Product
-		Quantity int32
-		Price    *pb.Money
+	shippingOptions, err := fe.getShippingOptions(r.Context(), cart, &subtotalUSD, currentCurrency(r))
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to get shipping quote"), http.StatusInternalServerError)
+		return
+	}
+	var shippingCost *pb.Money
+	if len(shippingOptions) > 0 {
+		shippingCost = shippingOptions[0].Cost
 	}
-	items := make([]cartItemView, len(cart))
-	totalPrice := pb.Money{CurrencyCode: currentCurrency(r)}
-	for i, item := range cart {
-		p, err := fe.getProduct(r.Context(), item.GetProductId())
-		if err != nil {
-			renderHTTPError(log, r, w, errors.Wrapf(err, "could not retrieve product #%s", item.GetProductId()), http.StatusInternalServerError)
-			return
-		}
-		price, err := fe.convertCurrency(r.Context(), p.GetPriceUsd(), currentCurrency(r))
-		if err != nil {
-			renderHTTPError(log, r, w, errors.Wrapf(err, "could not convert currency for product #%s", item.GetProductId()), http.StatusInternalServerError)
-			return
-		}
 
-		multPrice := money.MultiplySlow(*price, uint32(item.GetQuantity()))
-		items[i] = cartItemView{
-			Item:     p,
-			Quantity: item.GetQuantity(),
-			Price:    &multPrice}
-		totalPrice = money.Must(money.Sum(totalPrice, multPrice))
+	// Each rendering of the checkout form gets its own idempotency key so
+	// that a resubmitted form does not place the order twice.
+	idempotencyKey, err := uuid.NewRandom()
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to generate idempotency key"), http.StatusInternalServerError)
+		return
 	}
 
 	year := time.Now().Year()
+	w.WriteHeader(code)
 	if err := templates.ExecuteTemplate(w, "cart", map[string]interface{}{
 		"session_id":       sessionID(r),
 		"request_id":       r.Context().Value(ctxKeyRequestID{}),
@@ -288,10 +388,13 @@ func (fe *frontendServer) viewCartHandler(w http.ResponseWriter, r *http.Request
 		"recommendations":  recommendations,
 		"cart_size":        cartSize(cart),
 		"shipping_cost":    shippingCost,
+		"shipping_options": shippingOptions,
 		"show_currency":    true,
 		"total_cost":       totalPrice,
 		"items":            items,
 		"expiration_years": []int{year, year + 1, year + 2, year + 3, year + 4},
+		"idempotency_key":  idempotencyKey.String(),
+		"form":             form,
 		"platform_css":     plat.css,
 		"platform_name":    plat.provider,
 	}); err != nil {
@@ -304,21 +407,25 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 	log.Debug("placing order")
 
 	var (
-		email         = r.FormValue("email")
-		streetAddress = r.FormValue("street_address")
-		zipCode, _    = strconv.ParseInt(r.FormValue("zip_code"), 10, 32)
-		city          = r.FormValue("city")
-		state         = r.FormValue("state")
-		country       = r.FormValue("country")
-		ccNumber      = r.FormValue("credit_card_number")
-		ccMonth, _    = strconv.ParseInt(r.FormValue("credit_card_expiration_month"), 10, 32)
-		ccYear, _     = strconv.ParseInt(r.FormValue("credit_card_expiration_year"), 10, 32)
-		ccCVV, _      = strconv.ParseInt(r.FormValue("credit_card_cvv"), 10, 32)
+		form = checkoutForm{
+			Email:         r.FormValue("email"),
+			StreetAddress: r.FormValue("street_address"),
+			PostalCode:    r.FormValue("postal_code"),
+			City:          r.FormValue("city"),
+			State:         r.FormValue("state"),
+			Country:       r.FormValue("country"),
+		}
+		ccNumber       = r.FormValue("credit_card_number")
+		ccMonth, _     = strconv.ParseInt(r.FormValue("credit_card_expiration_month"), 10, 32)
+		ccYear, _      = strconv.ParseInt(r.FormValue("credit_card_expiration_year"), 10, 32)
+		ccCVV, _       = strconv.ParseInt(r.FormValue("credit_card_cvv"), 10, 32)
+		idempotencyKey = r.FormValue("idempotency_key")
+		shippingLevel  = r.FormValue("shipping_service_level")
 	)
 
 	order, err := pb.NewCheckoutServiceClient(fe.checkoutSvcConn).
 		PlaceOrder(r.Context(), &pb.PlaceOrderRequest{
-			Email: email,
+			Email: form.Email,
 			CreditCard: &pb.CreditCardInfo{
 				CreditCardNumber:          ccNumber,
 				CreditCardExpirationMonth: int32(ccMonth),
@@ -327,12 +434,19 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 			UserId:       sessionID(r),
 			UserCurrency: currentCurrency(r),
 			Address: &pb.Address{
-				StreetAddress: streetAddress,
-				City:          city,
-				State:         state,
-				ZipCode:       int32(zipCode),
-				Country:       country},
+				StreetAddress: form.StreetAddress,
+				City:          form.City,
+				State:         form.State,
+				PostalCode:    form.PostalCode,
+				Country:       form.Country},
+			IdempotencyKey:       idempotencyKey,
+			ShippingServiceLevel: shippingLevel,
 		})
+	if form.Errors = addressErrors(err); form.Errors != nil {
+		log.WithField("error", err).Info("order rejected for its address")
+		fe.renderCart(w, r, form, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		renderHTTPError(log, r, w, errors.Wrap(err, "failed to complete the order"), http.StatusInternalServerError)
 		return
@@ -342,11 +456,7 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 	order.GetOrder().GetItems()
 	recommendations, _ := fe.getRecommendations(r.Context(), sessionID(r), nil)
 
-	totalPaid := *order.GetOrder().GetShippingCost()
-	for _, v := range order.GetOrder().GetItems() {
-		multPrice := money.MultiplySlow(*v.GetCost(), uint32(v.GetItem().GetQuantity()))
-		totalPaid = money.Must(money.Sum(totalPaid, multPrice))
-	}
+	totalPaid := orderTotal(order.GetOrder())
 
 	currencies, err := fe.getCurrencies(r.Context())
 	if err != nil {
@@ -361,6 +471,7 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 		"show_currency":   false,
 		"currencies":      currencies,
 		"order":           order.GetOrder(),
+		"order_placed":    true,
 		"total_paid":      &totalPaid,
 		"recommendations": recommendations,
 		"platform_css":    plat.css,
@@ -370,6 +481,122 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+func (fe *frontendServer) ordersHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+	log.Debug("listing orders")
+
+	resp, err := fe.listOrders(r.Context(), sessionID(r), r.FormValue("page_token"))
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve orders"), http.StatusInternalServerError)
+		return
+	}
+
+	type orderView struct {
+		Order     *pb.OrderResult
+		ItemCount int
+		Total     pb.Money
+	}
+	orders := make([]orderView, len(resp.GetOrders()))
+	for i, o := range resp.GetOrders() {
+		count := 0
+		for _, item := range o.GetItems() {
+			count += int(item.GetItem().GetQuantity())
+		}
+		orders[i] = orderView{Order: o, ItemCount: count, Total: orderTotal(o)}
+	}
+
+	if err := templates.ExecuteTemplate(w, "orders", map[string]interface{}{
+		"session_id":      sessionID(r),
+		"request_id":      r.Context().Value(ctxKeyRequestID{}),
+		"show_currency":   false,
+		"orders":          orders,
+		"next_page_token": resp.GetNextPageToken(),
+		"platform_css":    plat.css,
+		"platform_name":   plat.provider,
+	}); err != nil {
+		log.Println(err)
+	}
+}
+
+func (fe *frontendServer) orderHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+	id := mux.Vars(r)["id"]
+	log.WithField("id", id).Debug("serving order page")
+
+	order, err := fe.getOrder(r.Context(), sessionID(r), id)
+	if status.Code(err) == codes.NotFound {
+		renderHTTPError(log, r, w, errors.Wrap(err, "order not found"), http.StatusNotFound)
+		return
+	} else if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve order"), http.StatusInternalServerError)
+		return
+	}
+	totalPaid := orderTotal(order)
+
+	if err := templates.ExecuteTemplate(w, "order", map[string]interface{}{
+		"session_id":    sessionID(r),
+		"request_id":    r.Context().Value(ctxKeyRequestID{}),
+		"show_currency": false,
+		"order":         order,
+		"order_placed":  false,
+		"total_paid":    &totalPaid,
+		"platform_css":  plat.css,
+		"platform_name": plat.provider,
+	}); err != nil {
+		log.Println(err)
+	}
+}
+
+// shipmentEventView is a step of a shipment's history as the track page
+// shows it.
+type shipmentEventView struct {
+	State string
+	Time  time.Time
+}
+
+// shipmentStateNames are the display names of shipment states.
+var shipmentStateNames = map[pb.ShipmentStatus_State]string{
+	pb.ShipmentStatus_LABEL_CREATED:    "Label created",
+	pb.ShipmentStatus_IN_TRANSIT:       "In transit",
+	pb.ShipmentStatus_OUT_FOR_DELIVERY: "Out for delivery",
+	pb.ShipmentStatus_DELIVERED:        "Delivered",
+}
+
+func (fe *frontendServer) trackHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+	id := mux.Vars(r)["id"]
+	log.WithField("tracking_id", id).Debug("serving track page")
+
+	st, err := fe.getShipmentStatus(r.Context(), id)
+	if status.Code(err) == codes.NotFound {
+		renderHTTPError(log, r, w, errors.Wrap(err, "shipment not found"), http.StatusNotFound)
+		return
+	} else if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve shipment status"), http.StatusInternalServerError)
+		return
+	}
+	// Most recent first.
+	events := make([]shipmentEventView, len(st.GetEvents()))
+	for i, e := range st.GetEvents() {
+		events[len(events)-1-i] = shipmentEventView{State: shipmentStateNames[e.GetState()], Time: time.Unix(e.GetTime(), 0).UTC()}
+	}
+
+	if err := templates.ExecuteTemplate(w, "track", map[string]interface{}{
+		"session_id":         sessionID(r),
+		"request_id":         r.Context().Value(ctxKeyRequestID{}),
+		"show_currency":      false,
+		"shipment":           st,
+		"state":              shipmentStateNames[st.GetState()],
+		"delivered":          st.GetState() == pb.ShipmentStatus_DELIVERED,
+		"estimated_delivery": time.Unix(st.GetEstimatedDelivery(), 0).UTC(),
+		"events":             events,
+		"platform_css":       plat.css,
+		"platform_name":      plat.provider,
+	}); err != nil {
+		log.Println(err)
+	}
+}
+
 func (fe *frontendServer) logoutHandler(w http.ResponseWriter, r *http.Request) {
 	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
 	log.Debug("logging out")
@@ -414,6 +641,34 @@ func (fe *frontendServer) chooseAd(ctx context.Context, ctxKeys []string, log lo
 	return ads[rand.Intn(len(ads))]
 }
 
+// addressErrors returns the address violations of a rejected order by the
+// name of the checkout form field they concern, or nil if err is not about
+// the address.
+func addressErrors(err error) map[string]string {
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		return nil
+	}
+	var out map[string]string
+	for _, d := range st.Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range br.GetFieldViolations() {
+			field := v.GetField()
+			if field != "address" && !strings.HasPrefix(field, "address.") {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]string)
+			}
+			out[strings.TrimPrefix(field, "address.")] = v.GetDescription()
+		}
+	}
+	return out
+}
+
 func renderHTTPError(log logrus.FieldLogger, r *http.Request, w http.ResponseWriter, err error, code int) {
 	log.WithField("error", err).Error("request error")
 	errMsg := fmt.Sprintf("%+v", err)
@@ -454,6 +709,52 @@ func cartIDs(c []*pb.CartItem) []string {
 	return out
 }
 
+type cartItemView struct {
+	Item     *pb.Product
+	Quantity int32
+	Price    *pb.Money
+}
+
+// cartItemViews looks up every cart item and prices it in currency. The
+// products are fetched in one batch and the currency conversions run
+// concurrently; the result keeps the order of cart.
+func (fe *frontendServer) cartItemViews(ctx context.Context, cart []*pb.CartItem, currency string) ([]cartItemView, error) {
+	products, err := fe.getProductsByID(ctx, cartIDs(cart))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve cart products")
+	}
+
+	items := make([]cartItemView, len(cart))
+	err = fanOut(ctx, len(cart), fe.fanOutLimit, func(ctx context.Context, i int) error {
+		item := cart[i]
+		p := products[item.GetProductId()]
+		price, err := fe.convertCurrency(ctx, p.GetPriceUsd(), currency)
+		if err != nil {
+			return errors.Wrapf(err, "could not convert currency for product #%s", item.GetProductId())
+		}
+		multPrice := money.MultiplySlow(*price, uint32(item.GetQuantity()))
+		items[i] = cartItemView{
+			Item:     p,
+			Quantity: item.GetQuantity(),
+			Price:    &multPrice}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// orderTotal returns the amount paid for an order, shipping included.
+func orderTotal(o *pb.OrderResult) pb.Money {
+	total := *o.GetShippingCost()
+	for _, v := range o.GetItems() {
+		multPrice := money.MultiplySlow(*v.GetCost(), uint32(v.GetItem().GetQuantity()))
+		total = money.Must(money.Sum(total, multPrice))
+	}
+	return total
+}
+
 // get total # of items in cart
 func cartSize(c []*pb.CartItem) int {
 	cartSize := 0
@@ -466,3 +767,17 @@ func cartSize(c []*pb.CartItem) int {
 func renderMoney(money pb.Money) string {
 	return fmt.Sprintf("%s %d.%02d", money.GetCurrencyCode(), money.GetUnits(), money.GetNanos()/10000000)
 }
+
+// healthzHandler reports the health of the frontend, broken down by
+// downstream connection, as JSON. It responds 503 unless every component is
+// healthy.
+func (fe *frontendServer) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	report, _ := fe.health.Evaluate(r.Context(), "")
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != healthpb.HealthCheckResponse_SERVING.String() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.WithError(err).Warn("failed to write health report")
+	}
+}
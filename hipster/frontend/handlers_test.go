@@ -0,0 +1,149 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+)
+
+func TestTrackHandler(t *testing.T) {
+	fe := &frontendServer{
+		shippingSvcConn: dialFake(t, func(s *grpc.Server) { pb.RegisterShippingServiceServer(s, fakeShipping{}) }),
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/track/{id}", fe.trackHandler)
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	for _, tc := range []struct {
+		id   string
+		code int
+		want []string
+	}{
+		{"AB-1", http.StatusOK, []string{
+			"Shipment AB-1", "In transit", "Estimated delivery", "Fri, Jan 3 18:00 UTC",
+			"Wed, Jan 1 06:00 UTC", "Label created",
+		}},
+		{"XX-0", http.StatusNotFound, []string{"shipment not found"}},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/track/"+tc.id, nil)
+		req = req.WithContext(context.WithValue(req.Context(), ctxKeyLog{}, logrus.FieldLogger(logger)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Errorf("GET /track/%s: status %d, want %d", tc.id, w.Code, tc.code)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("GET /track/%s: page does not contain %q", tc.id, want)
+			}
+		}
+	}
+}
+
+type fakeCheckout struct {
+	pb.CheckoutServiceServer
+}
+
+func (fakeCheckout) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.OrderResult, error) {
+	return &pb.OrderResult{
+		OrderId:            req.GetOrderId(),
+		ShippingTrackingId: "HS-1",
+		ShippingCost:       &pb.Money{CurrencyCode: "USD", Units: 5},
+		Items: []*pb.OrderItem{
+			{Item: &pb.CartItem{ProductId: "9SIQT8TOJO", Quantity: 1}, Cost: &pb.Money{CurrencyCode: "USD", Units: 790}},
+			{Item: &pb.CartItem{ProductId: "LS4PSXUNUM", Quantity: 2}, Cost: &pb.Money{CurrencyCode: "USD", Units: 25}},
+		},
+		Packages: []*pb.Package{
+			{TrackingId: "HS-1", Items: []*pb.CartItem{{ProductId: "9SIQT8TOJO", Quantity: 1}}, WeightKg: 14},
+			{TrackingId: "HS-2", Box: "small", Items: []*pb.CartItem{{ProductId: "LS4PSXUNUM", Quantity: 2}}, WeightKg: 0.6},
+		},
+	}, nil
+}
+
+func TestOrderHandlerPackages(t *testing.T) {
+	fe := &frontendServer{
+		checkoutSvcConn: dialFake(t, func(s *grpc.Server) { pb.RegisterCheckoutServiceServer(s, fakeCheckout{}) }),
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/orders/{id}", fe.orderHandler)
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/o1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKeyLog{}, logrus.FieldLogger(logger)))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /orders/o1: status %d", w.Code)
+	}
+	for _, want := range []string{
+		"2 Packages", `href="/track/HS-1"`, "(14.0 kg)", `href="/track/HS-2"`, "(small box, 0.6 kg)",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET /orders/o1: page does not contain %q", want)
+		}
+	}
+}
+
+func TestAddressErrors(t *testing.T) {
+	invalid, err := status.New(codes.InvalidArgument, "invalid address").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "address.postal_code", Description: "postal code must look like 94043 in United States"},
+			{Field: "address.state", Description: "state is required in United States"},
+			{Field: "credit_card.number", Description: "invalid card"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := addressErrors(invalid.Err())
+	want := map[string]string{
+		"postal_code": "postal code must look like 94043 in United States",
+		"state":       "state is required in United States",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("addressErrors() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("addressErrors()[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+
+	for _, err := range []error{
+		nil,
+		status.Error(codes.Unavailable, "shipping error"),
+		status.Error(codes.InvalidArgument, "unknown service level"),
+	} {
+		if got := addressErrors(err); got != nil {
+			t.Errorf("addressErrors(%v) = %v, want nil", err, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability/health"
+)
+
+func TestHealthz(t *testing.T) {
+	fe := &frontendServer{health: health.NewServer()}
+	fe.health.Register("cartservice", func(context.Context) error { return nil })
+
+	get := func() (int, health.Report) {
+		w := httptest.NewRecorder()
+		fe.healthzHandler(w, httptest.NewRequest(http.MethodGet, "/_healthz", nil))
+		var r health.Report
+		if err := json.Unmarshal(w.Body.Bytes(), &r); err != nil {
+			t.Fatalf("invalid health report %q: %v", w.Body.String(), err)
+		}
+		return w.Code, r
+	}
+
+	if code, r := get(); code != http.StatusOK || r.Status != "SERVING" || r.Components["cartservice"].Status != "SERVING" {
+		t.Errorf("healthy: got %d %+v", code, r)
+	}
+
+	fe.health.Register("adservice", func(context.Context) error { return errors.New("connection refused") })
+	if code, r := get(); code != http.StatusServiceUnavailable || r.Components["adservice"].Error != "connection refused" {
+		t.Errorf("with a failing component: got %d %+v", code, r)
+	}
+
+	fe.health = health.NewServer()
+	fe.health.Shutdown()
+	if code, r := get(); code != http.StatusServiceUnavailable || r.Status != "NOT_SERVING" {
+		t.Errorf("shutting down: got %d %+v", code, r)
+	}
+}
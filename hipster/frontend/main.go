@@ -22,25 +22,17 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp"
-	"go.opentelemetry.io/otel/exporters/stdout"
 
-	"go.opentelemetry.io/otel/label"
-	"go.opentelemetry.io/otel/sdk/resource"
-	"go.opentelemetry.io/otel/semconv"
 	"google.golang.org/grpc"
 
-	"go.opentelemetry.io/otel/exporters/trace/jaeger"
-	"go.opentelemetry.io/otel/propagation"
-	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
-	"go.opentelemetry.io/otel/sdk/trace"
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability/health"
 )
 
 const (
@@ -64,8 +56,8 @@ var (
 		"GBP": true,
 		"TRY": true}
 
-    serviceName string
-    serviceNameSpace string
+	// serviceName names the service in spans and metrics.
+	serviceName string
 )
 
 type ctxKeySessionID struct{}
@@ -91,6 +83,16 @@ type frontendServer struct {
 
 	adSvcAddr string
 	adSvcConn *grpc.ClientConn
+
+	// fanOutLimit bounds concurrent per-item downstream calls; zero means
+	// defaultFanOutLimit.
+	fanOutLimit int
+
+	// catalog serves product lookups locally while it is in sync; nil
+	// disables it.
+	catalog *catalogReplica
+
+	health *health.Server
 }
 
 func frontendserverConstructor(productCatalogSvcAddr string, currencySvcAddr string, cartSvcAddr string, recommendationSvcAddr string, checkoutSvcAddr string, shippingSvcAddr string, adSvcAddr string) *frontendServer {
@@ -109,13 +111,6 @@ func frontendserverConstructor(productCatalogSvcAddr string, currencySvcAddr str
 
 func main() {
 
-    if serviceName=os.Getenv("SERVICE_NAME");serviceName==""{
-        serviceName = "Frontend-service"
-    }
-    if serviceNameSpace=os.Getenv("SERVICE_NAMESPACE");serviceNameSpace==""{
-            serviceNameSpace = "hipster"
-    }
-
 	log = logrus.New()
 	log.Formatter = &logrus.JSONFormatter{
 		FieldMap: logrus.FieldMap{
@@ -126,13 +121,24 @@ func main() {
 		TimestampFormat: time.RFC3339Nano,
 	}
 	log.Out = os.Stdout
-	initTracing()
+	log.AddHook(observability.LogHook{})
+	telemetryConfig, err := observability.ConfigFromEnv("Frontend-service")
+	if err != nil {
+		log.WithError(err).Fatal("invalid telemetry configuration")
+	}
+	serviceName = telemetryConfig.ServiceName
+	shutdownTracing := initTracing(telemetryConfig)
+	metricsHandler, shutdownMetrics := initMetrics(telemetryConfig)
+	shutdownTimeout, err := observability.ShutdownTimeoutFromEnv()
+	if err != nil {
+		log.Fatalf("failed to parse SHUTDOWN_TIMEOUT as time.Duration: %+v", err)
+	}
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	srvPort := port
 
-
 	addr := os.Getenv("LISTEN_ADDR")
 	var PRODUCT_CATALOG_SERVICE_ADDR = "productcatlog:4000"
 	var CURRENCY_SERVICE_ADDR = "currency:9000"
@@ -152,116 +158,91 @@ func main() {
 	mustConnGRPC(ctx, &svc.checkoutSvcConn, svc.checkoutSvcAddr)
 	mustConnGRPC(ctx, &svc.adSvcConn, svc.adSvcAddr)
 
+	svc.health = svc.newHealthServer()
+
+	if os.Getenv("DISABLE_CATALOG_REPLICA") == "" {
+		svc.catalog = new(catalogReplica)
+		go svc.catalog.run(ctx, pb.NewProductCatalogServiceClient(svc.productCatalogSvcConn))
+	} else {
+		log.Info("catalog replica disabled")
+	}
+
 	r := mux.NewRouter()
-	r.Use(MuxMiddleware(), otelmux.Middleware(serviceName))
+	r.Use(MuxMiddleware(), otelmux.Middleware(serviceName), bindLogToSpan)
 	r.HandleFunc("/", svc.homeHandler).Methods(http.MethodGet, http.MethodHead)
 	r.HandleFunc("/product/{id}", svc.productHandler).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/search", svc.searchHandler).Methods(http.MethodGet, http.MethodHead)
 	r.HandleFunc("/cart", svc.viewCartHandler).Methods(http.MethodGet, http.MethodHead)
 	r.HandleFunc("/cart", svc.addToCartHandler).Methods(http.MethodPost)
 	r.HandleFunc("/cart/empty", svc.emptyCartHandler).Methods(http.MethodPost)
 	r.HandleFunc("/setCurrency", svc.setCurrencyHandler).Methods(http.MethodPost)
 	r.HandleFunc("/logout", svc.logoutHandler).Methods(http.MethodGet)
 	r.HandleFunc("/cart/checkout", svc.placeOrderHandler).Methods(http.MethodPost)
+	r.HandleFunc("/orders", svc.ordersHandler).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/orders/{id}", svc.orderHandler).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/track/{id}", svc.trackHandler).Methods(http.MethodGet, http.MethodHead)
 	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("./static/"))))
 	r.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) { fmt.Fprint(w, "User-agent: *\nDisallow: /") })
-	r.HandleFunc("/_healthz", func(w http.ResponseWriter, _ *http.Request) { fmt.Fprint(w, "ok") })
+	r.HandleFunc("/_healthz", svc.healthzHandler).Methods(http.MethodGet, http.MethodHead)
+	r.Handle("/metrics", metricsHandler).Methods(http.MethodGet)
 
 	var handler http.Handler = r
 	handler = &logHandler{log: log, next: handler} // add logging
 	handler = ensureSessionID(handler)             // add session ID
+	srv := &http.Server{Addr: addr + ":" + srvPort, Handler: handler}
 	log.Infof("starting server on " + addr + ":" + srvPort)
-	log.Fatal(http.ListenAndServe(addr+":"+srvPort, handler))
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	// On SIGTERM, fail /_healthz so that the load balancer stops sending
+	// traffic, let in-flight requests finish, then flush their spans and
+	// metrics.
+	observability.WaitForShutdownSignal(log)
+	svc.health.Shutdown()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Warn("timed out draining requests")
+	}
+	cancel()
+	svc.closeConns()
+	observability.FlushTelemetry(log, shutdownTracing, shutdownMetrics)
+	log.Info("shutdown complete")
 }
 
-func detectResource() (*resource.Resource, error) {
-	var instID label.KeyValue
-	if host, ok := os.LookupEnv("HOSTNAME"); ok && host != "" {
-		instID = semconv.ServiceInstanceIDKey.String(host)
+// initTracing installs the global tracer provider described by cfg and
+// returns a function that flushes and stops it.
+func initTracing(cfg observability.Config) (shutdown func(context.Context) error) {
+	if cfg.Disabled {
+		log.Info("tracing disabled")
 	} else {
-		instID = semconv.ServiceInstanceIDKey.String(uuid.New().String())
+		log.WithField("exporter", cfg.Exporter).Info("tracing enabled")
 	}
-
-   hostName:=os.Getenv("MY_POD_NAME")
-   hostIp:=os.Getenv("MY_POD_IP")
-   resourceType:=os.Getenv("RESOURCE_TYPE")
-   return resource.New(
-      context.Background(),
-      resource.WithAttributes(
-         instID,
-         semconv.ServiceNameKey.String(serviceName),
-         semconv.HostNameKey.String(hostName),
-         label.String("service.namespace",serviceNameSpace),
-         label.String("ip",hostIp),
-         label.String("resource.type",resourceType),
-      ),
-   )
-}
-func spanExporter() (exporttrace.SpanExporter, error) {
-
-
-    var user = os.Getenv("JAEGER_USER")
-    var password = os.Getenv("JAEGER_PASSWORD")
-	export_type := os.Getenv("EXPORT_TYPE")
-	if export_type == "JAEGER" {
-		log.Info("exporting with JAEGER logger")
-		addr1 := os.Getenv("JAEGER_ENDPOINT")
-		return jaeger.NewRawExporter(
-			jaeger.WithCollectorEndpoint(addr1,jaeger.WithUsername(user),jaeger.WithPassword(password)),
-			jaeger.WithProcess(jaeger.Process{
-				ServiceName: serviceName,
-			}),
-		)
+	if cfg.Exporter == "" || cfg.Exporter == observability.ExporterStdout {
+		cfg.StdoutWriter = log.Writer()
 	}
-	if export_type == "OTLP" {
-		log.Info("exporting with OTLP logger")
-		if os.Getenv("OTLP_ENDPOINT") != "" {
-			addr := os.Getenv("OTLP_ENDPOINT")
-			return otlp.NewExporter(
-				context.Background(),
-				otlp.WithInsecure(),
-				otlp.WithAddress(addr),
-			)
-		}
+	shutdown, err := observability.Setup(context.Background(), cfg)
+	if err != nil {
+		log.WithError(err).Fatal("failed to initialize tracing")
 	}
-
-	log.Info("exporting with STDOUT logger")
-	return stdout.NewExporter(
-		stdout.WithPrettyPrint(),
-		stdout.WithWriter(log.Writer()),
-	)
+	return shutdown
 }
-func initTracing() {
-	if os.Getenv("DISABLE_TRACING") != "" {
-		log.Info("tracing disabled")
-		return
-	}
 
-	res, err := detectResource()
-	if err != nil {
-		log.WithError(err).Fatal("failed to detect environment resource")
+// initMetrics installs the global meter provider described by cfg. It
+// returns the handler serving the metrics to Prometheus and a function that
+// pushes the last values and stops the provider.
+func initMetrics(cfg observability.Config) (http.Handler, func(context.Context) error) {
+	if cfg.MetricsDisabled {
+		log.Info("metrics disabled")
 	}
-
-	exp, err := spanExporter()
+	handler, shutdown, err := observability.SetupMetrics(context.Background(), cfg)
 	if err != nil {
-		log.WithError(err).Fatal("failed to initialize Span exporter")
-		return
+		log.WithError(err).Fatal("failed to initialize metrics")
 	}
-
-	log.Info("tracing enabled")
-	otel.SetTracerProvider(
-		trace.NewTracerProvider(
-			trace.WithConfig(
-				trace.Config{
-					DefaultSampler: trace.AlwaysSample(),
-					Resource:       res,
-				},
-			),
-			trace.WithSpanProcessor(
-				trace.NewBatchSpanProcessor(exp),
-			),
-		),
-	)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return handler, shutdown
 }
 
 type errorHandler struct {
@@ -272,14 +253,49 @@ func (eh errorHandler) Handle(err error) {
 	eh.log.Error(err)
 }
 
+// closeConns closes the connections to every downstream service.
+func (fe *frontendServer) closeConns() {
+	for _, conn := range []*grpc.ClientConn{
+		fe.productCatalogSvcConn,
+		fe.currencySvcConn,
+		fe.cartSvcConn,
+		fe.recommendationSvcConn,
+		fe.checkoutSvcConn,
+		fe.shippingSvcConn,
+		fe.adSvcConn,
+	} {
+		if err := conn.Close(); err != nil {
+			log.WithError(err).WithField("addr", conn.Target()).Warn("failed to close downstream connection")
+		}
+	}
+}
+
 func mustConnGRPC(ctx context.Context, conn **grpc.ClientConn, addr string) {
 	var err error
 
 	*conn, err = grpc.Dial(addr, grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
+		grpc.WithChainUnaryInterceptor(otelgrpc.UnaryClientInterceptor(), observability.UnaryClientLogInterceptor()),
+		grpc.WithChainStreamInterceptor(otelgrpc.StreamClientInterceptor(), observability.StreamClientLogInterceptor()),
 	)
 	if err != nil {
 		panic(errors.Wrapf(err, "grpc: failed to connect %s", addr))
 	}
 }
+
+// newHealthServer returns the health checks of the frontend, which is healthy
+// while none of its downstream connections is failing.
+func (fe *frontendServer) newHealthServer() *health.Server {
+	hs := health.NewServer()
+	for name, conn := range map[string]*grpc.ClientConn{
+		"productcatalogservice": fe.productCatalogSvcConn,
+		"currencyservice":       fe.currencySvcConn,
+		"cartservice":           fe.cartSvcConn,
+		"recommendationservice": fe.recommendationSvcConn,
+		"checkoutservice":       fe.checkoutSvcConn,
+		"shippingservice":       fe.shippingSvcConn,
+		"adservice":             fe.adSvcConn,
+	} {
+		hs.Register(name, health.ConnChecker(conn))
+	}
+	return hs
+}
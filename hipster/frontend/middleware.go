@@ -37,6 +37,8 @@ import (
 	"go.opentelemetry.io/otel/unit"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
 )
 
 const (
@@ -84,6 +86,7 @@ func init() {
 
 type ctxKeyLog struct{}
 type ctxKeyRequestID struct{}
+type ctxKeyRequestLog struct{}
 
 type logHandler struct {
 	log  *logrus.Logger
@@ -117,29 +120,48 @@ func (lh *logHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	requestID, _ := uuid.NewRandom()
 	ctx = context.WithValue(ctx, ctxKeyRequestID{}, requestID.String())
+	ctx = observability.ContextWithRequestID(ctx, requestID.String())
 
+	// The session, the request ID and, once bindLogToSpan has run, the
+	// trace are added by observability.LogHook.
 	start := time.Now()
-	log := lh.log.WithFields(logrus.Fields{
+	rl := &requestLog{entry: lh.log.WithContext(ctx).WithFields(logrus.Fields{
 		"http.req.path":   r.URL.Path,
 		"http.req.method": r.Method,
-		"http.req.id":     requestID.String(),
-	})
-	if v, ok := r.Context().Value(ctxKeySessionID{}).(string); ok {
-		log = log.WithField("session", v)
-	}
-	log.Debug("request started")
+	})}
+	rl.entry.Debug("request started")
 	defer func() {
-		log.WithFields(logrus.Fields{
+		rl.entry.WithFields(logrus.Fields{
 			"http.resp.took_ms": int64(time.Since(start) / time.Millisecond),
 			"http.resp.status":  rr.status,
 			"http.resp.bytes":   rr.b}).Debugf("request complete")
 	}()
 
-	ctx = context.WithValue(ctx, ctxKeyLog{}, log)
+	ctx = context.WithValue(ctx, ctxKeyLog{}, rl.entry)
+	ctx = context.WithValue(ctx, ctxKeyRequestLog{}, rl)
 	r = r.WithContext(ctx)
 	lh.next.ServeHTTP(rr, r)
 }
 
+// requestLog holds the logger of a request. The logger is created before the
+// request is routed, so bindLogToSpan replaces it with one bound to the span
+// started by the router, for the handler and the final line of logHandler.
+type requestLog struct {
+	entry *logrus.Entry
+}
+
+// bindLogToSpan binds the request's logger to the request's context, so that
+// its lines carry the trace. It must run after otelmux.Middleware.
+func bindLogToSpan(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rl, ok := r.Context().Value(ctxKeyRequestLog{}).(*requestLog); ok {
+			rl.entry = rl.entry.WithContext(r.Context())
+			r = r.WithContext(context.WithValue(r.Context(), ctxKeyLog{}, rl.entry))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func ensureSessionID(next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var sessionID string
@@ -158,6 +180,7 @@ func ensureSessionID(next http.Handler) http.HandlerFunc {
 			sessionID = c.Value
 		}
 		ctx := context.WithValue(r.Context(), ctxKeySessionID{}, sessionID)
+		ctx = observability.ContextWithSessionID(ctx, sessionID)
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	}
@@ -0,0 +1,78 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+)
+
+func TestRequestLogCorrelation(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &out
+	logger.Level = logrus.DebugLevel
+	logger.Formatter = &logrus.JSONFormatter{}
+	logger.AddHook(observability.LogHook{})
+
+	r := mux.NewRouter()
+	r.Use(otelmux.Middleware("frontend", otelmux.WithTracerProvider(sdktrace.NewTracerProvider())), bindLogToSpan)
+	r.HandleFunc("/cart", func(w http.ResponseWriter, r *http.Request) {
+		r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger).Info("viewing cart")
+	})
+	h := ensureSessionID(&logHandler{log: logger, next: r})
+
+	req := httptest.NewRequest(http.MethodGet, "/cart", nil)
+	req.AddCookie(&http.Cookie{Name: cookieSessionID, Value: "session-1"})
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var lines []map[string]interface{}
+	for _, b := range bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(b, &fields); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, fields)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("logged %d lines, want 3:\n%s", len(lines), out.String())
+	}
+	requestID := lines[0]["request_id"]
+	if requestID == nil {
+		t.Fatalf("request started line has no request_id: %v", lines[0])
+	}
+	traceID := lines[1]["trace_id"]
+	if traceID == nil {
+		t.Fatalf("handler line has no trace_id: %v", lines[1])
+	}
+	for _, l := range lines {
+		if l["session"] != "session-1" || l["request_id"] != requestID {
+			t.Errorf("line %q: session %v, request_id %v; want session-1, %v", l["msg"], l["session"], l["request_id"], requestID)
+		}
+	}
+	if got := lines[2]["trace_id"]; got != traceID {
+		t.Errorf("request complete line has trace_id %v, want %v", got, traceID)
+	}
+}
@@ -220,8 +220,11 @@ func TestSum(t *testing.T) {
 		{"both positive (no carry)", args{mm(2, 200000000), mm(2, 200000000)}, mm(4, 400000000), nil},
 		{"both positive (nanos=max)", args{mm(2, 111111111), mm(2, 888888888)}, mm(4, 999999999), nil},
 		{"both positive (carry)", args{mm(2, 200000000), mm(2, 900000000)}, mm(5, 100000000), nil},
+		{"both positive (just nanos)", args{mm(0, 250000000), mm(0, 250000000)}, mm(0, 500000000), nil},
+		{"both positive (just nanos, carry)", args{mm(0, 750000000), mm(0, 500000000)}, mm(1, 250000000), nil},
 		{"both negative (no carry)", args{mm(-2, -200000000), mm(-2, -200000000)}, mm(-4, -400000000), nil},
 		{"both negative (carry)", args{mm(-2, -200000000), mm(-2, -900000000)}, mm(-5, -100000000), nil},
+		{"both negative (just nanos)", args{mm(0, -250000000), mm(0, -250000000)}, mm(0, -500000000), nil},
 		{"mixed (larger positive, just decimals)", args{mm(11, 0), mm(-2, 0)}, mm(9, 0), nil},
 		{"mixed (larger negative, just decimals)", args{mm(-11, 0), mm(2, 0)}, mm(-9, 0), nil},
 		{"mixed (larger positive, no borrow)", args{mm(11, 100000000), mm(-2, -100000000)}, mm(9, 0), nil},
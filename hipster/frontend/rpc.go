@@ -20,6 +20,8 @@ import (
 
 	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -42,17 +44,68 @@ func (fe *frontendServer) getCurrencies(ctx context.Context) ([]string, error) {
 }
 
 func (fe *frontendServer) getProducts(ctx context.Context) ([]*pb.Product, error) {
+	if products, order, ok := fe.catalog.snapshot(); ok {
+		out := make([]*pb.Product, len(order))
+		for i, id := range order {
+			out[i] = products[id]
+		}
+		return out, nil
+	}
 	resp, err := pb.NewProductCatalogServiceClient(fe.productCatalogSvcConn).
 		ListProducts(ctx, &pb.Empty{})
 	return resp.GetProducts(), err
 }
 
 func (fe *frontendServer) getProduct(ctx context.Context, id string) (*pb.Product, error) {
+	if products, _, ok := fe.catalog.snapshot(); ok {
+		if p, found := products[id]; found {
+			return p, nil
+		}
+		return nil, status.Errorf(codes.NotFound, "no product with ID %s", id)
+	}
 	resp, err := pb.NewProductCatalogServiceClient(fe.productCatalogSvcConn).
 		GetProduct(ctx, &pb.GetProductRequest{Id: id})
 	return resp, err
 }
 
+// getProductsByID resolves ids in a single round trip, or from the catalog
+// replica when it is in sync, and returns the products keyed by ID. An ID
+// missing from the catalog is an error.
+func (fe *frontendServer) getProductsByID(ctx context.Context, ids []string) (map[string]*pb.Product, error) {
+	if products, _, ok := fe.catalog.snapshot(); ok {
+		out := make(map[string]*pb.Product, len(ids))
+		for _, id := range ids {
+			p, found := products[id]
+			if !found {
+				return nil, errors.Errorf("no product with ID %s", id)
+			}
+			out[id] = p
+		}
+		return out, nil
+	}
+	resp, err := pb.NewProductCatalogServiceClient(fe.productCatalogSvcConn).
+		GetProducts(ctx, &pb.GetProductsRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	if missing := resp.GetMissingIds(); len(missing) > 0 {
+		return nil, errors.Errorf("no product with ID %s", missing[0])
+	}
+	out := make(map[string]*pb.Product, len(resp.GetProducts()))
+	for _, p := range resp.GetProducts() {
+		out[p.GetId()] = p
+	}
+	return out, nil
+}
+
+func (fe *frontendServer) searchProducts(ctx context.Context, query string, categories []string, pageToken string) (*pb.SearchProductsResponse, error) {
+	return pb.NewProductCatalogServiceClient(fe.productCatalogSvcConn).
+		SearchProducts(ctx, &pb.SearchProductsRequest{
+			Query:      query,
+			Categories: categories,
+			PageToken:  pageToken})
+}
+
 func (fe *frontendServer) getCart(ctx context.Context, userID string) ([]*pb.CartItem, error) {
 	resp, err := pb.NewCartServiceClient(fe.cartSvcConn).GetCart(ctx, &pb.GetCartRequest{UserId: userID})
 	return resp.GetItems(), err
@@ -83,37 +136,75 @@ func (fe *frontendServer) convertCurrency(ctx context.Context, money *pb.Money,
 			ToCode: currency})
 }
 
-func (fe *frontendServer) getShippingQuote(ctx context.Context, items []*pb.CartItem, currency string) (*pb.Money, error) {
+// shippingOption is a shipping service level priced in the user's currency.
+type shippingOption struct {
+	ServiceLevel string
+	Name         string
+	Cost         *pb.Money
+}
+
+// getShippingOptions quotes items at every shipping service level, cheapest
+// first. The cart has no address yet, so the quotes are estimates for the
+// shipping service's default zone.
+func (fe *frontendServer) getShippingOptions(ctx context.Context, items []*pb.CartItem, subtotalUSD *pb.Money, currency string) ([]shippingOption, error) {
 	quote, err := pb.NewShippingServiceClient(fe.shippingSvcConn).GetQuote(ctx,
 		&pb.GetQuoteRequest{
-			Address: nil,
-			Items:   items})
+			Address:     nil,
+			Items:       items,
+			SubtotalUsd: subtotalUSD})
 	if err != nil {
 		return nil, err
 	}
-	localized, err := fe.convertCurrency(ctx, quote.GetCostUsd(), currency)
-	return localized, errors.Wrap(err, "failed to convert currency for shipping cost")
+	options := make([]shippingOption, len(quote.GetOptions()))
+	err = fanOut(ctx, len(options), fe.fanOutLimit, func(ctx context.Context, i int) error {
+		o := quote.GetOptions()[i]
+		localized, err := fe.convertCurrency(ctx, o.GetCostUsd(), currency)
+		if err != nil {
+			return errors.Wrap(err, "failed to convert currency for shipping cost")
+		}
+		options[i] = shippingOption{ServiceLevel: o.GetServiceLevel(), Name: o.GetName(), Cost: localized}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
 }
 
-
 func (fe *frontendServer) getRecommendations(ctx context.Context, userID string, productIDs []string) ([]*pb.Product, error) {
 	resp, err := pb.NewRecommendationServiceClient(fe.recommendationSvcConn).ListRecommendations(ctx,
 		&pb.ListRecommendationsRequest{UserId: userID, ProductIds: productIDs})
 	if err != nil {
 		return nil, err
 	}
-	out := make([]*pb.Product, len(resp.GetProductIds()))
-	for i, v := range resp.GetProductIds() {
-		p, err := fe.getProduct(ctx, v)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get recommended product info (#%s)", v)
-		}
-		out[i] = p
+	ids := resp.GetProductIds()
+	if len(ids) > 4 {
+		ids = ids[:4] // take only first four to fit the UI
 	}
-	if len(out) > 4 {
-		out = out[:4] // take only first four to fit the UI
+	products, err := fe.getProductsByID(ctx, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get recommended product info")
+	}
+	out := make([]*pb.Product, len(ids))
+	for i, id := range ids {
+		out[i] = products[id]
 	}
-	return out, err
+	return out, nil
+}
+
+func (fe *frontendServer) getOrder(ctx context.Context, userID, orderID string) (*pb.OrderResult, error) {
+	return pb.NewCheckoutServiceClient(fe.checkoutSvcConn).
+		GetOrder(ctx, &pb.GetOrderRequest{OrderId: orderID, UserId: userID})
+}
+
+func (fe *frontendServer) listOrders(ctx context.Context, userID, pageToken string) (*pb.ListOrdersResponse, error) {
+	return pb.NewCheckoutServiceClient(fe.checkoutSvcConn).
+		ListOrders(ctx, &pb.ListOrdersRequest{UserId: userID, PageToken: pageToken})
+}
+
+func (fe *frontendServer) getShipmentStatus(ctx context.Context, trackingID string) (*pb.ShipmentStatus, error) {
+	return pb.NewShippingServiceClient(fe.shippingSvcConn).
+		GetShipmentStatus(ctx, &pb.GetShipmentStatusRequest{TrackingId: trackingID})
 }
 
 func (fe *frontendServer) getAd(ctx context.Context, ctxKeys []string) ([]*pb.Ad, error) {
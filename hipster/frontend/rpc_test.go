@@ -0,0 +1,91 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+)
+
+type fakeShipping struct {
+	pb.ShippingServiceServer
+	requests chan *pb.GetQuoteRequest
+}
+
+func (s fakeShipping) GetQuote(ctx context.Context, req *pb.GetQuoteRequest) (*pb.GetQuoteResponse, error) {
+	s.requests <- req
+	return &pb.GetQuoteResponse{
+		CostUsd: &pb.Money{CurrencyCode: "USD", Units: 5},
+		Options: []*pb.ShippingOption{
+			{ServiceLevel: "standard", Name: "Standard (5-7 days)", CostUsd: &pb.Money{CurrencyCode: "USD", Units: 5}},
+			{ServiceLevel: "express", Name: "Express (2-3 days)", CostUsd: &pb.Money{CurrencyCode: "USD", Units: 15}},
+		},
+	}, nil
+}
+
+func (s fakeShipping) GetShipmentStatus(ctx context.Context, req *pb.GetShipmentStatusRequest) (*pb.ShipmentStatus, error) {
+	if req.GetTrackingId() != "AB-1" {
+		return nil, status.Errorf(codes.NotFound, "no shipment with tracking ID %s", req.GetTrackingId())
+	}
+	return &pb.ShipmentStatus{
+		TrackingId:   "AB-1",
+		State:        pb.ShipmentStatus_IN_TRANSIT,
+		ServiceLevel: "express",
+		Events: []*pb.ShipmentEvent{
+			{State: pb.ShipmentStatus_LABEL_CREATED, Time: 1577836800},
+			{State: pb.ShipmentStatus_IN_TRANSIT, Time: 1577858400},
+		},
+		EstimatedDelivery: 1578074400,
+	}, nil
+}
+
+func TestGetShippingOptions(t *testing.T) {
+	shipping := fakeShipping{requests: make(chan *pb.GetQuoteRequest, 1)}
+	fe := &frontendServer{
+		shippingSvcConn: dialFake(t, func(s *grpc.Server) { pb.RegisterShippingServiceServer(s, shipping) }),
+		currencySvcConn: dialFake(t, func(s *grpc.Server) { pb.RegisterCurrencyServiceServer(s, fakeCurrency{}) }),
+	}
+	items := []*pb.CartItem{{ProductId: "P0", Quantity: 2}}
+	subtotal := &pb.Money{CurrencyCode: "USD", Units: 20}
+
+	options, err := fe.getShippingOptions(context.Background(), items, subtotal, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := <-shipping.requests
+	if req.GetSubtotalUsd().GetUnits() != 20 || len(req.GetItems()) != 1 || req.GetAddress() != nil {
+		t.Errorf("GetQuote request = %v", req)
+	}
+
+	want := []shippingOption{
+		{ServiceLevel: "standard", Name: "Standard (5-7 days)", Cost: &pb.Money{CurrencyCode: "EUR", Units: 10}},
+		{ServiceLevel: "express", Name: "Express (2-3 days)", Cost: &pb.Money{CurrencyCode: "EUR", Units: 30}},
+	}
+	if len(options) != len(want) {
+		t.Fatalf("got %d options, want %d", len(options), len(want))
+	}
+	for i, o := range options {
+		w := want[i]
+		if o.ServiceLevel != w.ServiceLevel || o.Name != w.Name || renderMoney(*o.Cost) != renderMoney(*w.Cost) {
+			t.Errorf("option %d = %+v, want %+v", i, o, w)
+		}
+	}
+}
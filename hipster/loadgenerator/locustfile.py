@@ -54,7 +54,7 @@ def checkout(l):
     l.client.post("/cart/checkout", {
         'email': 'someone@example.com',
         'street_address': '1600 Amphitheatre Parkway',
-        'zip_code': '94043',
+        'postal_code': '94043',
         'city': 'Mountain View',
         'state': 'CA',
         'country': 'United States',
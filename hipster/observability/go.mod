@@ -0,0 +1,16 @@
+module github.com/GoogleCloudPlatform/microservices-demo/src/observability
+
+go 1.15
+
+require (
+	github.com/google/uuid v1.1.2
+	github.com/sirupsen/logrus v1.4.2
+	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.15.1
+	go.opentelemetry.io/otel v0.15.0
+	go.opentelemetry.io/otel/exporters/metric/prometheus v0.15.0
+	go.opentelemetry.io/otel/exporters/otlp v0.15.0
+	go.opentelemetry.io/otel/exporters/stdout v0.15.0
+	go.opentelemetry.io/otel/exporters/trace/jaeger v0.15.0
+	go.opentelemetry.io/otel/sdk v0.15.0
+	google.golang.org/grpc v1.34.0
+)
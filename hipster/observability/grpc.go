@@ -0,0 +1,95 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/unit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const instrumentationName = "github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+
+// serverMetrics are the instruments recorded for every RPC a server handles.
+type serverMetrics struct {
+	duration metric.Float64ValueRecorder
+	calls    metric.Int64Counter
+}
+
+func newServerMetrics() serverMetrics {
+	meter := metric.Must(otel.Meter(instrumentationName))
+	return serverMetrics{
+		duration: meter.NewFloat64ValueRecorder("grpc.server.duration",
+			metric.WithDescription("duration of the inbound gRPC request"),
+			metric.WithUnit(unit.Milliseconds)),
+		calls: meter.NewInt64Counter("grpc.server.calls",
+			metric.WithDescription("number of inbound gRPC requests by status code")),
+	}
+}
+
+func (m serverMetrics) record(ctx context.Context, fullMethod string, start time.Time, err error) {
+	labels := []label.KeyValue{semconv.RPCSystemGRPC}
+	service, method := splitFullMethod(fullMethod)
+	labels = append(labels,
+		semconv.RPCServiceKey.String(service),
+		semconv.RPCMethodKey.String(method),
+		label.String("rpc.grpc.status_code", status.Code(err).String()),
+	)
+	m.duration.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), labels...)
+	m.calls.Add(ctx, 1, labels...)
+}
+
+// splitFullMethod splits "/package.Service/Method" into its service and
+// method.
+func splitFullMethod(fullMethod string) (service, method string) {
+	name := strings.TrimLeft(fullMethod, "/")
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// UnaryServerMetricsInterceptor records the duration and status code of every
+// unary RPC as the grpc.server.duration and grpc.server.calls metrics. It
+// must be created after SetupMetrics.
+func UnaryServerMetricsInterceptor() grpc.UnaryServerInterceptor {
+	m := newServerMetrics()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.record(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerMetricsInterceptor is the streaming counterpart of
+// UnaryServerMetricsInterceptor; the duration is that of the whole stream.
+func StreamServerMetricsInterceptor() grpc.StreamServerInterceptor {
+	m := newServerMetrics()
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.record(ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
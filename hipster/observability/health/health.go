@@ -0,0 +1,229 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health implements the gRPC health service on top of component
+// checkers registered by the service.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultTimeout bounds a single run of a checker.
+	DefaultTimeout = 2 * time.Second
+	// DefaultWatchInterval is how often Watch re-runs the checkers.
+	DefaultWatchInterval = 5 * time.Second
+)
+
+// A Checker reports whether one component of the service is healthy. It
+// returns nil when it is.
+type Checker func(ctx context.Context) error
+
+type component struct {
+	name     string
+	check    Checker
+	services map[string]bool // nil means every service
+}
+
+// Server implements grpc_health_v1.HealthServer. A service name is SERVING
+// when every checker registered for it passes; the empty name stands for the
+// whole process and covers every checker. Names that were not declared are
+// unknown.
+type Server struct {
+	// Timeout bounds each checker run.
+	Timeout time.Duration
+	// WatchInterval is how often Watch re-runs the checkers to find out
+	// whether the status changed.
+	WatchInterval time.Duration
+
+	mu         sync.RWMutex
+	services   map[string]bool
+	components []component
+	shutdown   bool
+	shutdownCh chan struct{}
+}
+
+// NewServer returns a server that knows about services, in addition to the
+// empty service name.
+func NewServer(services ...string) *Server {
+	s := &Server{
+		Timeout:       DefaultTimeout,
+		WatchInterval: DefaultWatchInterval,
+		services:      map[string]bool{"": true},
+		shutdownCh:    make(chan struct{}),
+	}
+	for _, name := range services {
+		s.services[name] = true
+	}
+	return s
+}
+
+// Register adds a checker for the component called name to services, or to
+// every service if none are given.
+func (s *Server) Register(name string, check Checker, services ...string) {
+	c := component{name: name, check: check}
+	if len(services) > 0 {
+		c.services = make(map[string]bool, len(services))
+		for _, name := range services {
+			c.services[name] = true
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.components = append(s.components, c)
+}
+
+// Shutdown reports every service as NOT_SERVING from now on, whatever its
+// checkers say, so that clients stop sending new requests while the process
+// drains. Watchers are notified immediately.
+func (s *Server) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.shutdown {
+		s.shutdown = true
+		close(s.shutdownCh)
+	}
+}
+
+// ComponentReport is the result of a single checker.
+type ComponentReport struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the health of a service broken down by component.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+// Evaluate runs the checkers of service and returns their results. ok is
+// false if service is unknown.
+func (s *Server) Evaluate(ctx context.Context, service string) (r Report, ok bool) {
+	s.mu.RLock()
+	known, shutdown := s.services[service], s.shutdown
+	var components []component
+	for _, c := range s.components {
+		if service == "" || c.services == nil || c.services[service] {
+			components = append(components, c)
+		}
+	}
+	s.mu.RUnlock()
+	if !known {
+		return Report{Status: healthpb.HealthCheckResponse_SERVICE_UNKNOWN.String()}, false
+	}
+
+	serving := !shutdown
+	r.Components = make(map[string]ComponentReport, len(components))
+	for _, c := range components {
+		err := s.run(ctx, c.check)
+		if err != nil {
+			serving = false
+			r.Components[c.name] = ComponentReport{Status: healthpb.HealthCheckResponse_NOT_SERVING.String(), Error: err.Error()}
+		} else {
+			r.Components[c.name] = ComponentReport{Status: healthpb.HealthCheckResponse_SERVING.String()}
+		}
+	}
+	if shutdown {
+		r.Components["shutdown"] = ComponentReport{Status: healthpb.HealthCheckResponse_NOT_SERVING.String(), Error: "shutting down"}
+	}
+	r.Status = servingStatus(serving).String()
+	return r, true
+}
+
+func (s *Server) run(ctx context.Context, check Checker) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	return check(ctx)
+}
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+func (s *Server) status(ctx context.Context, service string) healthpb.HealthCheckResponse_ServingStatus {
+	r, ok := s.Evaluate(ctx, service)
+	if !ok {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+	return healthpb.HealthCheckResponse_ServingStatus(healthpb.HealthCheckResponse_ServingStatus_value[r.Status])
+}
+
+// Check returns the aggregated status of the requested service, or NotFound
+// if the service is unknown.
+func (s *Server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st := s.status(ctx, req.GetService())
+	if st == healthpb.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch sends the status of the requested service right away and again
+// whenever it changes, until the client goes away. Unknown services are
+// reported as SERVICE_UNKNOWN. Once the server shuts down, Watch sends the
+// final NOT_SERVING and ends the stream, so that watchers do not hold up a
+// graceful stop.
+func (s *Server) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	t := time.NewTicker(s.WatchInterval)
+	defer t.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	stopping := false
+	for {
+		if st := s.status(ctx, req.GetService()); st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+		if stopping {
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.shutdownCh:
+			stopping = true
+		case <-t.C:
+		}
+	}
+}
+
+// ConnChecker reports conn as unhealthy while it is failing to connect or has
+// been closed. Idle and connecting connections are healthy, since gRPC only
+// connects once there is an RPC to send.
+func ConnChecker(conn *grpc.ClientConn) Checker {
+	return func(context.Context) error {
+		switch st := conn.GetState(); st {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("connection to %s is %s", conn.Target(), st)
+		}
+		return nil
+	}
+}
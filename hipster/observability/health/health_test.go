@@ -0,0 +1,177 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// toggle is a checker whose result can be flipped by the test.
+type toggle struct{ failing int32 }
+
+func (c *toggle) set(failing bool) {
+	var v int32
+	if failing {
+		v = 1
+	}
+	atomic.StoreInt32(&c.failing, v)
+}
+
+func (c *toggle) check(context.Context) error {
+	if atomic.LoadInt32(&c.failing) == 1 {
+		return errors.New("down")
+	}
+	return nil
+}
+
+func check(t *testing.T, s *Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q): %v", service, err)
+	}
+	return resp.GetStatus()
+}
+
+func TestCheck(t *testing.T) {
+	s := NewServer("a", "b")
+	var db, cache toggle
+	s.Register("db", db.check)
+	s.Register("cache", cache.check, "b")
+
+	cache.set(true)
+	for service, want := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":  healthpb.HealthCheckResponse_NOT_SERVING,
+		"a": healthpb.HealthCheckResponse_SERVING,
+		"b": healthpb.HealthCheckResponse_NOT_SERVING,
+	} {
+		if got := check(t, s, service); got != want {
+			t.Errorf("Check(%q) = %s, want %s", service, got, want)
+		}
+	}
+
+	r, _ := s.Evaluate(context.Background(), "")
+	if got := r.Components["cache"]; got.Status != "NOT_SERVING" || got.Error != "down" {
+		t.Errorf("cache component = %+v", got)
+	}
+	if got := r.Components["db"]; got.Status != "SERVING" {
+		t.Errorf("db component = %+v", got)
+	}
+
+	_, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "c"})
+	if got, want := status.Code(err), codes.NotFound; got != want {
+		t.Errorf("Check of an unknown service: got %s, want %s", got, want)
+	}
+
+	cache.set(false)
+	s.Shutdown()
+	if got := check(t, s, "a"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Check after Shutdown = %s, want NOT_SERVING", got)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	s := NewServer()
+	s.Timeout = 10 * time.Millisecond
+	s.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if got := check(t, s, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Check with a hung checker = %s, want NOT_SERVING", got)
+	}
+}
+
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (f *fakeWatchStream) Context() context.Context { return f.ctx }
+
+func (f *fakeWatchStream) Send(resp *healthpb.HealthCheckResponse) error {
+	f.sent <- resp.GetStatus()
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	s := NewServer("a")
+	s.WatchInterval = 5 * time.Millisecond
+	var db toggle
+	s.Register("db", db.check)
+
+	stream := &fakeWatchStream{ctx: context.Background(), sent: make(chan healthpb.HealthCheckResponse_ServingStatus, 10)}
+	done := make(chan error)
+	go func() { done <- s.Watch(&healthpb.HealthCheckRequest{Service: "a"}, stream) }()
+
+	next := func() healthpb.HealthCheckResponse_ServingStatus {
+		select {
+		case st := <-stream.sent:
+			return st
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for Watch")
+			return 0
+		}
+	}
+	if got := next(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("initial status = %s, want SERVING", got)
+	}
+	db.set(true)
+	if got := next(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status after failure = %s, want NOT_SERVING", got)
+	}
+	db.set(false)
+	if got := next(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status after recovery = %s, want SERVING", got)
+	}
+	// Unchanged statuses are not sent again.
+	select {
+	case st := <-stream.sent:
+		t.Errorf("Watch sent %s without a change", st)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	s.Shutdown()
+	if got := next(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status after Shutdown = %s, want NOT_SERVING", got)
+	}
+	if err := <-done; status.Code(err) != codes.Unavailable {
+		t.Errorf("Watch returned %v after Shutdown, want Unavailable", err)
+	}
+}
+
+func TestWatchEndsWithClient(t *testing.T) {
+	s := NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeWatchStream{ctx: ctx, sent: make(chan healthpb.HealthCheckResponse_ServingStatus, 10)}
+	done := make(chan error)
+	go func() { done <- s.Watch(&healthpb.HealthCheckRequest{}, stream) }()
+
+	<-stream.sent
+	cancel()
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Errorf("Watch returned %v after the client went away, want Canceled", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys the session and request IDs travel under between services.
+const (
+	SessionIDMetadataKey = "x-session-id"
+	RequestIDMetadataKey = "x-request-id"
+)
+
+type ctxKeySessionID struct{}
+type ctxKeyRequestID struct{}
+type ctxKeyLogger struct{}
+
+// ContextWithSessionID returns a copy of ctx carrying the ID of the user's
+// session, which LogHook adds to log lines and the client interceptors pass
+// on to other services.
+func ContextWithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKeySessionID{}, id)
+}
+
+// ContextWithRequestID is ContextWithSessionID for the ID of the request
+// that started the work.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestID{}, id)
+}
+
+// SessionID returns the session ID carried by ctx, if any.
+func SessionID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKeySessionID{}).(string)
+	return id
+}
+
+// RequestID returns the request ID carried by ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKeyRequestID{}).(string)
+	return id
+}
+
+// LogHook adds the trace_id and span_id of the active span and the session
+// and request_id of the context an entry is logged with, as in
+// log.WithContext(ctx).Info(...). Fields already set are left alone.
+type LogHook struct{}
+
+// Levels implements logrus.Hook.
+func (LogHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+// Fire implements logrus.Hook.
+func (LogHook) Fire(e *logrus.Entry) error {
+	if e.Context == nil {
+		return nil
+	}
+	set := func(key, value string) {
+		if _, ok := e.Data[key]; !ok && value != "" {
+			e.Data[key] = value
+		}
+	}
+	if sc := trace.SpanContextFromContext(e.Context); sc.IsValid() {
+		set("trace_id", sc.TraceID.String())
+		set("span_id", sc.SpanID.String())
+	}
+	set("session", SessionID(e.Context))
+	set("request_id", RequestID(e.Context))
+	return nil
+}
+
+// ContextWithLogger returns a copy of ctx carrying log.
+func ContextWithLogger(ctx context.Context, log *logrus.Entry) context.Context {
+	return context.WithValue(ctx, ctxKeyLogger{}, log)
+}
+
+// LoggerFromContext returns the logger put in ctx by the server log
+// interceptors, or fallback bound to ctx if there is none.
+func LoggerFromContext(ctx context.Context, fallback *logrus.Logger) *logrus.Entry {
+	if log, ok := ctx.Value(ctxKeyLogger{}).(*logrus.Entry); ok {
+		return log
+	}
+	return fallback.WithContext(ctx)
+}
+
+// serverLogContext returns ctx with the session and request IDs sent by the
+// client and a logger for the RPC.
+func serverLogContext(ctx context.Context, log *logrus.Logger, fullMethod string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(SessionIDMetadataKey); len(v) > 0 {
+		ctx = ContextWithSessionID(ctx, v[0])
+	}
+	if v := md.Get(RequestIDMetadataKey); len(v) > 0 {
+		ctx = ContextWithRequestID(ctx, v[0])
+	}
+	return ContextWithLogger(ctx, log.WithContext(ctx).WithField("rpc.method", fullMethod))
+}
+
+// UnaryServerLogInterceptor puts a logger for each RPC into its context,
+// for LoggerFromContext. The lines it logs carry the RPC's method and,
+// through LogHook, its trace and the session and request IDs the client
+// sent. It must come after the tracing interceptor so that the span has
+// started.
+func UnaryServerLogInterceptor(log *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(serverLogContext(ctx, log, info.FullMethod), req)
+	}
+}
+
+// StreamServerLogInterceptor is the streaming counterpart of
+// UnaryServerLogInterceptor.
+func StreamServerLogInterceptor(log *logrus.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, serverStream{ss, serverLogContext(ss.Context(), log, info.FullMethod)})
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context { return s.ctx }
+
+// outgoingIDs adds the session and request IDs of ctx to its outgoing
+// metadata.
+func outgoingIDs(ctx context.Context) context.Context {
+	if id := SessionID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, SessionIDMetadataKey, id)
+	}
+	if id := RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, id)
+	}
+	return ctx
+}
+
+// UnaryClientLogInterceptor passes the session and request IDs of the
+// caller's context on to the server, whose log interceptor picks them up.
+func UnaryClientLogInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingIDs(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientLogInterceptor is the streaming counterpart of
+// UnaryClientLogInterceptor.
+func StreamClientLogInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingIDs(ctx), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// newTestLogger returns a logger with LogHook whose JSON lines go to out.
+func newTestLogger(out *bytes.Buffer) *logrus.Logger {
+	log := logrus.New()
+	log.Out = out
+	log.Formatter = &logrus.JSONFormatter{}
+	log.AddHook(LogHook{})
+	return log
+}
+
+func lastLine(t *testing.T, out *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	var fields map[string]interface{}
+	if err := json.Unmarshal(lines[len(lines)-1], &fields); err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+func TestLogHook(t *testing.T) {
+	var out bytes.Buffer
+	log := newTestLogger(&out)
+
+	ctx := ContextWithRequestID(ContextWithSessionID(context.Background(), "session-1"), "request-1")
+	ctx, span := trace.NewTracerProvider().Tracer("test").Start(ctx, "op")
+	defer span.End()
+	log.WithContext(ctx).Info("hello")
+
+	fields := lastLine(t, &out)
+	sc := span.SpanContext()
+	for k, want := range map[string]string{
+		"trace_id":   sc.TraceID.String(),
+		"span_id":    sc.SpanID.String(),
+		"session":    "session-1",
+		"request_id": "request-1",
+	} {
+		if got := fields[k]; got != want {
+			t.Errorf("field %s = %v, want %q", k, got, want)
+		}
+	}
+
+	log.Info("no context")
+	for _, k := range []string{"trace_id", "span_id", "session", "request_id"} {
+		if v, ok := lastLine(t, &out)[k]; ok {
+			t.Errorf("line logged without a context has %s = %v", k, v)
+		}
+	}
+}
+
+func TestLogInterceptors(t *testing.T) {
+	var out bytes.Buffer
+	log := newTestLogger(&out)
+
+	// The client sends the IDs of its context as metadata...
+	var sent metadata.MD
+	client := UnaryClientLogInterceptor()
+	ctx := ContextWithRequestID(ContextWithSessionID(context.Background(), "session-1"), "request-1")
+	err := client(ctx, "/hipstershop.CartService/GetCart", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			sent, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ...which the server puts into the context of the RPC and its logger.
+	server := UnaryServerLogInterceptor(log)
+	info := &grpc.UnaryServerInfo{FullMethod: "/hipstershop.CartService/GetCart"}
+	_, err = server(metadata.NewIncomingContext(context.Background(), sent), nil, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			if got := RequestID(ctx); got != "request-1" {
+				t.Errorf("RequestID() = %q in the handler, want request-1", got)
+			}
+			LoggerFromContext(ctx, nil).Info("handling")
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := lastLine(t, &out)
+	for k, want := range map[string]string{
+		"rpc.method": info.FullMethod,
+		"session":    "session-1",
+		"request_id": "request-1",
+	} {
+		if got := fields[k]; got != want {
+			t.Errorf("field %s = %v, want %q", k, got, want)
+		}
+	}
+}
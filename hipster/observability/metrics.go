@@ -0,0 +1,152 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/metric/prometheus"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/metric/controller/pull"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// DefaultMetricsPushInterval is how often metrics are pushed to an OTLP
+// collector when Config.MetricsPushInterval is not set.
+const DefaultMetricsPushInterval = 30 * time.Second
+
+// LatencyBoundaries are the histogram buckets, in milliseconds, of the
+// latency instruments.
+var LatencyBoundaries = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// SetupMetrics installs the global meter provider described by c. The
+// returned handler serves the current value of every instrument in the
+// Prometheus text format; it is meant to be mounted on /metrics. If
+// c.MetricsOTLPEndpoint is set the metrics are also pushed there. The
+// returned function pushes the last values and must be called before the
+// process exits.
+//
+// Instruments created before SetupMetrics is called through the global
+// provider, as package-level instruments usually are, are recorded too.
+func SetupMetrics(ctx context.Context, c Config) (handler http.Handler, shutdown func(context.Context) error, err error) {
+	if c.MetricsDisabled {
+		return http.NotFoundHandler(), func(context.Context) error { return nil }, nil
+	}
+
+	// Only the service is attached to every series: Prometheus adds the
+	// target's own labels, and a per-process instance ID would multiply the
+	// number of series. Every scrape collects afresh, so scrapes and pushes
+	// never see stale values.
+	res := resource.NewWithAttributes(
+		semconv.ServiceNameKey.String(c.ServiceName),
+		semconv.ServiceNamespaceKey.String(c.ServiceNamespace),
+	)
+	prom, err := prometheus.InstallNewPipeline(
+		prometheus.Config{DefaultHistogramBoundaries: LatencyBoundaries},
+		pull.WithResource(res), pull.WithCachePeriod(0),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create prometheus exporter: %v", err)
+	}
+	if c.MetricsOTLPEndpoint == "" {
+		return prom, func(context.Context) error { return nil }, nil
+	}
+
+	exp, err := otlp.NewExporter(ctx, otlp.WithInsecure(), otlp.WithAddress(c.MetricsOTLPEndpoint))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create OTLP metric exporter: %v", err)
+	}
+	interval := c.MetricsPushInterval
+	if interval <= 0 {
+		interval = DefaultMetricsPushInterval
+	}
+	p := &metricsPusher{
+		controller: prom.Controller(),
+		exporter:   exp,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go p.run(interval)
+	return prom, p.shutdown, nil
+}
+
+// metricsPusher periodically exports what the Prometheus pipeline collects
+// to an OTLP collector, so that both see the same cumulative values.
+type metricsPusher struct {
+	controller *pull.Controller
+	exporter   *otlp.Exporter
+	stop, done chan struct{}
+}
+
+func (p *metricsPusher) run(interval time.Duration) {
+	defer close(p.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := p.push(ctx); err != nil {
+				otel.Handle(fmt.Errorf("failed to push metrics: %v", err))
+			}
+			cancel()
+		}
+	}
+}
+
+func (p *metricsPusher) push(ctx context.Context) error {
+	if err := p.controller.Collect(ctx); err != nil {
+		return err
+	}
+	return p.exporter.Export(ctx, controllerCheckpoint{p.controller})
+}
+
+// shutdown stops the periodic pushes, pushes the last values and closes the
+// exporter.
+func (p *metricsPusher) shutdown(ctx context.Context) error {
+	close(p.stop)
+	<-p.done
+	err := p.push(ctx)
+	if serr := p.exporter.Shutdown(ctx); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// controllerCheckpoint presents the last collection of a pull controller as
+// an export.CheckpointSet. The controller does its own locking in ForEach,
+// so the locking methods do nothing.
+type controllerCheckpoint struct {
+	controller *pull.Controller
+}
+
+var _ export.CheckpointSet = controllerCheckpoint{}
+
+func (c controllerCheckpoint) ForEach(ks export.ExportKindSelector, f func(export.Record) error) error {
+	return c.controller.ForEach(ks, f)
+}
+
+func (controllerCheckpoint) Lock()    {}
+func (controllerCheckpoint) Unlock()  {}
+func (controllerCheckpoint) RLock()   {}
+func (controllerCheckpoint) RUnlock() {}
@@ -0,0 +1,149 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupMetrics(t *testing.T, c Config) http.Handler {
+	t.Helper()
+	h, shutdown, err := SetupMetrics(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { shutdown(context.Background()) })
+	return h
+}
+
+// hasSample reports whether body has a sample of metric with all of labels
+// and value.
+func hasSample(body, metric, value string, labels ...string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if !strings.HasPrefix(line, metric+"{") || !strings.HasSuffix(line, "} "+value) {
+			continue
+		}
+		matches := true
+		for _, l := range labels {
+			matches = matches && strings.Contains(line, l)
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// scrape returns the body h serves on /metrics.
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics: status %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+func TestSetupMetrics(t *testing.T) {
+	h := setupMetrics(t, Config{ServiceName: "checkoutservice"})
+	orders := metric.Must(otel.Meter("test")).NewInt64Counter("orders.placed")
+	orders.Add(context.Background(), 2, label.String("currency", "EUR"))
+
+	if body := scrape(t, h); !hasSample(body, "orders_placed", "2", `currency="EUR"`, `service_name="checkoutservice"`) {
+		t.Errorf("/metrics has no orders_placed sample:\n%s", body)
+	}
+}
+
+func TestSetupMetricsDisabled(t *testing.T) {
+	h := setupMetrics(t, Config{MetricsDisabled: true})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics with metrics disabled: status %d, want 404", w.Code)
+	}
+}
+
+func TestServerMetricsInterceptors(t *testing.T) {
+	h := setupMetrics(t, Config{})
+	unary := UnaryServerMetricsInterceptor()
+	stream := StreamServerMetricsInterceptor()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/hipstershop.ShippingService/GetQuote"}
+	for _, err := range []error{nil, nil, status.Error(codes.InvalidArgument, "no address")} {
+		unary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+	}
+	stream(nil, fakeServerStream{}, &grpc.StreamServerInfo{FullMethod: "/hipstershop.ProductCatalogService/WatchCatalog"},
+		func(srv interface{}, ss grpc.ServerStream) error { return status.Error(codes.Unavailable, "stopping") })
+
+	body := scrape(t, h)
+	for _, want := range []struct {
+		metric, value string
+		labels        []string
+	}{
+		{"grpc_server_calls", "2", []string{`rpc_grpc_status_code="OK"`, `rpc_method="GetQuote"`, `rpc_service="hipstershop.ShippingService"`}},
+		{"grpc_server_calls", "1", []string{`rpc_grpc_status_code="InvalidArgument"`, `rpc_method="GetQuote"`}},
+		{"grpc_server_calls", "1", []string{`rpc_grpc_status_code="Unavailable"`, `rpc_method="WatchCatalog"`}},
+		{"grpc_server_duration_count", "2", []string{`rpc_grpc_status_code="OK"`, `rpc_method="GetQuote"`}},
+	} {
+		if !hasSample(body, want.metric, want.value, want.labels...) {
+			t.Errorf("/metrics has no %s sample %s with %v:\n%s", want.metric, want.value, want.labels, body)
+		}
+	}
+}
+
+func TestServerMetricsSubMillisecond(t *testing.T) {
+	h := setupMetrics(t, Config{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/hipstershop.ShippingService/GetQuote"}
+	// The RPC returns at once, well within a millisecond.
+	UnaryServerMetricsInterceptor()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	for _, line := range strings.Split(scrape(t, h), "\n") {
+		if !strings.HasPrefix(line, "grpc_server_duration_sum{") {
+			continue
+		}
+		sum, err := strconv.ParseFloat(line[strings.LastIndex(line, " ")+1:], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum <= 0 {
+			t.Errorf("grpc.server.duration of an instant RPC = %gms, want a fraction of a millisecond", sum)
+		}
+		return
+	}
+	t.Error("/metrics has no grpc_server_duration_sum sample")
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+}
+
+func (fakeServerStream) Context() context.Context { return context.Background() }
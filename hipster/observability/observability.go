@@ -0,0 +1,228 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package observability sets up tracing and metrics for the Go services: the
+// resource describing the process, the span exporter, the sampler, the
+// propagators and the metric exporters.
+package observability
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/exporters/trace/jaeger"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// Exporters selectable in Config.Exporter.
+const (
+	ExporterStdout = "stdout"
+	ExporterJaeger = "jaeger"
+	ExporterOTLP   = "otlp"
+)
+
+// Config describes how a service is traced.
+type Config struct {
+	// Disabled turns tracing off; Setup then only installs the propagators.
+	Disabled bool
+
+	ServiceName      string
+	ServiceNamespace string
+	// ServiceInstanceID identifies the process; a random ID is used if it
+	// is empty.
+	ServiceInstanceID string
+	HostName          string
+	HostIP            string
+	ResourceType      string
+
+	// Exporter is one of ExporterStdout (the default), ExporterJaeger or
+	// ExporterOTLP.
+	Exporter       string
+	JaegerEndpoint string
+	JaegerUser     string
+	JaegerPassword string
+	OTLPEndpoint   string
+	// StdoutWriter receives the spans of the stdout exporter; it defaults
+	// to os.Stdout.
+	StdoutWriter io.Writer
+
+	// SpanExporter, if set, is used instead of the exporter selected by
+	// Exporter. Tests use it to capture spans in memory.
+	SpanExporter exporttrace.SpanExporter
+
+	// Sampling describes which traces are recorded.
+	Sampling SamplerConfig
+	// Sampler, if set, is used instead of the one described by Sampling.
+	Sampler trace.Sampler
+
+	// MetricsDisabled turns metrics off; SetupMetrics then installs
+	// nothing.
+	MetricsDisabled bool
+	// MetricsOTLPEndpoint, if set, is the address of an OTLP collector that
+	// metrics are pushed to every MetricsPushInterval, in addition to being
+	// served to Prometheus.
+	MetricsOTLPEndpoint string
+	MetricsPushInterval time.Duration
+}
+
+// ConfigFromEnv reads the configuration from the environment variables the
+// services are deployed with. serviceName is used when SERVICE_NAME is not
+// set.
+//
+//	DISABLE_TRACING        any value turns tracing off
+//	SERVICE_NAME           service.name
+//	SERVICE_NAMESPACE      service.namespace, default "hipster"
+//	HOSTNAME               service.instance.id
+//	MY_POD_NAME            host.name
+//	MY_POD_IP              ip
+//	RESOURCE_TYPE          resource.type
+//	EXPORT_TYPE            JAEGER, OTLP or anything else for stdout
+//	JAEGER_ENDPOINT        Jaeger collector endpoint
+//	JAEGER_USER            Jaeger collector user name
+//	JAEGER_PASSWORD        Jaeger collector password
+//	OTLP_ENDPOINT          OTLP collector address; stdout is used if unset
+//	DISABLE_METRICS        any value turns metrics off
+//	METRICS_OTLP_ENDPOINT  OTLP collector address metrics are pushed to
+//	METRICS_PUSH_INTERVAL  how often metrics are pushed, default 30s
+//
+// The sampler is configured as described by SamplerConfigFromEnv.
+func ConfigFromEnv(serviceName string) (Config, error) {
+	c := Config{
+		Disabled:          os.Getenv("DISABLE_TRACING") != "",
+		ServiceName:       serviceName,
+		ServiceNamespace:  "hipster",
+		ServiceInstanceID: os.Getenv("HOSTNAME"),
+		HostName:          os.Getenv("MY_POD_NAME"),
+		HostIP:            os.Getenv("MY_POD_IP"),
+		ResourceType:      os.Getenv("RESOURCE_TYPE"),
+		JaegerEndpoint:    os.Getenv("JAEGER_ENDPOINT"),
+		JaegerUser:        os.Getenv("JAEGER_USER"),
+		JaegerPassword:    os.Getenv("JAEGER_PASSWORD"),
+		OTLPEndpoint:      os.Getenv("OTLP_ENDPOINT"),
+
+		MetricsDisabled:     os.Getenv("DISABLE_METRICS") != "",
+		MetricsOTLPEndpoint: os.Getenv("METRICS_OTLP_ENDPOINT"),
+	}
+	if v := os.Getenv("SERVICE_NAME"); v != "" {
+		c.ServiceName = v
+	}
+	if v := os.Getenv("SERVICE_NAMESPACE"); v != "" {
+		c.ServiceNamespace = v
+	}
+	switch strings.ToUpper(os.Getenv("EXPORT_TYPE")) {
+	case "JAEGER":
+		c.Exporter = ExporterJaeger
+	case "OTLP":
+		if c.OTLPEndpoint != "" {
+			c.Exporter = ExporterOTLP
+		}
+	}
+	if v := os.Getenv("METRICS_PUSH_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return c, fmt.Errorf("invalid METRICS_PUSH_INTERVAL: %v", err)
+		}
+		c.MetricsPushInterval = d
+	}
+	var err error
+	c.Sampling, err = SamplerConfigFromEnv()
+	return c, err
+}
+
+// Resource returns the resource describing the process.
+func (c Config) Resource(ctx context.Context) (*resource.Resource, error) {
+	instanceID := c.ServiceInstanceID
+	if instanceID == "" {
+		instanceID = uuid.New().String()
+	}
+	return resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceInstanceIDKey.String(instanceID),
+			semconv.ServiceNameKey.String(c.ServiceName),
+			semconv.HostNameKey.String(c.HostName),
+			label.String("service.namespace", c.ServiceNamespace),
+			label.String("ip", c.HostIP),
+			label.String("resource.type", c.ResourceType),
+		),
+	)
+}
+
+// NewSpanExporter returns the exporter selected by the configuration.
+func (c Config) NewSpanExporter(ctx context.Context) (exporttrace.SpanExporter, error) {
+	if c.SpanExporter != nil {
+		return c.SpanExporter, nil
+	}
+	switch c.Exporter {
+	case ExporterJaeger:
+		return jaeger.NewRawExporter(
+			jaeger.WithCollectorEndpoint(c.JaegerEndpoint,
+				jaeger.WithUsername(c.JaegerUser), jaeger.WithPassword(c.JaegerPassword)),
+			jaeger.WithProcess(jaeger.Process{ServiceName: c.ServiceName}),
+		)
+	case ExporterOTLP:
+		return otlp.NewExporter(ctx, otlp.WithInsecure(), otlp.WithAddress(c.OTLPEndpoint))
+	case "", ExporterStdout:
+		w := c.StdoutWriter
+		if w == nil {
+			w = os.Stdout
+		}
+		return stdout.NewExporter(stdout.WithPrettyPrint(), stdout.WithWriter(w))
+	default:
+		return nil, fmt.Errorf("unknown span exporter %q", c.Exporter)
+	}
+}
+
+// Setup installs the global tracer provider and propagators described by c.
+// The returned function flushes the spans that are still buffered and stops
+// the provider; it must be called before the process exits.
+func Setup(ctx context.Context, c Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if c.Disabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := c.Resource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect resource: %v", err)
+	}
+	exp, err := c.NewSpanExporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span exporter: %v", err)
+	}
+	sampler := c.Sampler
+	if sampler == nil {
+		if sampler, err = c.Sampling.NewSampler(); err != nil {
+			return nil, fmt.Errorf("failed to create sampler: %v", err)
+		}
+	}
+	tp := trace.NewTracerProvider(
+		trace.WithConfig(trace.Config{DefaultSampler: sampler, Resource: res}),
+		trace.WithSpanProcessor(trace.NewBatchSpanProcessor(exp)),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/export/trace/tracetest"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// traceOne records a single span through the global tracer provider and
+// flushes it.
+func traceOne(t *testing.T, c Config) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	c.SpanExporter = exp
+	shutdown, err := Setup(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return exp
+}
+
+func TestSetup(t *testing.T) {
+	exp := traceOne(t, Config{ServiceName: "checkoutservice", ServiceNamespace: "hipster", ServiceInstanceID: "pod-1"})
+	spans := exp.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	attrs := make(map[string]string)
+	for _, kv := range spans[0].Resource.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	for k, want := range map[string]string{
+		string(semconv.ServiceNameKey):       "checkoutservice",
+		string(semconv.ServiceInstanceIDKey): "pod-1",
+		"service.namespace":                  "hipster",
+	} {
+		if got := attrs[k]; got != want {
+			t.Errorf("resource attribute %s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestSetupSampler(t *testing.T) {
+	if spans := traceOne(t, Config{Sampler: trace.NeverSample()}).GetSpans(); len(spans) != 0 {
+		t.Errorf("exported %d spans with a sampler that drops everything", len(spans))
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	if spans := traceOne(t, Config{Disabled: true}).GetSpans(); len(spans) != 0 {
+		t.Errorf("exported %d spans with tracing disabled", len(spans))
+	}
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Setup accepted an unknown exporter")
+	}
+}
+
+func setenv(t *testing.T, env map[string]string) {
+	for k, v := range env {
+		old, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		k := k
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(k, old)
+			} else {
+				os.Unsetenv(k)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		env          map[string]string
+		wantExporter string
+		wantName     string
+	}{
+		{map[string]string{"EXPORT_TYPE": ""}, "", "default"},
+		{map[string]string{"EXPORT_TYPE": "JAEGER", "SERVICE_NAME": "named"}, ExporterJaeger, "named"},
+		{map[string]string{"EXPORT_TYPE": "OTLP", "OTLP_ENDPOINT": "collector:4317"}, ExporterOTLP, "default"},
+		// Without an endpoint OTLP falls back to stdout.
+		{map[string]string{"EXPORT_TYPE": "OTLP", "OTLP_ENDPOINT": ""}, "", "default"},
+	} {
+		t.Run(tc.env["EXPORT_TYPE"], func(t *testing.T) {
+			setenv(t, map[string]string{"SERVICE_NAME": "", "OTLP_ENDPOINT": ""})
+			setenv(t, tc.env)
+			c, err := ConfigFromEnv("default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Exporter != tc.wantExporter || c.ServiceName != tc.wantName {
+				t.Errorf("ConfigFromEnv() = exporter %q, service %q; want %q, %q",
+					c.Exporter, c.ServiceName, tc.wantExporter, tc.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// Sampler types selectable in SamplerConfig.Type.
+const (
+	SamplerAlways = "always"
+	SamplerNever  = "never"
+	SamplerRatio  = "ratio"
+)
+
+// DefaultSamplingRules keep health checks, metric scrapes and static assets
+// out of traces.
+var DefaultSamplingRules = []SamplingRule{
+	{Prefix: "/_healthz", Ratio: 0},
+	{Prefix: "/metrics", Ratio: 0},
+	{Prefix: "/static/", Ratio: 0},
+	{Prefix: "grpc.health.v1.Health/", Ratio: 0},
+}
+
+// SamplerConfig describes which traces are recorded. The decision for a span
+// is made by, in order:
+//
+//  1. the first rule whose prefix matches the span name or its HTTP route or
+//     target;
+//  2. the parent span's decision, unless IgnoreParent is set;
+//  3. the sampler selected by Type, capped at RateLimit traces per second.
+type SamplerConfig struct {
+	// Type is SamplerAlways (the default), SamplerNever or SamplerRatio.
+	Type string `json:"type"`
+	// Ratio is the fraction of traces a SamplerRatio sampler records.
+	Ratio float64 `json:"ratio"`
+	// IgnoreParent makes every span decide for itself instead of following
+	// its parent, which can break traces into pieces.
+	IgnoreParent bool `json:"ignore_parent"`
+	// RateLimit caps the number of traces started per second; zero means
+	// no cap.
+	RateLimit float64        `json:"rate_limit"`
+	Rules     []SamplingRule `json:"rules"`
+}
+
+// SamplingRule overrides the sampling ratio of the spans it matches.
+type SamplingRule struct {
+	// Prefix is matched against the span name and the http.route and
+	// http.target attributes.
+	Prefix string  `json:"prefix"`
+	Ratio  float64 `json:"ratio"`
+}
+
+// SamplerConfigFromEnv reads the sampler configuration from the JSON file
+// named by TRACE_SAMPLER_CONFIG or, if that is not set, from:
+//
+//	TRACE_SAMPLER                always, never or ratio
+//	TRACE_SAMPLER_RATIO          fraction of traces recorded by ratio
+//	TRACE_SAMPLER_IGNORE_PARENT  true to not follow the parent's decision
+//	TRACE_SAMPLER_RATE_LIMIT     maximum traces per second
+//	TRACE_SAMPLER_RULES          comma-separated prefix=ratio rules;
+//	                             DefaultSamplingRules if unset, none if
+//	                             set to the empty string
+func SamplerConfigFromEnv() (SamplerConfig, error) {
+	if path := os.Getenv("TRACE_SAMPLER_CONFIG"); path != "" {
+		return samplerConfigFromFile(path)
+	}
+	c := SamplerConfig{Type: os.Getenv("TRACE_SAMPLER"), Ratio: 1, Rules: DefaultSamplingRules}
+	var err error
+	if s := os.Getenv("TRACE_SAMPLER_RATIO"); s != "" {
+		if c.Ratio, err = strconv.ParseFloat(s, 64); err != nil {
+			return c, fmt.Errorf("invalid TRACE_SAMPLER_RATIO: %v", err)
+		}
+	}
+	if s := os.Getenv("TRACE_SAMPLER_IGNORE_PARENT"); s != "" {
+		if c.IgnoreParent, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid TRACE_SAMPLER_IGNORE_PARENT: %v", err)
+		}
+	}
+	if s := os.Getenv("TRACE_SAMPLER_RATE_LIMIT"); s != "" {
+		if c.RateLimit, err = strconv.ParseFloat(s, 64); err != nil {
+			return c, fmt.Errorf("invalid TRACE_SAMPLER_RATE_LIMIT: %v", err)
+		}
+	}
+	if s, ok := os.LookupEnv("TRACE_SAMPLER_RULES"); ok {
+		if c.Rules, err = parseSamplingRules(s); err != nil {
+			return c, fmt.Errorf("invalid TRACE_SAMPLER_RULES: %v", err)
+		}
+	}
+	return c, c.validate()
+}
+
+func samplerConfigFromFile(path string) (SamplerConfig, error) {
+	c := SamplerConfig{Ratio: 1, Rules: DefaultSamplingRules}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("failed to read sampler config: %v", err)
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("failed to parse sampler config %s: %v", path, err)
+	}
+	return c, c.validate()
+}
+
+// parseSamplingRules parses comma-separated prefix=ratio pairs.
+func parseSamplingRules(s string) ([]SamplingRule, error) {
+	var rules []SamplingRule
+	for _, r := range strings.Split(s, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		i := strings.LastIndex(r, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("rule %q is not of the form prefix=ratio", r)
+		}
+		ratio, err := strconv.ParseFloat(r[i+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %v", r, err)
+		}
+		rules = append(rules, SamplingRule{Prefix: r[:i], Ratio: ratio})
+	}
+	return rules, nil
+}
+
+func (c SamplerConfig) validate() error {
+	switch c.Type {
+	case "", SamplerAlways, SamplerNever, SamplerRatio:
+	default:
+		return fmt.Errorf("unknown sampler %q", c.Type)
+	}
+	if c.Ratio < 0 || c.Ratio > 1 {
+		return fmt.Errorf("sampling ratio %v is not between 0 and 1", c.Ratio)
+	}
+	if c.RateLimit < 0 {
+		return fmt.Errorf("negative sampling rate limit %v", c.RateLimit)
+	}
+	for _, r := range c.Rules {
+		if r.Ratio < 0 || r.Ratio > 1 {
+			return fmt.Errorf("sampling ratio %v of rule %q is not between 0 and 1", r.Ratio, r.Prefix)
+		}
+	}
+	return nil
+}
+
+// NewSampler builds the sampler described by c.
+func (c SamplerConfig) NewSampler() (trace.Sampler, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	var s trace.Sampler
+	switch c.Type {
+	case "", SamplerAlways:
+		s = trace.AlwaysSample()
+	case SamplerNever:
+		s = trace.NeverSample()
+	case SamplerRatio:
+		s = trace.TraceIDRatioBased(c.Ratio)
+	}
+	if c.RateLimit > 0 {
+		s = newRateLimitedSampler(s, c.RateLimit)
+	}
+	if !c.IgnoreParent {
+		s = trace.ParentBased(s)
+	}
+	if len(c.Rules) > 0 {
+		rs := ruleSampler{next: s}
+		for _, r := range c.Rules {
+			rs.rules = append(rs.rules, compiledRule{prefix: r.Prefix, sampler: trace.TraceIDRatioBased(r.Ratio)})
+		}
+		s = rs
+	}
+	return s, nil
+}
+
+type compiledRule struct {
+	prefix  string
+	sampler trace.Sampler
+}
+
+// ruleSampler hands the spans matched by a rule to the rule's sampler and
+// all others to next.
+type ruleSampler struct {
+	rules []compiledRule
+	next  trace.Sampler
+}
+
+func (s ruleSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	for _, r := range s.rules {
+		if matchesPrefix(p, r.prefix) {
+			return r.sampler.ShouldSample(p)
+		}
+	}
+	return s.next.ShouldSample(p)
+}
+
+func matchesPrefix(p trace.SamplingParameters, prefix string) bool {
+	if strings.HasPrefix(p.Name, prefix) {
+		return true
+	}
+	for _, kv := range p.Attributes {
+		if kv.Key == semconv.HTTPRouteKey || kv.Key == semconv.HTTPTargetKey {
+			if kv.Value.Type() == label.STRING && strings.HasPrefix(kv.Value.AsString(), prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s ruleSampler) Description() string {
+	var rules []string
+	for _, r := range s.rules {
+		rules = append(rules, r.prefix+"="+r.sampler.Description())
+	}
+	return fmt.Sprintf("Rules{%s}/%s", strings.Join(rules, ","), s.next.Description())
+}
+
+// rateLimitedSampler samples what next samples, as long as that stays under
+// a number of traces per second. It is a token bucket holding up to one
+// second's worth of traces.
+type rateLimitedSampler struct {
+	next  trace.Sampler
+	rate  float64
+	clock func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimitedSampler(next trace.Sampler, perSecond float64) *rateLimitedSampler {
+	return &rateLimitedSampler{next: next, rate: perSecond, clock: time.Now, tokens: burst(perSecond)}
+}
+
+func burst(perSecond float64) float64 {
+	if perSecond < 1 {
+		return 1
+	}
+	return perSecond
+}
+
+func (s *rateLimitedSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	res := s.next.ShouldSample(p)
+	if res.Decision != trace.RecordAndSample || s.take() {
+		return res
+	}
+	return trace.SamplingResult{Decision: trace.Drop}
+}
+
+// take removes a token from the bucket, if there is one.
+func (s *rateLimitedSampler) take() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	if !s.last.IsZero() {
+		s.tokens += now.Sub(s.last).Seconds() * s.rate
+		if max := burst(s.rate); s.tokens > max {
+			s.tokens = max
+		}
+	}
+	s.last = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+func (s *rateLimitedSampler) Description() string {
+	return fmt.Sprintf("RateLimited{%g/s}/%s", s.rate, s.next.Description())
+}
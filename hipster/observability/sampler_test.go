@@ -0,0 +1,224 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func randomTraceID() oteltrace.TraceID {
+	var id oteltrace.TraceID
+	rand.Read(id[:])
+	return id
+}
+
+// sampled counts how many of n root spans named name s samples.
+func sampled(s trace.Sampler, n int, name string, attrs ...label.KeyValue) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		res := s.ShouldSample(trace.SamplingParameters{TraceID: randomTraceID(), Name: name, Attributes: attrs})
+		if res.Decision == trace.RecordAndSample {
+			count++
+		}
+	}
+	return count
+}
+
+func newSampler(t *testing.T, c SamplerConfig) trace.Sampler {
+	t.Helper()
+	s, err := c.NewSampler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSamplerTypes(t *testing.T) {
+	for _, tc := range []struct {
+		config   SamplerConfig
+		min, max int
+	}{
+		{SamplerConfig{}, 1000, 1000},
+		{SamplerConfig{Type: SamplerAlways}, 1000, 1000},
+		{SamplerConfig{Type: SamplerNever}, 0, 0},
+		{SamplerConfig{Type: SamplerRatio, Ratio: 0.25}, 180, 320},
+	} {
+		got := sampled(newSampler(t, tc.config), 1000, "hipstershop.CheckoutService/PlaceOrder")
+		if got < tc.min || got > tc.max {
+			t.Errorf("%+v sampled %d of 1000 traces, want between %d and %d", tc.config, got, tc.min, tc.max)
+		}
+	}
+}
+
+func TestSamplerRules(t *testing.T) {
+	s := newSampler(t, SamplerConfig{Rules: DefaultSamplingRules})
+	for _, tc := range []struct {
+		name  string
+		attrs []label.KeyValue
+		want  int
+	}{
+		{"/_healthz", nil, 0},
+		{"/static/", []label.KeyValue{semconv.HTTPTargetKey.String("/static/img/logo.png")}, 0},
+		{"HTTP GET", []label.KeyValue{semconv.HTTPRouteKey.String("/static/")}, 0},
+		{"grpc.health.v1.Health/Check", nil, 0},
+		{"/product/{id}", []label.KeyValue{semconv.HTTPTargetKey.String("/product/OLJCESPC7Z")}, 10},
+		{"hipstershop.CartService/GetCart", nil, 10},
+	} {
+		if got := sampled(s, 10, tc.name, tc.attrs...); got != tc.want {
+			t.Errorf("sampled %d of 10 %q spans, want %d", got, tc.name, tc.want)
+		}
+	}
+}
+
+func TestSamplerParentBased(t *testing.T) {
+	traceID := randomTraceID()
+	parent := func(sampled bool) oteltrace.SpanContext {
+		sc := oteltrace.SpanContext{TraceID: traceID, SpanID: oteltrace.SpanID{1}}
+		if sampled {
+			sc.TraceFlags = oteltrace.FlagsSampled
+		}
+		return sc
+	}
+	decide := func(s trace.Sampler, parentSampled bool) trace.SamplingDecision {
+		return s.ShouldSample(trace.SamplingParameters{
+			ParentContext: parent(parentSampled), TraceID: traceID, Name: "op", HasRemoteParent: true,
+		}).Decision
+	}
+
+	never := newSampler(t, SamplerConfig{Type: SamplerNever})
+	if got := decide(never, true); got != trace.RecordAndSample {
+		t.Errorf("child of a sampled span: decision %v, want RecordAndSample", got)
+	}
+	always := newSampler(t, SamplerConfig{Type: SamplerAlways})
+	if got := decide(always, false); got != trace.Drop {
+		t.Errorf("child of a dropped span: decision %v, want Drop", got)
+	}
+	ignoring := newSampler(t, SamplerConfig{Type: SamplerNever, IgnoreParent: true})
+	if got := decide(ignoring, true); got != trace.Drop {
+		t.Errorf("child of a sampled span with IgnoreParent: decision %v, want Drop", got)
+	}
+}
+
+func TestRateLimitedSampler(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := newRateLimitedSampler(trace.AlwaysSample(), 10)
+	s.clock = func() time.Time { return now }
+
+	if got := sampled(s, 100, "op"); got != 10 {
+		t.Errorf("sampled %d of a burst of 100 traces, want 10", got)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if got := sampled(s, 100, "op"); got != 5 {
+		t.Errorf("sampled %d traces after half a second, want 5", got)
+	}
+	// The bucket never holds more than a second's worth of traces.
+	now = now.Add(time.Minute)
+	if got := sampled(s, 100, "op"); got != 10 {
+		t.Errorf("sampled %d traces after a minute, want 10", got)
+	}
+}
+
+func TestRateLimitedSamplerKeepsDrops(t *testing.T) {
+	s := newRateLimitedSampler(trace.NeverSample(), 10)
+	if got := sampled(s, 10, "op"); got != 0 {
+		t.Errorf("sampled %d traces the wrapped sampler dropped", got)
+	}
+	if s.tokens != 10 {
+		t.Errorf("dropped traces used %v tokens", 10-s.tokens)
+	}
+}
+
+func TestSamplerConfigFromEnv(t *testing.T) {
+	setenv(t, map[string]string{
+		"TRACE_SAMPLER_CONFIG":        "",
+		"TRACE_SAMPLER":               "ratio",
+		"TRACE_SAMPLER_RATIO":         "0.5",
+		"TRACE_SAMPLER_IGNORE_PARENT": "true",
+		"TRACE_SAMPLER_RATE_LIMIT":    "100",
+		"TRACE_SAMPLER_RULES":         "/_healthz=0, /cart=0.1",
+	})
+	c, err := SamplerConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SamplerConfig{
+		Type: SamplerRatio, Ratio: 0.5, IgnoreParent: true, RateLimit: 100,
+		Rules: []SamplingRule{{"/_healthz", 0}, {"/cart", 0.1}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("SamplerConfigFromEnv() = %+v, want %+v", c, want)
+	}
+}
+
+func TestSamplerConfigFromEnvDefaults(t *testing.T) {
+	for _, k := range []string{"TRACE_SAMPLER_CONFIG", "TRACE_SAMPLER", "TRACE_SAMPLER_RATIO",
+		"TRACE_SAMPLER_IGNORE_PARENT", "TRACE_SAMPLER_RATE_LIMIT", "TRACE_SAMPLER_RULES"} {
+		setenv(t, map[string]string{k: ""})
+	}
+	c, err := SamplerConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SamplerConfig{Ratio: 1}); !reflect.DeepEqual(c, want) {
+		t.Errorf("SamplerConfigFromEnv() = %+v, want %+v", c, want)
+	}
+}
+
+func TestSamplerConfigFromEnvInvalid(t *testing.T) {
+	for _, env := range []map[string]string{
+		{"TRACE_SAMPLER": "sometimes"},
+		{"TRACE_SAMPLER_RATIO": "half"},
+		{"TRACE_SAMPLER_RATIO": "2"},
+		{"TRACE_SAMPLER_RATE_LIMIT": "-1"},
+		{"TRACE_SAMPLER_RULES": "/_healthz"},
+		{"TRACE_SAMPLER_RULES": "/_healthz=1.5"},
+	} {
+		t.Run("", func(t *testing.T) {
+			setenv(t, map[string]string{"TRACE_SAMPLER_CONFIG": "", "TRACE_SAMPLER": "",
+				"TRACE_SAMPLER_RATIO": "", "TRACE_SAMPLER_RATE_LIMIT": "", "TRACE_SAMPLER_RULES": ""})
+			setenv(t, env)
+			if _, err := SamplerConfigFromEnv(); err == nil {
+				t.Errorf("SamplerConfigFromEnv() accepted %v", env)
+			}
+		})
+	}
+}
+
+func TestSamplerConfigFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sampling.json")
+	if err := ioutil.WriteFile(path, []byte(`{"type": "ratio", "ratio": 0.1, "rate_limit": 50}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setenv(t, map[string]string{"TRACE_SAMPLER_CONFIG": path, "TRACE_SAMPLER": "never"})
+	c, err := SamplerConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SamplerConfig{Type: SamplerRatio, Ratio: 0.1, RateLimit: 50, Rules: DefaultSamplingRules}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("SamplerConfigFromEnv() = %+v, want %+v", c, want)
+	}
+}
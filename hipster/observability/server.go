@@ -0,0 +1,170 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// healthServicePrefix is the full method prefix of the health checks, which
+// are logged at debug level since probes call them every few seconds.
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// UnaryServerInterceptors returns the interceptors every gRPC server chains,
+// in order: tracing, metrics, the per-RPC logger, the access log, panic
+// recovery and request validation. Service-specific interceptors go after
+// them.
+func UnaryServerInterceptors(log *logrus.Logger) []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		otelgrpc.UnaryServerInterceptor(),
+		UnaryServerMetricsInterceptor(),
+		UnaryServerLogInterceptor(log),
+		UnaryServerAccessLogInterceptor(log),
+		UnaryServerRecoveryInterceptor(log),
+		UnaryServerValidationInterceptor(),
+	}
+}
+
+// StreamServerInterceptors is the streaming counterpart of
+// UnaryServerInterceptors. Streamed messages are not validated.
+func StreamServerInterceptors(log *logrus.Logger) []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		otelgrpc.StreamServerInterceptor(),
+		StreamServerMetricsInterceptor(),
+		StreamServerLogInterceptor(log),
+		StreamServerAccessLogInterceptor(log),
+		StreamServerRecoveryInterceptor(log),
+	}
+}
+
+// accessLog logs one line for a finished RPC.
+func accessLog(ctx context.Context, log *logrus.Logger, fullMethod string, start time.Time, err error) {
+	code := status.Code(err)
+	entry := LoggerFromContext(ctx, log).WithFields(logrus.Fields{
+		"rpc.method":      fullMethod,
+		"rpc.code":        code.String(),
+		"rpc.duration_ms": time.Since(start).Milliseconds(),
+	})
+	if p, ok := peer.FromContext(ctx); ok {
+		entry = entry.WithField("peer.address", p.Addr.String())
+	}
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	entry.Log(accessLogLevel(fullMethod, code), "rpc complete")
+}
+
+// accessLogLevel logs health checks at debug level and the codes that point
+// at a problem with the server at warning level.
+func accessLogLevel(fullMethod string, code codes.Code) logrus.Level {
+	if strings.HasPrefix(fullMethod, healthServicePrefix) {
+		return logrus.DebugLevel
+	}
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return logrus.WarnLevel
+	}
+	return logrus.InfoLevel
+}
+
+// UnaryServerAccessLogInterceptor logs one line per RPC with its method,
+// status code, duration and peer.
+func UnaryServerAccessLogInterceptor(log *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		accessLog(ctx, log, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerAccessLogInterceptor is the streaming counterpart of
+// UnaryServerAccessLogInterceptor; the line is logged when the stream ends.
+func StreamServerAccessLogInterceptor(log *logrus.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		accessLog(ss.Context(), log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// recovered logs a panic and returns the error the RPC fails with instead.
+// The panic value is only logged, not sent to the client.
+func recovered(ctx context.Context, log *logrus.Logger, fullMethod string, p interface{}) error {
+	LoggerFromContext(ctx, log).WithFields(logrus.Fields{
+		"rpc.method": fullMethod,
+		"panic":      p,
+		"stack":      string(debug.Stack()),
+	}).Error("recovered from panic")
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryServerRecoveryInterceptor turns a panic in the handler into a
+// codes.Internal error, so that it fails the RPC rather than the process.
+func UnaryServerRecoveryInterceptor(log *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				resp, err = nil, recovered(ctx, log, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecoveryInterceptor is the streaming counterpart of
+// UnaryServerRecoveryInterceptor.
+func StreamServerRecoveryInterceptor(log *logrus.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ss.Context(), log, info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// UnaryServerValidationInterceptor rejects nil requests with
+// codes.InvalidArgument before they reach the handler. Requests decoded
+// from the wire never are, but in-process callers can pass one.
+func UnaryServerValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isNil(req) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s: request is required", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
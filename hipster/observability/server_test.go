@@ -0,0 +1,183 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// panickingHealth panics on checks of the "panic" service.
+type panickingHealth struct {
+	healthpb.HealthServer
+}
+
+func (panickingHealth) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.GetService() == "panic" {
+		var m map[string]int
+		m["boom"]++
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (panickingHealth) Watch(req *healthpb.HealthCheckRequest, ws healthpb.Health_WatchServer) error {
+	panic("watch is broken")
+}
+
+// serveWithInterceptors serves panickingHealth through the standard
+// interceptor chain and returns a client for it.
+func serveWithInterceptors(t *testing.T, log *logrus.Logger) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptors(log)...),
+		grpc.ChainStreamInterceptor(StreamServerInterceptors(log)...),
+	)
+	healthpb.RegisterHealthServer(srv, panickingHealth{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func logLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, b := range bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(b, &fields); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, fields)
+	}
+	return lines
+}
+
+func TestRecovery(t *testing.T) {
+	var out bytes.Buffer
+	log := newTestLogger(&out)
+	client := serveWithInterceptors(t, log)
+	ctx := context.Background()
+
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "panic"}); status.Code(err) != codes.Internal {
+		t.Errorf("Check() of a panicking handler: %v, want Internal", err)
+	}
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Internal {
+		t.Errorf("Watch() of a panicking handler: %v, want Internal", err)
+	}
+	// The server survives the panics.
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("Check() after a panic: %v", err)
+	}
+
+	var panics int
+	for _, l := range logLines(t, &out) {
+		if l["msg"] == "recovered from panic" {
+			panics++
+			if l["stack"] == nil || l["level"] != "error" {
+				t.Errorf("panic logged as %v", l)
+			}
+		}
+	}
+	if panics != 2 {
+		t.Errorf("logged %d panics, want 2:\n%s", panics, out.String())
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	log := newTestLogger(&out)
+	log.Level = logrus.DebugLevel
+	client := serveWithInterceptors(t, log)
+
+	client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"})
+
+	var access []map[string]interface{}
+	for _, l := range logLines(t, &out) {
+		if l["msg"] == "rpc complete" {
+			access = append(access, l)
+		}
+	}
+	if len(access) != 2 {
+		t.Fatalf("logged %d access lines, want 2:\n%s", len(access), out.String())
+	}
+	for i, want := range []struct{ code, level string }{{"OK", "debug"}, {"Internal", "debug"}} {
+		l := access[i]
+		if l["rpc.method"] != "/grpc.health.v1.Health/Check" || l["rpc.code"] != want.code ||
+			l["level"] != want.level || l["peer.address"] == nil || l["rpc.duration_ms"] == nil {
+			t.Errorf("access line %d = %v, want code %s at %s", i, l, want.code, want.level)
+		}
+	}
+}
+
+func TestAccessLogLevel(t *testing.T) {
+	for _, tc := range []struct {
+		method string
+		code   codes.Code
+		want   logrus.Level
+	}{
+		{"/hipstershop.ShippingService/GetQuote", codes.OK, logrus.InfoLevel},
+		{"/hipstershop.ShippingService/GetQuote", codes.InvalidArgument, logrus.InfoLevel},
+		{"/hipstershop.ShippingService/GetQuote", codes.Internal, logrus.WarnLevel},
+		{"/hipstershop.CheckoutService/PlaceOrder", codes.Unavailable, logrus.WarnLevel},
+		{"/grpc.health.v1.Health/Check", codes.Internal, logrus.DebugLevel},
+	} {
+		if got := accessLogLevel(tc.method, tc.code); got != tc.want {
+			t.Errorf("accessLogLevel(%s, %v) = %v, want %v", tc.method, tc.code, got, tc.want)
+		}
+	}
+}
+
+func TestValidation(t *testing.T) {
+	validate := UnaryServerValidationInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+	for _, req := range []interface{}{nil, (*healthpb.HealthCheckRequest)(nil)} {
+		if _, err := validate(context.Background(), req, info, handler); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("validating %#v: %v, want InvalidArgument", req, err)
+		}
+	}
+	if called {
+		t.Error("handler called with a nil request")
+	}
+	if _, err := validate(context.Background(), &healthpb.HealthCheckRequest{}, info, handler); err != nil || !called {
+		t.Errorf("validating a request: %v, handler called: %v", err, called)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultShutdownTimeout is how long in-flight requests may take to
+	// finish after a SIGTERM. Together with telemetryFlushTimeout it stays
+	// within Kubernetes' default 30s termination grace period.
+	DefaultShutdownTimeout = 20 * time.Second
+
+	// telemetryFlushTimeout bounds the export of the spans and metrics still
+	// buffered at shutdown.
+	telemetryFlushTimeout = 5 * time.Second
+)
+
+// ShutdownTimeoutFromEnv reads SHUTDOWN_TIMEOUT as a time.Duration.
+func ShutdownTimeoutFromEnv() (time.Duration, error) {
+	if s := os.Getenv("SHUTDOWN_TIMEOUT"); s != "" {
+		return time.ParseDuration(s)
+	}
+	return DefaultShutdownTimeout, nil
+}
+
+// WaitForShutdownSignal blocks until the process receives SIGTERM or SIGINT.
+func WaitForShutdownSignal(log *logrus.Logger) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGTERM, os.Interrupt)
+	sig := <-ch
+	signal.Stop(ch)
+	log.WithField("signal", sig.String()).Info("shutting down")
+}
+
+// GracefulStop stops srv from accepting connections and waits for in-flight
+// RPCs to finish. RPCs still running when ctx expires are cancelled.
+func GracefulStop(ctx context.Context, log *logrus.Logger, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn("timed out draining RPCs, cancelling the remaining ones")
+		srv.Stop()
+		<-done
+	}
+}
+
+// FlushTelemetry exports the buffered spans and metrics and stops their
+// providers through the functions returned by Setup and SetupMetrics.
+func FlushTelemetry(log *logrus.Logger, shutdowns ...func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryFlushTimeout)
+	defer cancel()
+	for _, shutdown := range shutdowns {
+		if err := shutdown(ctx); err != nil {
+			log.WithError(err).Warn("failed to flush telemetry")
+		}
+	}
+}
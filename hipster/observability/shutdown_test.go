@@ -0,0 +1,110 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability/health"
+)
+
+// startSlowServer serves a health service whose checks block until release
+// is closed, and returns a client for it. started receives a value whenever
+// a check begins.
+func startSlowServer(t *testing.T, release <-chan struct{}) (srv *grpc.Server, client healthpb.HealthClient, started <-chan struct{}) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan struct{}, 1)
+	hs := health.NewServer()
+	hs.Timeout = time.Minute
+	hs.Register("slow", func(ctx context.Context) error {
+		ch <- struct{}{}
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	srv = grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return srv, healthpb.NewHealthClient(conn), ch
+}
+
+func TestGracefulStopDrainsRPCs(t *testing.T) {
+	release := make(chan struct{})
+	srv, client, started := startSlowServer(t, release)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		errc <- err
+	}()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		GracefulStop(context.Background(), logrus.New(), srv)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("GracefulStop returned while an RPC was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+	if err := <-errc; err != nil {
+		t.Errorf("in-flight RPC failed: %v", err)
+	}
+}
+
+func TestGracefulStopDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv, client, started := startSlowServer(t, release)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		errc <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	GracefulStop(ctx, logrus.New(), srv)
+	if err := <-errc; status.Code(err) != codes.Unavailable {
+		t.Errorf("RPC still running at the deadline: got %v, want Unavailable", err)
+	}
+}
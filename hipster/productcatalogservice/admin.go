@@ -0,0 +1,200 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// adminServicePrefix is the full method prefix of the admin RPCs, which are
+// the ones that require the admin token.
+const adminServicePrefix = "/hipstershop.ProductCatalogAdminService/"
+
+// catalogAdmin implements ProductCatalogAdminService. Every edit is applied to
+// a copy of the catalog being served, validated as a whole, saved through the
+// source and then reloaded, so that a successful call is visible to the next
+// read.
+type catalogAdmin struct {
+	catalog *catalogLoader
+	writer  catalogWriter
+
+	mu sync.Mutex // serializes edits
+}
+
+// newCatalogAdmin returns the admin service for catalog, or an error if its
+// source cannot be written to.
+func newCatalogAdmin(catalog *catalogLoader) (*catalogAdmin, error) {
+	w, ok := catalog.source.(catalogWriter)
+	if !ok {
+		return nil, fmt.Errorf("catalog source %s is read-only", catalog.source)
+	}
+	return &catalogAdmin{catalog: catalog, writer: w}, nil
+}
+
+// edit applies fn to a copy of the served products and persists the result.
+func (a *catalogAdmin) edit(ctx context.Context, fn func(products []*pb.Product) ([]*pb.Product, error)) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	served := a.catalog.index().products
+	products := make([]*pb.Product, len(served))
+	copy(products, served)
+	products, err := fn(products)
+	if err != nil {
+		return err
+	}
+	if err := validateCatalog(products); err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err := a.writer.Save(ctx, products); err != nil {
+		observability.LoggerFromContext(ctx, log).WithError(err).Error("failed to save product catalog")
+		return status.Errorf(codes.Internal, "failed to save catalog: %v", err)
+	}
+	if err := a.catalog.load(ctx); err != nil {
+		return status.Errorf(codes.Internal, "catalog saved but failed to reload: %v", err)
+	}
+	return nil
+}
+
+func indexOf(products []*pb.Product, id string) int {
+	for i, p := range products {
+		if p.GetId() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *catalogAdmin) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.Product, error) {
+	p := req.GetProduct()
+	if p == nil {
+		return nil, status.Error(codes.InvalidArgument, "product is required")
+	}
+	err := a.edit(ctx, func(products []*pb.Product) ([]*pb.Product, error) {
+		if indexOf(products, p.GetId()) >= 0 {
+			return nil, status.Errorf(codes.AlreadyExists, "product %q already exists", p.GetId())
+		}
+		return append(products, p), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	observability.LoggerFromContext(ctx, log).WithField("id", p.GetId()).Info("created product")
+	return p, nil
+}
+
+func (a *catalogAdmin) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+	p := req.GetProduct()
+	if p == nil {
+		return nil, status.Error(codes.InvalidArgument, "product is required")
+	}
+	err := a.edit(ctx, func(products []*pb.Product) ([]*pb.Product, error) {
+		i := indexOf(products, p.GetId())
+		if i < 0 {
+			return nil, status.Errorf(codes.NotFound, "no product with ID %s", p.GetId())
+		}
+		products[i] = p
+		return products, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	observability.LoggerFromContext(ctx, log).WithField("id", p.GetId()).Info("updated product")
+	return p, nil
+}
+
+func (a *catalogAdmin) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.Empty, error) {
+	err := a.edit(ctx, func(products []*pb.Product) ([]*pb.Product, error) {
+		i := indexOf(products, req.GetId())
+		if i < 0 {
+			return nil, status.Errorf(codes.NotFound, "no product with ID %s", req.GetId())
+		}
+		return append(products[:i], products[i+1:]...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	observability.LoggerFromContext(ctx, log).WithField("id", req.GetId()).Info("deleted product")
+	return &pb.Empty{}, nil
+}
+
+func (a *catalogAdmin) BulkImport(ctx context.Context, req *pb.BulkImportRequest) (*pb.BulkImportResponse, error) {
+	resp := new(pb.BulkImportResponse)
+	err := a.edit(ctx, func(products []*pb.Product) ([]*pb.Product, error) {
+		*resp = pb.BulkImportResponse{}
+		imported := make(map[string]bool, len(req.GetProducts()))
+		for _, p := range req.GetProducts() {
+			imported[p.GetId()] = true
+			if i := indexOf(products, p.GetId()); i >= 0 {
+				products[i] = p
+				resp.Updated++
+			} else {
+				products = append(products, p)
+				resp.Created++
+			}
+		}
+		if !req.GetReplace() {
+			return products, nil
+		}
+		kept := products[:0]
+		for _, p := range products {
+			if imported[p.GetId()] {
+				kept = append(kept, p)
+			} else {
+				resp.Deleted++
+			}
+		}
+		return kept, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	observability.LoggerFromContext(ctx, log).WithField("created", resp.Created).WithField("updated", resp.Updated).
+		WithField("deleted", resp.Deleted).Info("imported products")
+	return resp, nil
+}
+
+// adminAuthInterceptor rejects calls to the admin service that do not carry
+// "authorization: Bearer <token>" metadata. Other services are not affected.
+func adminAuthInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			return handler(ctx, req)
+		}
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "admin service is disabled")
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if got := strings.TrimPrefix(v, "Bearer "); got != v &&
+				subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid admin token")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+func TestCatalogAdmin(t *testing.T) {
+	catalog, path := newTestCatalog(t, testCatalogV1)
+	addr, stop := run("0", catalog, "s3cret")
+	defer stop(context.Background())
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	admin := pb.NewProductCatalogAdminServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer s3cret")
+
+	gamma := &pb.Product{Id: "G", Name: "Gamma", PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 5}}
+
+	for _, badCtx := range []context.Context{
+		context.Background(),
+		metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong"),
+		metadata.AppendToOutgoingContext(context.Background(), "authorization", "s3cret"),
+	} {
+		_, err := admin.CreateProduct(badCtx, &pb.CreateProductRequest{Product: gamma})
+		if got, want := status.Code(err), codes.Unauthenticated; got != want {
+			t.Errorf("CreateProduct without a valid token: got %s, want %s", got, want)
+		}
+	}
+	// Read-only RPCs do not need the token.
+	if _, err := pb.NewProductCatalogServiceClient(conn).ListProducts(context.Background(), &pb.Empty{}); err != nil {
+		t.Errorf("ListProducts: %v", err)
+	}
+
+	if _, err := admin.CreateProduct(ctx, &pb.CreateProductRequest{Product: gamma}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := catalog.index().get("G"); !ok {
+		t.Error("created product is not served")
+	}
+	_, err = admin.CreateProduct(ctx, &pb.CreateProductRequest{Product: gamma})
+	if got, want := status.Code(err), codes.AlreadyExists; got != want {
+		t.Errorf("creating a duplicate: got %s, want %s", got, want)
+	}
+
+	badPrice := &pb.Product{Id: "G", Name: "Gamma", PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 1, Nanos: -1}}
+	_, err = admin.UpdateProduct(ctx, &pb.UpdateProductRequest{Product: badPrice})
+	if got, want := status.Code(err), codes.InvalidArgument; got != want {
+		t.Errorf("updating with an invalid price: got %s, want %s", got, want)
+	}
+	_, err = admin.UpdateProduct(ctx, &pb.UpdateProductRequest{Product: &pb.Product{Id: "Z", Name: "Zed", PriceUsd: gamma.PriceUsd}})
+	if got, want := status.Code(err), codes.NotFound; got != want {
+		t.Errorf("updating a missing product: got %s, want %s", got, want)
+	}
+	renamed := proto.Clone(gamma).(*pb.Product)
+	renamed.Name = "Gamma Ray"
+	if _, err := admin.UpdateProduct(ctx, &pb.UpdateProductRequest{Product: renamed}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := admin.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = admin.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: "A"})
+	if got, want := status.Code(err), codes.NotFound; got != want {
+		t.Errorf("deleting a missing product: got %s, want %s", got, want)
+	}
+
+	// Changes are persisted: a fresh loader sees them.
+	reloaded := newCatalogLoader(fileSource{path: path})
+	if err := reloaded.load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := productIDs(reloaded.index().products); len(got) != 1 || got[0] != "G" {
+		t.Errorf("persisted catalog = %v, want [G]", got)
+	}
+	if p, _ := reloaded.index().get("G"); p.GetName() != "Gamma Ray" {
+		t.Errorf("persisted name = %q, want Gamma Ray", p.GetName())
+	}
+
+	// A bulk import with an invalid product changes nothing.
+	_, err = admin.BulkImport(ctx, &pb.BulkImportRequest{Products: []*pb.Product{
+		{Id: "H", Name: "Eta", PriceUsd: gamma.PriceUsd},
+		{Id: "I", Name: "", PriceUsd: gamma.PriceUsd},
+	}})
+	if got, want := status.Code(err), codes.InvalidArgument; got != want {
+		t.Errorf("importing an invalid product: got %s, want %s", got, want)
+	}
+	if n := len(catalog.index().products); n != 1 {
+		t.Errorf("serving %d products after a rejected import, want 1", n)
+	}
+
+	res, err := admin.BulkImport(ctx, &pb.BulkImportRequest{Products: []*pb.Product{
+		{Id: "H", Name: "Eta", PriceUsd: gamma.PriceUsd},
+		{Id: "I", Name: "Iota", PriceUsd: gamma.PriceUsd},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 2 || res.Updated != 0 || res.Deleted != 0 {
+		t.Errorf("BulkImport = %v, want 2 created", res)
+	}
+	res, err = admin.BulkImport(ctx, &pb.BulkImportRequest{Replace: true, Products: []*pb.Product{
+		{Id: "I", Name: "Iota", PriceUsd: gamma.PriceUsd},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 0 || res.Updated != 1 || res.Deleted != 2 {
+		t.Errorf("BulkImport with replace = %v, want 1 updated and 2 deleted", res)
+	}
+	if got := productIDs(catalog.index().products); len(got) != 1 || got[0] != "I" {
+		t.Errorf("catalog = %v, want [I]", got)
+	}
+}
+
+func TestCatalogAdminRejectsUnsafeProducts(t *testing.T) {
+	root := tempDir(t)
+	dir := filepath.Join(root, "catalog")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "A.json"), `{"id": "A", "name": "Alpha", "priceUsd": {"currencyCode": "USD", "units": 1}}`)
+	catalog := newCatalogLoader(dirSource{dir: dir})
+	if err := catalog.load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	admin, err := newCatalogAdmin(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []*pb.Product{
+		{Id: "../evil", Name: "Evil", PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 1}},
+		{Id: "E", Name: "Euro", PriceUsd: &pb.Money{CurrencyCode: "EUR", Units: 1}},
+	} {
+		_, err := admin.CreateProduct(context.Background(), &pb.CreateProductRequest{Product: p})
+		if got, want := status.Code(err), codes.InvalidArgument; got != want {
+			t.Errorf("CreateProduct(%v): got %s, want %s", p, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "evil.json")); !os.IsNotExist(err) {
+		t.Errorf("a product was written outside the catalog directory: %v", err)
+	}
+	if got := productIDs(catalog.index().products); len(got) != 1 || got[0] != "A" {
+		t.Errorf("catalog = %v, want [A]", got)
+	}
+}
+
+func TestCatalogAdminNeedsWritableSource(t *testing.T) {
+	if _, err := newCatalogAdmin(newCatalogLoader(httpSource{url: "http://catalog"})); err == nil {
+		t.Error("newCatalogAdmin accepted a read-only source")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// catalogIndex is an immutable, indexed view of the product catalog. A new
+// index is built on every reload and swapped in atomically, so readers never
+// take a lock and never see a half-built catalog.
+type catalogIndex struct {
+	// version identifies the catalog file contents the index was built
+	// from; it is empty if no catalog has been loaded.
+	version  string
+	loadedAt time.Time
+	// generation counts the catalogs served by a loader; it is 0 for the
+	// empty catalog served before the first load.
+	generation int64
+
+	products []*pb.Product
+	byID     map[string]*pb.Product
+	// byCategory maps every category to the positions in products of the
+	// products in it, in catalog order.
+	byCategory map[string][]int
+
+	// docs holds the per-product term statistics used for ranked search,
+	// in catalog order.
+	docs []indexedProduct
+	// postings maps every word of a name or description to the positions in
+	// docs of the products containing it.
+	postings map[string][]int
+	// vocabulary is the sorted set of postings keys, for prefix lookups.
+	vocabulary []string
+
+	avgNameLen, avgDescriptionLen float64
+}
+
+// indexedProduct holds the term frequencies of one product's searchable
+// fields.
+type indexedProduct struct {
+	product          *pb.Product
+	name             map[string]int
+	description      map[string]int
+	nameLen, descLen int
+}
+
+func newCatalogIndex(products []*pb.Product) *catalogIndex {
+	idx := &catalogIndex{
+		products:   products,
+		byID:       make(map[string]*pb.Product, len(products)),
+		byCategory: make(map[string][]int),
+		docs:       make([]indexedProduct, len(products)),
+		postings:   make(map[string][]int),
+	}
+	var nameLen, descLen int
+	for i, p := range products {
+		idx.byID[p.Id] = p
+		for _, c := range p.Categories {
+			idx.byCategory[c] = append(idx.byCategory[c], i)
+		}
+
+		name, description := tokenize(p.Name), tokenize(p.Description)
+		d := indexedProduct{
+			product:     p,
+			name:        termFrequencies(name),
+			description: termFrequencies(description),
+			nameLen:     len(name),
+			descLen:     len(description),
+		}
+		for t := range d.name {
+			idx.postings[t] = append(idx.postings[t], i)
+		}
+		for t := range d.description {
+			if _, ok := d.name[t]; !ok {
+				idx.postings[t] = append(idx.postings[t], i)
+			}
+		}
+		idx.docs[i] = d
+		nameLen += d.nameLen
+		descLen += d.descLen
+	}
+	for t := range idx.postings {
+		idx.vocabulary = append(idx.vocabulary, t)
+	}
+	sort.Strings(idx.vocabulary)
+	if n := len(products); n > 0 {
+		idx.avgNameLen = float64(nameLen) / float64(n)
+		idx.avgDescriptionLen = float64(descLen) / float64(n)
+	}
+	return idx
+}
+
+func termFrequencies(terms []string) map[string]int {
+	tf := make(map[string]int, len(terms))
+	for _, t := range terms {
+		tf[t]++
+	}
+	return tf
+}
+
+// tokenize splits s into lower-cased runs of letters and digits.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// emptyCatalog is served until a catalog has been loaded.
+var emptyCatalog = newCatalogIndex(nil)
+
+func (idx *catalogIndex) get(id string) (*pb.Product, bool) {
+	p, ok := idx.byID[id]
+	return p, ok
+}
+
+// inCategories returns the positions in products of the products in at
+// least one of categories.
+func (idx *catalogIndex) inCategories(categories []string) map[int]bool {
+	docs := make(map[int]bool)
+	for _, c := range categories {
+		for _, doc := range idx.byCategory[c] {
+			docs[doc] = true
+		}
+	}
+	return docs
+}
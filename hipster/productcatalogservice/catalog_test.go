@@ -0,0 +1,126 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+func productIDs(ps []*pb.Product) []string {
+	var out []string
+	for _, p := range ps {
+		out = append(out, p.Id)
+	}
+	return out
+}
+
+func TestCatalogIndex(t *testing.T) {
+	idx := newCatalogIndex([]*pb.Product{
+		{Id: "A", Name: "Vintage Typewriter", Description: "Looks good in your living room.", Categories: []string{"vintage"}},
+		{Id: "B", Name: "Camera Lens", Description: "A vintage lens, probably broken.", Categories: []string{"photography", "vintage"}},
+		{Id: "C", Name: "Salt & Pepper", Description: "Shakers for the kitchen.", Categories: []string{"kitchen"}},
+	})
+
+	if p, ok := idx.get("B"); !ok || p.Name != "Camera Lens" {
+		t.Errorf("get(B) = %v, %v", p, ok)
+	}
+	if _, ok := idx.get("Z"); ok {
+		t.Error("get(Z) found a product")
+	}
+	if got := idx.inCategories([]string{"vintage", "kitchen"}); len(got) != 3 {
+		t.Errorf("inCategories(vintage, kitchen) = %v, want [0 1 2]", got)
+	}
+	if got := idx.inCategories([]string{"photography", "garden"}); len(got) != 1 || !got[1] {
+		t.Errorf("inCategories(photography, garden) = %v, want [1]", got)
+	}
+}
+
+func benchmarkCatalog(n int) []*pb.Product {
+	ps := make([]*pb.Product, n)
+	for i := range ps {
+		ps[i] = &pb.Product{
+			Id:          fmt.Sprintf("P%06d", i),
+			Name:        fmt.Sprintf("Product %d", i),
+			Description: fmt.Sprintf("Description of product number %d.", i),
+			Categories:  []string{fmt.Sprintf("c%d", i%10)},
+		}
+	}
+	return ps
+}
+
+// linearGetProduct is GetProduct as it was before the index: a scan that
+// re-fetches the catalog on every comparison.
+func linearGetProduct(catalog func() []*pb.Product, id string) *pb.Product {
+	var found *pb.Product
+	for i := 0; i < len(catalog()); i++ {
+		if id == catalog()[i].Id {
+			found = catalog()[i]
+		}
+	}
+	return found
+}
+
+func BenchmarkGetProductLinear(b *testing.B) {
+	ps := benchmarkCatalog(1000)
+	catalog := func() []*pb.Product { return ps }
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if linearGetProduct(catalog, ps[n%len(ps)].Id) == nil {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkGetProductIndexed(b *testing.B) {
+	ps := benchmarkCatalog(1000)
+	idx := newCatalogIndex(ps)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, ok := idx.get(ps[n%len(ps)].Id); !ok {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkSearchLinear(b *testing.B) {
+	ps := benchmarkCatalog(1000)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var out []*pb.Product
+		for _, p := range ps {
+			if strings.Contains(strings.ToLower(p.Name), "999") ||
+				strings.Contains(strings.ToLower(p.Description), "999") {
+				out = append(out, p)
+			}
+		}
+		if len(out) != 1 {
+			b.Fatalf("got %d results", len(out))
+		}
+	}
+}
+
+func BenchmarkSearchIndexed(b *testing.B) {
+	idx := newCatalogIndex(benchmarkCatalog(1000))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if out := idx.search("999", nil); len(out) != 1 {
+			b.Fatalf("got %d results", len(out))
+		}
+	}
+}
@@ -6,10 +6,13 @@ require (
 	cloud.google.com/go v0.74.0
 	contrib.go.opencensus.io/exporter/jaeger v0.2.0
 	contrib.go.opencensus.io/exporter/stackdriver v0.5.0
+	github.com/GoogleCloudPlatform/microservices-demo/src/observability v0.0.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/golang/protobuf v1.4.3
 	github.com/google/go-cmp v0.5.4
 	github.com/google/uuid v1.1.2
 	github.com/konsorten/go-windows-terminal-sequences v1.0.2 // indirect
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/sirupsen/logrus v1.4.2
 	github.com/uber/jaeger-client-go v2.21.1+incompatible // indirect
 	go.opencensus.io v0.22.5
@@ -22,4 +25,7 @@ require (
 	go.opentelemetry.io/otel/sdk v0.15.0
 	golang.org/x/net v0.0.0-20201209123823-ac852fbbde11
 	google.golang.org/grpc v1.34.0
+	sigs.k8s.io/yaml v1.2.0
 )
+
+replace github.com/GoogleCloudPlatform/microservices-demo/src/observability => ../observability
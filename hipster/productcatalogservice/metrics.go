@@ -0,0 +1,77 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+)
+
+const instrumentationName = "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice"
+
+// registerCatalogMetrics reports the number of products served by catalog
+// as the catalog.products metric.
+func registerCatalogMetrics(catalog *catalogLoader) {
+	metric.Must(otel.Meter(instrumentationName)).NewInt64ValueObserver("catalog.products",
+		func(ctx context.Context, result metric.Int64ObserverResult) {
+			result.Observe(int64(len(catalog.index().products)))
+		},
+		metric.WithDescription("number of products in the catalog being served"))
+}
+
+// defaultMetricsPort is the port /metrics is served on when METRICS_PORT is
+// not set.
+const defaultMetricsPort = "9464"
+
+// initMetrics installs the global meter provider described by cfg and serves
+// the metrics to Prometheus on METRICS_PORT in the background. The returned
+// function stops serving them, pushes the last values and stops the
+// provider.
+func initMetrics(cfg observability.Config) (shutdown func(context.Context) error) {
+	handler, shutdownProvider, err := observability.SetupMetrics(context.Background(), cfg)
+	if err != nil {
+		log.WithError(err).Fatal("failed to initialize metrics")
+	}
+	if cfg.MetricsDisabled {
+		log.Info("metrics disabled")
+		return shutdownProvider
+	}
+
+	port := os.Getenv("METRICS_PORT")
+	if port == "" {
+		port = defaultMetricsPort
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	srv := &http.Server{Addr: ":" + port, Handler: mux}
+	log.Infof("serving metrics on :%s/metrics", port)
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.WithError(err).Error("metrics server stopped")
+		}
+	}()
+	return func(ctx context.Context) error {
+		if err := srv.Shutdown(ctx); err != nil {
+			return err
+		}
+		return shutdownProvider(ctx)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+)
+
+func TestCatalogMetrics(t *testing.T) {
+	handler, shutdown, err := observability.SetupMetrics(context.Background(), observability.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	catalog := newCatalogLoader(fileSource{path: "products.json"})
+	if err := catalog.load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	registerCatalogMetrics(catalog)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	want := fmt.Sprintf("} %d", len(catalog.index().products))
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, "catalog_products{") && strings.HasSuffix(line, want) {
+			return
+		}
+	}
+	t.Errorf("/metrics has no catalog_products sample ending in %q:\n%s", want, w.Body)
+}
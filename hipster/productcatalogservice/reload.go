@@ -0,0 +1,233 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+const (
+	// reloadDebounce is how long the watcher waits after the last change to
+	// a local catalog before reloading, so that an editor's
+	// write-rename-chmod sequence results in a single reload.
+	reloadDebounce = 200 * time.Millisecond
+
+	// defaultPollInterval is how often catalogs that cannot be watched, such
+	// as remote ones, are reloaded.
+	defaultPollInterval = 30 * time.Second
+)
+
+// catalogLoader loads the catalog from its source, validates it and swaps it
+// in. A catalog that fails to load or validate is rejected and the last good
+// one keeps being served.
+type catalogLoader struct {
+	source       CatalogSource
+	pollInterval time.Duration
+
+	current atomic.Value // *catalogIndex
+
+	mu          sync.Mutex // serializes loads and guards lastErr and subscribers
+	lastErr     error
+	subscribers map[chan *catalogIndex]bool
+}
+
+func newCatalogLoader(source CatalogSource) *catalogLoader {
+	return &catalogLoader{source: source, pollInterval: defaultPollInterval}
+}
+
+// pollIntervalFromEnv reads CATALOG_POLL_INTERVAL as a time.Duration.
+func pollIntervalFromEnv() (time.Duration, error) {
+	if s := os.Getenv("CATALOG_POLL_INTERVAL"); s != "" {
+		return time.ParseDuration(s)
+	}
+	return defaultPollInterval, nil
+}
+
+// index returns the catalog being served. It is empty until a catalog has
+// been loaded successfully.
+func (l *catalogLoader) index() *catalogIndex {
+	if idx, ok := l.current.Load().(*catalogIndex); ok {
+		return idx
+	}
+	return emptyCatalog
+}
+
+// load reads the catalog from the source and, if it is valid and differs
+// from the catalog being served, makes it current.
+func (l *catalogLoader) load(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx, err := l.read(ctx)
+	l.lastErr = err
+	if err != nil {
+		log.WithField("source", l.source.String()).WithError(err).Warn("rejected product catalog, keeping the previous version")
+		return err
+	}
+	prev := l.index()
+	if idx.version == prev.version {
+		return nil
+	}
+	idx.generation = prev.generation + 1
+	l.current.Store(idx)
+	l.notify(idx)
+	log.WithField("source", l.source.String()).WithField("version", idx.version).
+		WithField("products", len(idx.products)).Info("loaded product catalog")
+	return nil
+}
+
+func (l *catalogLoader) read(ctx context.Context) (*catalogIndex, error) {
+	products, err := l.source.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCatalog(products); err != nil {
+		return nil, err
+	}
+	// The version is a hash of the catalog's serialized form, so it only
+	// changes when the catalog does, whatever the source.
+	b, err := proto.Marshal(&pb.ListProductsResponse{Products: products})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	idx := newCatalogIndex(products)
+	idx.version = hex.EncodeToString(sum[:6])
+	idx.loadedAt = time.Now()
+	return idx, nil
+}
+
+// subscribe returns a channel that receives the served catalog every time it
+// changes. A slow subscriber only receives the latest catalog. cancel must be
+// called once the subscriber is done.
+func (l *catalogLoader) subscribe() (updates <-chan *catalogIndex, cancel func()) {
+	ch := make(chan *catalogIndex, 1)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.subscribers == nil {
+		l.subscribers = make(map[chan *catalogIndex]bool)
+	}
+	l.subscribers[ch] = true
+	return ch, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers, ch)
+	}
+}
+
+// notify hands idx to every subscriber, replacing any catalog it has not
+// received yet. l.mu must be held.
+func (l *catalogLoader) notify(idx *catalogIndex) {
+	for ch := range l.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- idx
+	}
+}
+
+// status returns the version of the catalog being served and the error of
+// the most recent load attempt, if it failed.
+func (l *catalogLoader) status() (version string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index().version, l.lastErr
+}
+
+// check is the health checker of the catalog: it fails until a catalog has
+// been loaded. A rejected reload does not make it fail, since the previous
+// catalog is still being served.
+func (l *catalogLoader) check(ctx context.Context) error {
+	version, err := l.status()
+	if version != "" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("no catalog loaded: %v", err)
+	}
+	return errors.New("no catalog loaded")
+}
+
+// watch reloads the catalog whenever it changes, until ctx is cancelled.
+// Local sources are watched for file system changes; other sources are
+// polled every pollInterval.
+func (l *catalogLoader) watch(ctx context.Context) error {
+	if src, ok := l.source.(localSource); ok {
+		return l.watchDir(ctx, src.watchDir())
+	}
+	t := time.NewTicker(l.pollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			l.load(ctx)
+		}
+	}
+}
+
+// watchDir reloads the catalog when anything in dir changes. The directory
+// rather than the file is watched so that atomic replacements, including
+// Kubernetes ConfigMap updates which swap a symlink, are noticed.
+func (l *catalogLoader) watchDir(ctx context.Context, dir string) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := w.Add(dir); err != nil {
+		return err
+	}
+
+	var timer *time.Timer
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			log.WithField("event", ev.String()).Debug("catalog directory changed")
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(reloadDebounce, func() { l.load(ctx) })
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			log.WithError(err).Warn("catalog watcher error")
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+func TestValidateCatalog(t *testing.T) {
+	usd := func(units int64, nanos int32) *pb.Money {
+		return &pb.Money{CurrencyCode: "USD", Units: units, Nanos: nanos}
+	}
+	for _, tc := range []struct {
+		name     string
+		products []*pb.Product
+		want     string // substring of the error, empty if valid
+	}{
+		{"valid", []*pb.Product{{Id: "A", Name: "a", PriceUsd: usd(1, 0)}, {Id: "B", Name: "b", PriceUsd: usd(0, 5)}}, ""},
+		{"duplicate id", []*pb.Product{{Id: "A", Name: "a", PriceUsd: usd(1, 0)}, {Id: "A", Name: "b", PriceUsd: usd(1, 0)}}, `duplicate id "A"`},
+		{"missing id", []*pb.Product{{Name: "a", PriceUsd: usd(1, 0)}}, "has no id"},
+		{"id with dashes", []*pb.Product{{Id: "mug_2-blue", Name: "a", PriceUsd: usd(1, 0)}}, ""},
+		{"id with a slash", []*pb.Product{{Id: "../A", Name: "a", PriceUsd: usd(1, 0)}}, `invalid id "../A"`},
+		{"id with a dot", []*pb.Product{{Id: "A.json", Name: "a", PriceUsd: usd(1, 0)}}, `invalid id "A.json"`},
+		{"id with a space", []*pb.Product{{Id: "A B", Name: "a", PriceUsd: usd(1, 0)}}, `invalid id "A B"`},
+		{"blank name", []*pb.Product{{Id: "A", Name: "  ", PriceUsd: usd(1, 0)}}, "has no name"},
+		{"no price", []*pb.Product{{Id: "A", Name: "a"}}, "has no price"},
+		{"no currency", []*pb.Product{{Id: "A", Name: "a", PriceUsd: &pb.Money{Units: 1}}}, "invalid price"},
+		{"not in USD", []*pb.Product{{Id: "A", Name: "a", PriceUsd: &pb.Money{CurrencyCode: "EUR", Units: 1}}}, "invalid price"},
+		{"sign mismatch", []*pb.Product{{Id: "A", Name: "a", PriceUsd: usd(1, -5)}}, "invalid price"},
+		{"nanos overflow", []*pb.Product{{Id: "A", Name: "a", PriceUsd: usd(1, 1e9)}}, "invalid price"},
+		{"negative", []*pb.Product{{Id: "A", Name: "a", PriceUsd: usd(-1, 0)}}, "invalid price"},
+	} {
+		err := validateCatalog(tc.products)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("%s: got error %v, want one containing %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+const (
+	testCatalogV1 = `{"products": [{"id": "A", "name": "Alpha", "priceUsd": {"currencyCode": "USD", "units": 1}}]}`
+	testCatalogV2 = `{"products": [{"id": "A", "name": "Alpha", "priceUsd": {"currencyCode": "USD", "units": 2}},
+	                               {"id": "B", "name": "Beta", "priceUsd": {"currencyCode": "USD", "units": 3}}]}`
+	testCatalogBad = `{"products": [{"id": "A", "name": "Alpha", "priceUsd": {"currencyCode": "USD", "units": 1}},
+	                                {"id": "A", "name": "Again", "priceUsd": {"currencyCode": "USD", "units": 1}}]}`
+)
+
+// newTestCatalog returns a loader for a temporary catalog file holding
+// contents, with the catalog loaded.
+func newTestCatalog(t *testing.T, contents string) (l *catalogLoader, path string) {
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path = filepath.Join(dir, "products.json")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l = newCatalogLoader(fileSource{path: path})
+	if err := l.load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return l, path
+}
+
+func TestCatalogLoaderKeepsLastGoodCatalog(t *testing.T) {
+	l, path := newTestCatalog(t, testCatalogV1)
+	v1, err := l.status()
+	if v1 == "" || err != nil {
+		t.Fatalf("status() = %q, %v", v1, err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(testCatalogBad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.load(context.Background()); err == nil {
+		t.Fatal("load() accepted a catalog with duplicate ids")
+	}
+	if v, err := l.status(); v != v1 || err == nil {
+		t.Errorf("status() = %q, %v; want %q and the load error", v, err, v1)
+	}
+	if n := len(l.index().products); n != 1 {
+		t.Errorf("serving %d products, want the last good catalog's 1", n)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.load(context.Background()); err == nil {
+		t.Fatal("load() accepted malformed JSON")
+	}
+	if v, _ := l.status(); v != v1 {
+		t.Errorf("version = %q after a parse error, want %q", v, v1)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(testCatalogV2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := l.status(); v == v1 || err != nil {
+		t.Errorf("status() = %q, %v; want a new version and no error", v, err)
+	}
+	if n := len(l.index().products); n != 2 {
+		t.Errorf("serving %d products, want 2", n)
+	}
+}
+
+func TestCatalogLoaderWatch(t *testing.T) {
+	l, path := newTestCatalog(t, testCatalogV1)
+	v1, _ := l.status()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.watch(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+	// Give the watcher a moment to register before changing the file.
+	time.Sleep(50 * time.Millisecond)
+
+	// Replace the file atomically, as an editor or a ConfigMap update would.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(testCatalogV2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if v, _ := l.status(); v != v1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("catalog was not reloaded after the file changed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, ok := l.index().get("B"); !ok {
+		t.Error("reloaded catalog does not contain product B")
+	}
+}
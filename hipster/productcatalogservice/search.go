@@ -0,0 +1,138 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// Search ranks products with BM25F: term frequencies from the name and the
+// description are length-normalised per field, weighted and summed before
+// BM25 saturation is applied.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	nameBoost        = 3.0
+	descriptionBoost = 1.0
+
+	// prefixMatchWeight discounts words that merely start with a query term,
+	// so that "camera" ranks above "cameras" for the query "camera".
+	prefixMatchWeight = 0.5
+)
+
+// search returns the products matching query, best match first. Every query
+// word matches index words it is a prefix of; a product matches if any query
+// word does. An empty query matches every product in catalog order. If
+// categories is not empty, only products in at least one of them are
+// returned.
+func (idx *catalogIndex) search(query string, categories []string) []*pb.Product {
+	// allowed holds the positions of the products in categories; it is nil
+	// when every product is allowed.
+	var allowed map[int]bool
+	if len(categories) > 0 {
+		allowed = idx.inCategories(categories)
+	}
+
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		if allowed == nil {
+			return append([]*pb.Product(nil), idx.products...)
+		}
+		docs := make([]int, 0, len(allowed))
+		for doc := range allowed {
+			docs = append(docs, doc)
+		}
+		sort.Ints(docs)
+		out := make([]*pb.Product, len(docs))
+		for i, doc := range docs {
+			out[i] = idx.products[doc]
+		}
+		return out
+	}
+
+	scores := make(map[int]float64)
+	for _, term := range terms {
+		// A document's score for a query term is its best-scoring expansion,
+		// so that short prefixes matching many words do not dominate.
+		best := make(map[int]float64)
+		for _, word := range idx.expand(term) {
+			weight := 1.0
+			if word != term {
+				weight = prefixMatchWeight
+			}
+			idf := idx.idf(word)
+			for _, doc := range idx.postings[word] {
+				if s := weight * idf * idx.saturatedTF(doc, word); s > best[doc] {
+					best[doc] = s
+				}
+			}
+		}
+		for doc, s := range best {
+			scores[doc] += s
+		}
+	}
+
+	docs := make([]int, 0, len(scores))
+	for doc := range scores {
+		if allowed == nil || allowed[doc] {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if si, sj := scores[docs[i]], scores[docs[j]]; si != sj {
+			return si > sj
+		}
+		return docs[i] < docs[j]
+	})
+	out := make([]*pb.Product, len(docs))
+	for i, doc := range docs {
+		out[i] = idx.docs[doc].product
+	}
+	return out
+}
+
+// expand returns the index words that start with prefix.
+func (idx *catalogIndex) expand(prefix string) []string {
+	i := sort.SearchStrings(idx.vocabulary, prefix)
+	j := i
+	for j < len(idx.vocabulary) && strings.HasPrefix(idx.vocabulary[j], prefix) {
+		j++
+	}
+	return idx.vocabulary[i:j]
+}
+
+func (idx *catalogIndex) idf(word string) float64 {
+	n, df := float64(len(idx.docs)), float64(len(idx.postings[word]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+func (idx *catalogIndex) saturatedTF(doc int, word string) float64 {
+	d := idx.docs[doc]
+	tf := nameBoost*normalizedTF(d.name[word], d.nameLen, idx.avgNameLen) +
+		descriptionBoost*normalizedTF(d.description[word], d.descLen, idx.avgDescriptionLen)
+	return tf * (bm25K1 + 1) / (tf + bm25K1)
+}
+
+func normalizedTF(tf, length int, avgLength float64) float64 {
+	if tf == 0 {
+		return 0
+	}
+	return float64(tf) / (1 - bm25B + bm25B*float64(length)/avgLength)
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+func TestSearch(t *testing.T) {
+	idx := newCatalogIndex([]*pb.Product{
+		{Id: "A", Name: "Camera Lens", Description: "You won't have a camera to use it.", Categories: []string{"photography", "vintage"}},
+		{Id: "B", Name: "Vintage Typewriter", Description: "Looks good next to your camera.", Categories: []string{"vintage"}},
+		{Id: "C", Name: "Film Cameras", Description: "Three cameras for the price of one.", Categories: []string{"photography"}},
+		{Id: "D", Name: "Salt & Pepper Shakers", Description: "Add some flavor to your kitchen.", Categories: []string{"kitchen"}},
+	})
+
+	for _, tc := range []struct {
+		query      string
+		categories []string
+		want       string
+	}{
+		// Name matches outrank description matches, even prefix ones, and
+		// exact words outrank prefix matches in the same field.
+		{"camera", nil, "A,C,B"},
+		{"CAMERAS", nil, "C"},
+		{"type", nil, "B"},
+		{"vintage typewriter", nil, "B"},
+		{"salt & pepper", nil, "D"},
+		{"kitchen camera", nil, "D,A,C,B"},
+		{"camera", []string{"photography"}, "A,C"},
+		{"camera", []string{"kitchen"}, ""},
+		{"", []string{"vintage"}, "A,B"},
+		{"", nil, "A,B,C,D"},
+		{"nothing", nil, ""},
+	} {
+		got := productIDs(idx.search(tc.query, tc.categories))
+		if strings.Join(got, ",") != tc.want {
+			t.Errorf("search(%q, %v) = %v, want %s", tc.query, tc.categories, got, tc.want)
+		}
+	}
+}
@@ -15,54 +15,38 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"net"
 	"os"
-	"os/signal"
+	"strconv"
 	"strings"
-	"sync"
-	"syscall"
 	"time"
 
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability/health"
 	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
-	"github.com/google/uuid"
-	"go.opentelemetry.io/contrib/detectors/gcp"
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp"
-	"go.opentelemetry.io/otel/exporters/stdout"
-	"go.opentelemetry.io/otel/exporters/trace/jaeger"
-	"go.opentelemetry.io/otel/label"
-	"go.opentelemetry.io/otel/propagation"
-	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
-	"go.opentelemetry.io/otel/sdk/resource"
-	"go.opentelemetry.io/otel/sdk/trace"
-	"go.opentelemetry.io/otel/semconv"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
-	"github.com/golang/protobuf/jsonpb"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+const (
+	defaultSearchPageSize = 10
+	maxSearchPageSize     = 100
+)
 
 var (
-	cat          pb.ListProductsResponse
-	catalogMutex *sync.Mutex
 	log          *logrus.Logger
 	extraLatency time.Duration
 
 	port = os.Getenv("PORT")
-
-	reloadCatalog bool
-	serviceName string
-  serviceNameSpace string
 )
 
 func init() {
@@ -76,101 +60,25 @@ func init() {
 		TimestampFormat: time.RFC3339Nano,
 	}
 	log.Out = os.Stdout
-	catalogMutex = &sync.Mutex{}
-	err := readCatalogFile(&cat)
-	if err != nil {
-		log.Warnf("could not parse product catalog")
-	}
-}
-
-func detectResource() (*resource.Resource, error) {
-	var instID label.KeyValue
-	if host, ok := os.LookupEnv("HOSTNAME"); ok && host != "" {
-		instID = semconv.ServiceInstanceIDKey.String(host)
-	} else {
-		instID = semconv.ServiceInstanceIDKey.String(uuid.New().String())
-	}
-    hostName:=os.Getenv("MY_POD_NAME")
-   hostIp:=os.Getenv("MY_POD_IP")
-   resourceType:=os.Getenv("RESOURCE_TYPE")
-   return resource.New(
-      context.Background(),
-      resource.WithAttributes(
-         instID,
-         semconv.ServiceNameKey.String(serviceName),
-         semconv.HostNameKey.String(hostName),
-         label.String("service.namespace",serviceNameSpace),
-         label.String("ip",hostIp),
-         label.String("resource.type",resourceType),
-      ),
-   )
+	log.AddHook(observability.LogHook{})
 }
 
-func spanExporter() (exporttrace.SpanExporter, error) {
-
-    var user = os.Getenv("JAEGER_USER")
-    var password = os.Getenv("JAEGER_PASSWORD")
-	export_type := os.Getenv("EXPORT_TYPE")
-	if export_type == "JAEGER" {
-		log.Info("exporting with JAEGER logger")
-		addr1 := os.Getenv("JAEGER_ENDPOINT")
-		return jaeger.NewRawExporter(
-			jaeger.WithCollectorEndpoint(addr1,jaeger.WithUsername(user),jaeger.WithPassword(password)),
-			jaeger.WithProcess(jaeger.Process{
-				ServiceName: serviceName,
-			}),
-		)
-	}
-	if export_type == "OTLP" {
-		log.Info("exporting with OTLP logger")
-		if os.Getenv("OTLP_ENDPOINT") != "" {
-			addr := os.Getenv("OTLP_ENDPOINT")
-			return otlp.NewExporter(
-				context.Background(),
-				otlp.WithInsecure(),
-				otlp.WithAddress(addr),
-			)
-		}
-	}
-
-	log.Info("exporting with STDOUT logger")
-	return stdout.NewExporter(
-		stdout.WithPrettyPrint(),
-		stdout.WithWriter(log.Writer()),
-	)
-}
-func initTracing() {
-	if os.Getenv("DISABLE_TRACING") != "" {
+// initTracing installs the global tracer provider described by cfg and
+// returns a function that flushes and stops it.
+func initTracing(cfg observability.Config) (shutdown func(context.Context) error) {
+	if cfg.Disabled {
 		log.Info("tracing disabled")
-		return
+	} else {
+		log.WithField("exporter", cfg.Exporter).Info("tracing enabled")
 	}
-
-	res, err := detectResource()
-	if err != nil {
-		log.WithError(err).Fatal("failed to detect environment resource")
+	if cfg.Exporter == "" || cfg.Exporter == observability.ExporterStdout {
+		cfg.StdoutWriter = log.Writer()
 	}
-
-	exp, err := spanExporter()
+	shutdown, err := observability.Setup(context.Background(), cfg)
 	if err != nil {
-		log.WithError(err).Fatal("failed to initialize Span exporter")
-		return
+		log.WithError(err).Fatal("failed to initialize tracing")
 	}
-
-	log.Info("tracing enabled")
-	otel.SetTracerProvider(
-		trace.NewTracerProvider(
-			trace.WithConfig(
-				trace.Config{
-					DefaultSampler: trace.AlwaysSample(),
-					Resource:       res,
-				},
-			),
-			trace.WithSpanProcessor(
-				trace.NewBatchSpanProcessor(exp),
-			),
-		),
-	)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return shutdown
 }
 
 type errorHandler struct {
@@ -183,13 +91,12 @@ func (eh errorHandler) Handle(err error) {
 
 func main() {
 
-    if serviceName=os.Getenv("SERVICE_NAME");serviceName==""{
-        serviceName = "Productcatalog-service"
-    }
-    if serviceNameSpace=os.Getenv("SERVICE_NAMESPACE");serviceNameSpace==""{
-         serviceNameSpace = "hipster"
-    }
-	initTracing()
+	telemetryConfig, err := observability.ConfigFromEnv("Productcatalog-service")
+	if err != nil {
+		log.WithError(err).Fatal("invalid telemetry configuration")
+	}
+	shutdownTracing := initTracing(telemetryConfig)
+	shutdownMetrics := initMetrics(telemetryConfig)
 	otel.SetErrorHandler(errorHandler{log: log})
 	flag.Parse()
 
@@ -205,19 +112,26 @@ func main() {
 		extraLatency = time.Duration(0)
 	}
 
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+	source, err := newCatalogSourceFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	catalog := newCatalogLoader(source)
+	registerCatalogMetrics(catalog)
+	if catalog.pollInterval, err = pollIntervalFromEnv(); err != nil {
+		log.Fatalf("failed to parse CATALOG_POLL_INTERVAL as time.Duration: %+v", err)
+	}
+	shutdownTimeout, err := observability.ShutdownTimeoutFromEnv()
+	if err != nil {
+		log.Fatalf("failed to parse SHUTDOWN_TIMEOUT as time.Duration: %+v", err)
+	}
+	// A catalog that fails to load is reported by the health check; the
+	// watcher keeps trying.
+	catalog.load(context.Background())
+	watchCtx, stopWatching := context.WithCancel(context.Background())
 	go func() {
-		for {
-			sig := <-sigs
-			log.Printf("Received signal: %s", sig)
-			if sig == syscall.SIGUSR1 {
-				reloadCatalog = true
-				log.Infof("Enable catalog reloading")
-			} else {
-				reloadCatalog = false
-				log.Infof("Disable catalog reloading")
-			}
+		if err := catalog.watch(watchCtx); err != nil {
+			log.WithError(err).Warn("catalog hot reloading disabled")
 		}
 	}()
 
@@ -225,11 +139,22 @@ func main() {
 		port = os.Getenv("PORT")
 	}
 	log.Infof("starting grpc server at :%s", port)
-	run(port)
-	select {}
+	_, stop := run(port, catalog, os.Getenv("ADMIN_TOKEN"))
+
+	observability.WaitForShutdownSignal(log)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	stop(ctx)
+	stopWatching()
+	observability.FlushTelemetry(log, shutdownTracing, shutdownMetrics)
+	log.Info("shutdown complete")
 }
 
-func run(port string) string {
+// run serves the catalog on port in the background and returns the address
+// it listens on. The admin service is only served when adminToken is set.
+// stop reports the service as not serving, ends catalog watches and waits
+// for in-flight RPCs until ctx expires.
+func run(port string, catalog *catalogLoader, adminToken string) (addr string, stop func(ctx context.Context)) {
 	l, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
 	if err != nil {
 		log.Fatal(err)
@@ -237,84 +162,134 @@ func run(port string) string {
 	var srv *grpc.Server
 
 	srv = grpc.NewServer(
-		grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
+		grpc.ChainUnaryInterceptor(append(observability.UnaryServerInterceptors(log), adminAuthInterceptor(adminToken))...),
+		grpc.ChainStreamInterceptor(observability.StreamServerInterceptors(log)...),
 	)
 
 	//srv = grpc.NewServer()
 
-	svc := &productCatalog{}
+	svc := &productCatalog{catalog: catalog, stopping: make(chan struct{})}
+	services := []string{"hipstershop.ProductCatalogService"}
 
 	pb.RegisterProductCatalogServiceServer(srv, svc)
+	if adminToken == "" {
+		log.Info("ADMIN_TOKEN not set, catalog admin service disabled")
+	} else if admin, err := newCatalogAdmin(catalog); err != nil {
+		log.WithError(err).Warn("catalog admin service disabled")
+	} else {
+		pb.RegisterProductCatalogAdminServiceServer(srv, admin)
+		services = append(services, "hipstershop.ProductCatalogAdminService")
+	}
+	svc.health = health.NewServer(services...)
+	svc.health.Register("catalog", catalog.check)
 	healthpb.RegisterHealthServer(srv, svc)
 	go srv.Serve(l)
-	return l.Addr().String()
+	return l.Addr().String(), func(ctx context.Context) {
+		svc.health.Shutdown()
+		close(svc.stopping)
+		observability.GracefulStop(ctx, log, srv)
+	}
 }
 
-type productCatalog struct{}
+type productCatalog struct {
+	catalog *catalogLoader
+	health  *health.Server
 
-func readCatalogFile(catalog *pb.ListProductsResponse) error {
-	catalogMutex.Lock()
-	defer catalogMutex.Unlock()
-	catalogJSON, err := ioutil.ReadFile("products.json")
-	if err != nil {
-		log.Fatalf("failed to open product catalog json file: %v", err)
-		return err
-	}
-	if err := jsonpb.Unmarshal(bytes.NewReader(catalogJSON), catalog); err != nil {
-		log.Warnf("failed to parse the catalog JSON: %v", err)
-		return err
-	}
-	log.Info("successfully parsed product catalog json")
-	return nil
+	// stopping is closed when the server shuts down, to end WatchCatalog
+	// streams which would otherwise hold up a graceful stop.
+	stopping chan struct{}
 }
 
-func parseCatalog() []*pb.Product {
-	if reloadCatalog || len(cat.Products) == 0 {
-		err := readCatalogFile(&cat)
-		if err != nil {
-			return []*pb.Product{}
-		}
+// Check reports the catalog version being served in the "catalog-version"
+// response header and, if the last reload was rejected, the reason in
+// "catalog-load-error". The service is not serving until a catalog loads.
+func (p *productCatalog) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	version, err := p.catalog.status()
+	md := metadata.Pairs("catalog-version", version)
+	if err != nil {
+		md.Append("catalog-load-error", headerSafe(err.Error()))
 	}
-	return cat.Products
+	grpc.SetHeader(ctx, md)
+	return p.health.Check(ctx, req)
 }
 
-func (p *productCatalog) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
-	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+// headerSafe replaces the characters that may not appear in a gRPC ASCII
+// header value.
+func headerSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '?'
+		}
+		return r
+	}, s)
 }
 
 func (p *productCatalog) Watch(req *healthpb.HealthCheckRequest, ws healthpb.Health_WatchServer) error {
-	return status.Errorf(codes.Unimplemented, "health check via Watch not implemented")
+	return p.health.Watch(req, ws)
 }
 
 func (p *productCatalog) ListProducts(context.Context, *pb.Empty) (*pb.ListProductsResponse, error) {
 	time.Sleep(extraLatency)
-	return &pb.ListProductsResponse{Products: parseCatalog()}, nil
+	return &pb.ListProductsResponse{Products: p.catalog.index().products}, nil
 }
 
 func (p *productCatalog) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
 	time.Sleep(extraLatency)
-	var found *pb.Product
-	for i := 0; i < len(parseCatalog()); i++ {
-		if req.Id == parseCatalog()[i].Id {
-			found = parseCatalog()[i]
-		}
-	}
-	if found == nil {
+	found, ok := p.catalog.index().get(req.Id)
+	if !ok {
 		return nil, status.Errorf(codes.NotFound, "no product with ID %s", req.Id)
 	}
 	return found, nil
 }
 
+func (p *productCatalog) GetProducts(ctx context.Context, req *pb.GetProductsRequest) (*pb.GetProductsResponse, error) {
+	time.Sleep(extraLatency)
+	idx := p.catalog.index()
+	resp := &pb.GetProductsResponse{}
+	seen := make(map[string]bool, len(req.Ids))
+	for _, id := range req.Ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if found, ok := idx.get(id); ok {
+			resp.Products = append(resp.Products, found)
+		} else {
+			resp.MissingIds = append(resp.MissingIds, id)
+		}
+	}
+	return resp, nil
+}
+
 func (p *productCatalog) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
 	time.Sleep(extraLatency)
-	// Intepret query as a substring match in name or description.
-	var ps []*pb.Product
-	for _, p := range parseCatalog() {
-		if strings.Contains(strings.ToLower(p.Name), strings.ToLower(req.Query)) ||
-			strings.Contains(strings.ToLower(p.Description), strings.ToLower(req.Query)) {
-			ps = append(ps, p)
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultSearchPageSize
+	} else if pageSize > maxSearchPageSize {
+		pageSize = maxSearchPageSize
+	}
+	// The page token is the offset of the first result of the page.
+	var offset int
+	if token := req.GetPageToken(); token != "" {
+		v, err := strconv.Atoi(token)
+		if err != nil || v < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token %q", token)
 		}
+		offset = v
+	}
+
+	results := p.catalog.index().search(req.GetQuery(), req.GetCategories())
+	resp := &pb.SearchProductsResponse{TotalSize: int32(len(results))}
+	if offset >= len(results) {
+		return resp, nil
+	}
+	end := offset + pageSize
+	if end < len(results) {
+		resp.NextPageToken = strconv.Itoa(end)
+	} else {
+		end = len(results)
 	}
-	return &pb.SearchProductsResponse{Results: ps}, nil
+	resp.Results = results[offset:end]
+	return resp, nil
 }
@@ -24,12 +24,20 @@ import (
 	"go.opencensus.io/plugin/ocgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 func TestServer(t *testing.T) {
 	ctx := context.Background()
-	addr := run(0)
+	catalog := newCatalogLoader(fileSource{path: "products.json"})
+	if err := catalog.load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	parseCatalog := func() []*pb.Product { return catalog.index().products }
+	addr, stop := run("0", catalog, "")
+	defer stop(context.Background())
 	conn, err := grpc.Dial(addr,
 		grpc.WithInsecure(),
 		grpc.WithStatsHandler(&ocgrpc.ClientHandler{}))
@@ -58,6 +66,17 @@ func TestServer(t *testing.T) {
 		t.Errorf("got %s, want %s", got, want)
 	}
 
+	gres, err := client.GetProducts(ctx, &pb.GetProductsRequest{Ids: []string{"66VCHSJNUP", "N/A", "OLJCESPC7Z", "66VCHSJNUP"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(gres.Products, []*pb.Product{parseCatalog()[1], parseCatalog()[0]}, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(gres.MissingIds, []string{"N/A"}); diff != "" {
+		t.Error(diff)
+	}
+
 	sres, err := client.SearchProducts(ctx, &pb.SearchProductsRequest{Query: "typewriter"})
 	if err != nil {
 		t.Fatal(err)
@@ -65,4 +84,72 @@ func TestServer(t *testing.T) {
 	if diff := cmp.Diff(sres.Results, []*pb.Product{parseCatalog()[0]}, cmp.Comparer(proto.Equal)); diff != "" {
 		t.Error(diff)
 	}
+
+	var paged []*pb.Product
+	req := &pb.SearchProductsRequest{PageSize: 4}
+	for {
+		sres, err := client.SearchProducts(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := int(sres.TotalSize), len(parseCatalog()); got != want {
+			t.Errorf("total_size = %d, want %d", got, want)
+		}
+		paged = append(paged, sres.Results...)
+		if sres.NextPageToken == "" {
+			break
+		}
+		req.PageToken = sres.NextPageToken
+	}
+	if diff := cmp.Diff(paged, parseCatalog(), cmp.Comparer(proto.Equal)); diff != "" {
+		t.Error(diff)
+	}
+	_, err = client.SearchProducts(ctx, &pb.SearchProductsRequest{PageToken: "x"})
+	if got, want := status.Code(err), codes.InvalidArgument; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	var md metadata.MD
+	hres, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hres.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health status = %s, want SERVING", hres.Status)
+	}
+	if v := md.Get("catalog-version"); len(v) != 1 || v[0] == "" {
+		t.Errorf("catalog-version header = %v, want the loaded catalog version", v)
+	}
+}
+
+func TestHealthWithoutCatalog(t *testing.T) {
+	catalog := newCatalogLoader(fileSource{path: "does-not-exist.json"})
+	catalog.load(context.Background())
+	addr, stop := run("0", catalog, "")
+	defer stop(context.Background())
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, service := range []string{"", "hipstershop.ProductCatalogService"} {
+		hres, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hres.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("health status of %q = %s, want NOT_SERVING", service, hres.Status)
+		}
+	}
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "hipstershop.ProductCatalogService"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hres, err := stream.Recv(); err != nil || hres.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("first watched status = %v, %v; want NOT_SERVING", hres, err)
+	}
 }
@@ -0,0 +1,282 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"sigs.k8s.io/yaml"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// CatalogSource supplies the complete product catalog. Load is called once at
+// startup and again on every reload; the products it returns are validated
+// before they are served.
+type CatalogSource interface {
+	Load(ctx context.Context) ([]*pb.Product, error)
+	// String describes the source for logs.
+	String() string
+}
+
+// catalogWriter is implemented by sources that can persist an edited
+// catalog. Save replaces the whole catalog with products.
+type catalogWriter interface {
+	Save(ctx context.Context, products []*pb.Product) error
+}
+
+// localSource is implemented by sources that live on the local file system.
+// The loader watches watchDir and reloads when anything in it changes;
+// other sources are polled.
+type localSource interface {
+	watchDir() string
+}
+
+// newCatalogSourceFromEnv builds the source selected by CATALOG_SOURCE
+// ("file", "dir", "sqlite" or "http") from the location in CATALOG_URI.
+// CATALOG_PATH is accepted as the location of a "file" source.
+func newCatalogSourceFromEnv() (CatalogSource, error) {
+	uri := os.Getenv("CATALOG_URI")
+	if uri == "" {
+		uri = os.Getenv("CATALOG_PATH")
+	}
+	return newCatalogSource(os.Getenv("CATALOG_SOURCE"), uri)
+}
+
+func newCatalogSource(kind, uri string) (CatalogSource, error) {
+	switch kind {
+	case "", "file":
+		if uri == "" {
+			uri = "products.json"
+		}
+		return fileSource{path: uri}, nil
+	case "dir":
+		if uri == "" {
+			return nil, fmt.Errorf("a dir catalog source needs CATALOG_URI")
+		}
+		return dirSource{dir: uri}, nil
+	case "sqlite":
+		if uri == "" {
+			uri = "products.db"
+		}
+		return newSQLiteSource(uri)
+	case "http":
+		if uri == "" {
+			return nil, fmt.Errorf("an http catalog source needs CATALOG_URI")
+		}
+		return httpSource{url: uri, client: &http.Client{Timeout: httpSourceTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown catalog source %q", kind)
+	}
+}
+
+// writeFileAtomic replaces path with b through a rename, so that readers and
+// file watchers never see a partially written file.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func unmarshalCatalog(b []byte) ([]*pb.Product, error) {
+	var c pb.ListProductsResponse
+	if err := jsonpb.Unmarshal(bytes.NewReader(b), &c); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog: %v", err)
+	}
+	return c.Products, nil
+}
+
+// fileSource reads the catalog from a single JSON file in the format of
+// products.json.
+type fileSource struct {
+	path string
+}
+
+func (s fileSource) Load(ctx context.Context) ([]*pb.Product, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %v", err)
+	}
+	return unmarshalCatalog(b)
+}
+
+func (s fileSource) Save(ctx context.Context, products []*pb.Product) error {
+	var buf bytes.Buffer
+	m := jsonpb.Marshaler{Indent: "    "}
+	if err := m.Marshal(&buf, &pb.ListProductsResponse{Products: products}); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, buf.Bytes())
+}
+
+func (s fileSource) String() string   { return "file:" + s.path }
+func (s fileSource) watchDir() string { return filepath.Dir(s.path) }
+
+// dirSource reads one product per file from a directory. Files ending in
+// .json hold a Product in its JSON form; files ending in .yaml or .yml hold
+// the same fields as YAML. Other files are ignored. Products are ordered by
+// file name.
+type dirSource struct {
+	dir string
+}
+
+func (s dirSource) Load(ctx context.Context) ([]*pb.Product, error) {
+	products, _, err := s.read()
+	return products, err
+}
+
+// read returns the products in the directory along with the name of the
+// file each one was read from.
+func (s dirSource) read() ([]*pb.Product, []string, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read catalog directory: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".json", ".yaml", ".yml":
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	products := make([]*pb.Product, 0, len(names))
+	for _, name := range names {
+		b, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		if isYAML(name) {
+			if b, err = yaml.YAMLToJSON(b); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse %s: %v", name, err)
+			}
+		}
+		var p pb.Product
+		if err := jsonpb.Unmarshal(bytes.NewReader(b), &p); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %v", name, err)
+		}
+		products = append(products, &p)
+	}
+	return products, names, nil
+}
+
+// Save writes every product back to the file it was read from, in the same
+// format, writes new products to "<id>.json" and removes the files of
+// products that are gone.
+func (s dirSource) Save(ctx context.Context, products []*pb.Product) error {
+	old, names, err := s.read()
+	if err != nil {
+		return err
+	}
+	files := make(map[string]string, len(old))
+	for i, p := range old {
+		files[p.GetId()] = names[i]
+	}
+
+	m := jsonpb.Marshaler{Indent: "  "}
+	keep := make(map[string]bool, len(products))
+	for _, p := range products {
+		name, ok := files[p.GetId()]
+		if !ok {
+			name = p.GetId() + ".json"
+		}
+		keep[name] = true
+
+		var buf bytes.Buffer
+		if err := m.Marshal(&buf, p); err != nil {
+			return err
+		}
+		b := buf.Bytes()
+		if isYAML(name) {
+			if b, err = yaml.JSONToYAML(b); err != nil {
+				return err
+			}
+		}
+		if err := writeFileAtomic(filepath.Join(s.dir, name), b); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		if !keep[name] {
+			if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isYAML(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func (s dirSource) String() string   { return "dir:" + s.dir }
+func (s dirSource) watchDir() string { return s.dir }
+
+// httpSourceTimeout bounds a single fetch of a remote catalog.
+const httpSourceTimeout = 10 * time.Second
+
+// httpSource fetches the catalog, in the format of products.json, from a URL.
+type httpSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s httpSource) Load(ctx context.Context) ([]*pb.Product, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch catalog: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch catalog: %s", resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch catalog: %v", err)
+	}
+	return unmarshalCatalog(b)
+}
+
+func (s httpSource) String() string { return s.url }
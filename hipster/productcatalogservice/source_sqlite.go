@@ -0,0 +1,143 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// sqliteProductsSchema is the table a sqlite catalog is read from. Categories
+// are stored comma-separated; products are served in rowid order.
+const sqliteProductsSchema = `
+CREATE TABLE IF NOT EXISTS products (
+	id                  TEXT PRIMARY KEY,
+	name                TEXT NOT NULL,
+	description         TEXT NOT NULL DEFAULT '',
+	picture             TEXT NOT NULL DEFAULT '',
+	price_currency_code TEXT NOT NULL,
+	price_units         INTEGER NOT NULL,
+	price_nanos         INTEGER NOT NULL DEFAULT 0,
+	categories          TEXT NOT NULL DEFAULT ''
+);
+`
+
+// sqliteSource reads the catalog from the products table of a SQLite
+// database. The database is opened for each load so that changes made by
+// other processes are picked up.
+type sqliteSource struct {
+	path string
+}
+
+// newSQLiteSource returns the source for the database at path, creating the
+// products table if it does not exist yet. The database file itself must
+// exist: a missing file more likely means a volume that failed to mount than
+// an empty catalog.
+func newSQLiteSource(path string) (sqliteSource, error) {
+	s := sqliteSource{path: path}
+	db, err := s.open()
+	if err != nil {
+		return sqliteSource{}, err
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqliteProductsSchema); err != nil {
+		return sqliteSource{}, fmt.Errorf("failed to create catalog schema: %v", err)
+	}
+	return s, nil
+}
+
+// open opens the database, which must exist.
+func (s sqliteSource) open() (*sql.DB, error) {
+	if _, err := os.Stat(s.path); err != nil {
+		return nil, fmt.Errorf("failed to open catalog database: %v", err)
+	}
+	db, err := sql.Open("sqlite3", s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open catalog database %s: %v", s.path, err)
+	}
+	return db, nil
+}
+
+func (s sqliteSource) Load(ctx context.Context) ([]*pb.Product, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `SELECT id, name, description, picture,
+		price_currency_code, price_units, price_nanos, categories
+		FROM products ORDER BY rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query catalog: %v", err)
+	}
+	defer rows.Close()
+
+	var products []*pb.Product
+	for rows.Next() {
+		p := &pb.Product{PriceUsd: new(pb.Money)}
+		var categories string
+		if err := rows.Scan(&p.Id, &p.Name, &p.Description, &p.Picture,
+			&p.PriceUsd.CurrencyCode, &p.PriceUsd.Units, &p.PriceUsd.Nanos, &categories); err != nil {
+			return nil, fmt.Errorf("failed to read catalog: %v", err)
+		}
+		if categories != "" {
+			p.Categories = strings.Split(categories, ",")
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %v", err)
+	}
+	return products, nil
+}
+
+func (s sqliteSource) Save(ctx context.Context, products []*pb.Product) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM products`); err != nil {
+		return fmt.Errorf("failed to save catalog: %v", err)
+	}
+	for _, p := range products {
+		_, err := tx.ExecContext(ctx, `INSERT INTO products (id, name, description, picture,
+			price_currency_code, price_units, price_nanos, categories)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			p.GetId(), p.GetName(), p.GetDescription(), p.GetPicture(),
+			p.GetPriceUsd().GetCurrencyCode(), p.GetPriceUsd().GetUnits(), p.GetPriceUsd().GetNanos(),
+			strings.Join(p.GetCategories(), ","))
+		if err != nil {
+			return fmt.Errorf("failed to save product %q: %v", p.GetId(), err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s sqliteSource) String() string { return "sqlite:" + s.path }
@@ -0,0 +1,216 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+var sourceTestProducts = []*pb.Product{
+	{Id: "A", Name: "Alpha", Description: "First.", Picture: "/a.jpg",
+		PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 1, Nanos: 500000000}, Categories: []string{"tools", "garden"}},
+	{Id: "B", Name: "Beta", PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 2}},
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkSource(t *testing.T, src CatalogSource) {
+	got, err := src.Load(context.Background())
+	if err != nil {
+		t.Fatalf("%s: %v", src, err)
+	}
+	if diff := cmp.Diff(got, sourceTestProducts, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("%s: %s", src, diff)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(tempDir(t), "products.json")
+	writeFile(t, path, `{"products": [
+		{"id": "A", "name": "Alpha", "description": "First.", "picture": "/a.jpg",
+		 "priceUsd": {"currencyCode": "USD", "units": 1, "nanos": 500000000}, "categories": ["tools", "garden"]},
+		{"id": "B", "name": "Beta", "priceUsd": {"currencyCode": "USD", "units": 2}}]}`)
+	checkSource(t, fileSource{path: path})
+
+	if _, err := (fileSource{path: path + ".missing"}).Load(context.Background()); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := tempDir(t)
+	writeFile(t, filepath.Join(dir, "01-alpha.yaml"), `
+id: A
+name: Alpha
+description: First.
+picture: /a.jpg
+priceUsd:
+  currencyCode: USD
+  units: 1
+  nanos: 500000000
+categories: [tools, garden]
+`)
+	writeFile(t, filepath.Join(dir, "02-beta.json"), `{"id": "B", "name": "Beta", "priceUsd": {"currencyCode": "USD", "units": 2}}`)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a product")
+	checkSource(t, dirSource{dir: dir})
+
+	writeFile(t, filepath.Join(dir, "03-broken.yml"), "id: [")
+	if _, err := (dirSource{dir: dir}).Load(context.Background()); err == nil {
+		t.Error("loading a directory with a malformed file succeeded")
+	}
+}
+
+// newTestSQLiteSource returns a source for a new, empty database.
+func newTestSQLiteSource(t *testing.T) sqliteSource {
+	path := filepath.Join(tempDir(t), "products.db")
+	writeFile(t, path, "")
+	src, err := newSQLiteSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestSQLiteSource(t *testing.T) {
+	src := newTestSQLiteSource(t)
+	db, err := src.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`INSERT INTO products (id, name, description, picture, price_currency_code, price_units, price_nanos, categories)
+		VALUES ('A', 'Alpha', 'First.', '/a.jpg', 'USD', 1, 500000000, 'tools,garden'),
+		       ('B', 'Beta', '', '', 'USD', 2, 0, '')`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSource(t, src)
+
+	// A database that disappears, as when its volume is not mounted, fails
+	// to load rather than loading as an empty catalog.
+	if err := os.Remove(src.path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Load(context.Background()); err == nil {
+		t.Error("loading a missing database succeeded")
+	}
+	if _, err := os.Stat(src.path); !os.IsNotExist(err) {
+		t.Errorf("loading a missing database created it: %v", err)
+	}
+	if _, err := newSQLiteSource(src.path); err == nil {
+		t.Error("newSQLiteSource() of a missing database succeeded")
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	var status = http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"products": [
+			{"id": "A", "name": "Alpha", "description": "First.", "picture": "/a.jpg",
+			 "priceUsd": {"currencyCode": "USD", "units": 1, "nanos": 500000000}, "categories": ["tools", "garden"]},
+			{"id": "B", "name": "Beta", "priceUsd": {"currencyCode": "USD", "units": 2}}]}`))
+	}))
+	defer srv.Close()
+
+	src, err := newCatalogSource("http", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSource(t, src)
+
+	status = http.StatusServiceUnavailable
+	if _, err := src.Load(context.Background()); err == nil {
+		t.Error("loading from a failing server succeeded")
+	}
+}
+
+func TestNewCatalogSource(t *testing.T) {
+	for _, tc := range []struct {
+		kind, uri string
+		want      string
+	}{
+		{"", "", "file:products.json"},
+		{"file", "/etc/catalog.json", "file:/etc/catalog.json"},
+		{"dir", "/etc/catalog", "dir:/etc/catalog"},
+		{"http", "http://catalog/products.json", "http://catalog/products.json"},
+	} {
+		src, err := newCatalogSource(tc.kind, tc.uri)
+		if err != nil {
+			t.Errorf("newCatalogSource(%q, %q): %v", tc.kind, tc.uri, err)
+		} else if src.String() != tc.want {
+			t.Errorf("newCatalogSource(%q, %q) = %s, want %s", tc.kind, tc.uri, src, tc.want)
+		}
+	}
+	// products.db does not exist, and a sqlite database must.
+	for _, kind := range []string{"dir", "sqlite", "http", "ftp"} {
+		if _, err := newCatalogSource(kind, ""); err == nil {
+			t.Errorf("newCatalogSource(%q, \"\") succeeded", kind)
+		}
+	}
+}
+
+func TestSourceSave(t *testing.T) {
+	dir := tempDir(t)
+	writeFile(t, filepath.Join(dir, "old.yaml"), "id: OLD\nname: Old\npriceUsd: {currencyCode: USD, units: 1}\n")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "id: B\nname: Bee\npriceUsd: {currencyCode: USD, units: 1}\n")
+
+	for _, src := range []CatalogSource{
+		fileSource{path: filepath.Join(tempDir(t), "products.json")},
+		dirSource{dir: dir},
+		newTestSQLiteSource(t),
+	} {
+		if err := src.(catalogWriter).Save(context.Background(), sourceTestProducts); err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		checkSource(t, src)
+	}
+
+	// The directory source rewrites existing files in place and removes the
+	// files of deleted products.
+	if _, err := os.Stat(filepath.Join(dir, "b.yaml")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "A.json")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.yaml")); !os.IsNotExist(err) {
+		t.Errorf("old.yaml was not removed: %v", err)
+	}
+}
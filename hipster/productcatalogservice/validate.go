@@ -0,0 +1,66 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/money"
+)
+
+// validID matches the product IDs a catalog may hold. IDs name files in a
+// dirSource, so they must not contain path separators or dots.
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// catalogErrors lists every problem found in a catalog.
+type catalogErrors []string
+
+func (e catalogErrors) Error() string {
+	return "invalid catalog: " + strings.Join(e, "; ")
+}
+
+// validateCatalog checks that every product has a unique, non-empty ID made
+// of letters, digits, underscores and dashes, a non-empty name and a valid
+// price in USD.
+func validateCatalog(products []*pb.Product) error {
+	var errs catalogErrors
+	seen := make(map[string]bool, len(products))
+	for i, p := range products {
+		if p.GetId() == "" {
+			errs = append(errs, fmt.Sprintf("product #%d has no id", i))
+		} else if !validID.MatchString(p.GetId()) {
+			errs = append(errs, fmt.Sprintf("product #%d has invalid id %q", i, p.GetId()))
+		} else if seen[p.GetId()] {
+			errs = append(errs, fmt.Sprintf("product #%d has duplicate id %q", i, p.GetId()))
+		}
+		seen[p.GetId()] = true
+
+		if strings.TrimSpace(p.GetName()) == "" {
+			errs = append(errs, fmt.Sprintf("product %q has no name", p.GetId()))
+		}
+		if price := p.GetPriceUsd(); price == nil {
+			errs = append(errs, fmt.Sprintf("product %q has no price", p.GetId()))
+		} else if price.GetCurrencyCode() != "USD" || !money.IsValid(*price) || money.IsNegative(*price) {
+			errs = append(errs, fmt.Sprintf("product %q has invalid price %v", p.GetId(), price))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
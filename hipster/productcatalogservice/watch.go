@@ -0,0 +1,88 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+func (p *productCatalog) WatchCatalog(req *pb.WatchCatalogRequest, stream pb.ProductCatalogService_WatchCatalogServer) error {
+	// Subscribe before reading the served catalog so that no change between
+	// the two is missed.
+	updates, cancel := p.catalog.subscribe()
+	defer cancel()
+
+	// The first diff, against the empty catalog, is the snapshot.
+	prev, idx := (*catalogIndex)(nil), p.catalog.index()
+	for {
+		if err := sendCatalogDiff(stream, prev, idx); err != nil {
+			return err
+		}
+		prev = idx
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-p.stopping:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case idx = <-updates:
+		}
+	}
+}
+
+// catalogDiff returns the events that turn prev into next, followed by a
+// SYNCED event, all tagged with next's generation.
+func catalogDiff(prev, next *catalogIndex) []*pb.CatalogEvent {
+	var events []*pb.CatalogEvent
+	for _, p := range next.products {
+		old, ok := prev.get(p.Id)
+		switch {
+		case !ok:
+			events = append(events, &pb.CatalogEvent{Type: pb.CatalogEvent_ADDED, Product: p})
+		case !proto.Equal(old, p):
+			events = append(events, &pb.CatalogEvent{Type: pb.CatalogEvent_UPDATED, Product: p})
+		}
+	}
+	for _, p := range prev.products {
+		if _, ok := next.get(p.Id); !ok {
+			events = append(events, &pb.CatalogEvent{Type: pb.CatalogEvent_REMOVED, Product: &pb.Product{Id: p.Id}})
+		}
+	}
+	events = append(events, &pb.CatalogEvent{Type: pb.CatalogEvent_SYNCED})
+	for _, e := range events {
+		e.Version = next.generation
+	}
+	return events
+}
+
+// sendCatalogDiff sends the events that turn prev into next. A nil prev
+// stands for the empty catalog.
+func sendCatalogDiff(stream pb.ProductCatalogService_WatchCatalogServer, prev, next *catalogIndex) error {
+	if prev == next {
+		return nil
+	}
+	if prev == nil {
+		prev = emptyCatalog
+	}
+	for _, e := range catalogDiff(prev, next) {
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// recvUntilSynced reads events up to and including the next SYNCED one and
+// returns them as "TYPE id@version" strings.
+func recvUntilSynced(t *testing.T, stream pb.ProductCatalogService_WatchCatalogClient) string {
+	t.Helper()
+	var events []string
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, fmt.Sprintf("%s %s@%d", e.GetType(), e.GetProduct().GetId(), e.GetVersion()))
+		if e.GetType() == pb.CatalogEvent_SYNCED {
+			return strings.Join(events, ", ")
+		}
+	}
+}
+
+func TestWatchCatalog(t *testing.T) {
+	catalog, path := newTestCatalog(t, testCatalogV1)
+	addr, stop := run("0", catalog, "")
+	defer stop(context.Background())
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewProductCatalogServiceClient(conn).WatchCatalog(ctx, &pb.WatchCatalogRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recvUntilSynced(t, stream), "ADDED A@1, SYNCED @1"; got != want {
+		t.Errorf("snapshot: got %q, want %q", got, want)
+	}
+
+	for _, step := range []struct {
+		contents, want string
+	}{
+		{testCatalogV2, "UPDATED A@2, ADDED B@2, SYNCED @2"},
+		{testCatalogBad, ""},
+		{testCatalogV1, "UPDATED A@3, REMOVED B@3, SYNCED @3"},
+	} {
+		if err := ioutil.WriteFile(path, []byte(step.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := catalog.load(context.Background()); err != nil {
+			// A rejected catalog is not announced; the next event
+			// belongs to the following step.
+			continue
+		}
+		if got := recvUntilSynced(t, stream); got != step.want {
+			t.Errorf("after reload: got %q, want %q", got, step.want)
+		}
+	}
+}
+
+func TestWatchCatalogEndsOnShutdown(t *testing.T) {
+	catalog, _ := newTestCatalog(t, testCatalogV1)
+	addr, stop := run("0", catalog, "")
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stream, err := pb.NewProductCatalogServiceClient(conn).WatchCatalog(context.Background(), &pb.WatchCatalogRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recvUntilSynced(t, stream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stop(ctx)
+	if ctx.Err() != nil {
+		t.Error("the watch held up the graceful stop")
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Recv after shutdown: got %v, want Unavailable", err)
+	}
+}
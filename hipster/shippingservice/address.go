@@ -0,0 +1,211 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+// maxAddressFieldLength bounds the free-form fields of an address.
+const maxAddressFieldLength = 200
+
+// country is a destination and the rules its addresses follow. See
+// address_data.go for the countries the shop ships to.
+type country struct {
+	// Code is the ISO 3166-1 alpha-2 code, which addresses are normalized
+	// to.
+	Code    string
+	Name    string
+	Aliases []string
+
+	// Postal matches a postal code in upper case with spaces and dashes
+	// removed. Its non-empty groups joined by PostalSep are the normalized
+	// code. Countries without Postal have no postal codes.
+	Postal        *regexp.Regexp
+	PostalSep     string
+	PostalExample string
+
+	// States lists the states or provinces of countries whose addresses
+	// require one.
+	States []region
+}
+
+// region is a state or province and its postal abbreviation.
+type region struct {
+	Code string
+	Name string
+}
+
+// countriesByName indexes countries by their code, name and aliases in
+// lower case.
+var countriesByName = func() map[string]*country {
+	m := make(map[string]*country)
+	for i := range countries {
+		c := &countries[i]
+		for _, name := range append([]string{c.Code, c.Name}, c.Aliases...) {
+			m[strings.ToLower(name)] = c
+		}
+	}
+	return m
+}()
+
+func lookupCountry(name string) (*country, bool) {
+	c, ok := countriesByName[strings.ToLower(name)]
+	return c, ok
+}
+
+// state returns the code of the state named s by its code or name.
+func (c *country) state(s string) (string, bool) {
+	for _, r := range c.States {
+		if strings.EqualFold(s, r.Code) || strings.EqualFold(s, r.Name) {
+			return r.Code, true
+		}
+	}
+	return "", false
+}
+
+// postalCode returns s in the country's canonical form, as in "K1A 0B1" for
+// "k1a0b1".
+func (c *country) postalCode(s string) (string, bool) {
+	m := c.Postal.FindStringSubmatch(compactPostalCode(s))
+	if m == nil {
+		return "", false
+	}
+	var parts []string
+	for _, g := range m[1:] {
+		if g != "" {
+			parts = append(parts, g)
+		}
+	}
+	return strings.Join(parts, c.PostalSep), true
+}
+
+func compactPostalCode(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.ToUpper(s))
+}
+
+// legacyPostalCode spells out the zip_code of clients that predate
+// postal_code, restoring the leading zeros an integer drops.
+func (c *country) legacyPostalCode(zip int32) string {
+	return fmt.Sprintf("%0*d", len(compactPostalCode(c.PostalExample)), zip)
+}
+
+// normalizeAddress checks that addr is complete and well-formed for its
+// country and returns it in canonical form: whitespace collapsed, the
+// country as its ISO code, the state as its abbreviation where the country
+// has them and the postal code formatted the way the country writes it.
+// Otherwise it returns a violation for each field in error.
+func normalizeAddress(addr *pb.Address) (*pb.Address, []*errdetails.BadRequest_FieldViolation) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	violate := func(field, format string, args ...interface{}) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fmt.Sprintf(format, args...),
+		})
+	}
+	if addr == nil {
+		violate("address", "address is required")
+		return nil, violations
+	}
+
+	out := &pb.Address{
+		StreetAddress: collapseSpace(addr.GetStreetAddress()),
+		City:          collapseSpace(addr.GetCity()),
+		State:         collapseSpace(addr.GetState()),
+	}
+	for _, f := range []struct{ field, name, value string }{
+		{"address.street_address", "street address", out.StreetAddress},
+		{"address.city", "city", out.City},
+	} {
+		if f.value == "" {
+			violate(f.field, "%s is required", f.name)
+		} else if len(f.value) > maxAddressFieldLength {
+			violate(f.field, "%s must be at most %d characters", f.name, maxAddressFieldLength)
+		}
+	}
+
+	name := collapseSpace(addr.GetCountry())
+	c, ok := lookupCountry(name)
+	if name == "" {
+		violate("address.country", "country is required")
+	} else if !ok {
+		violate("address.country", "no shipping to %q", name)
+	}
+	if !ok {
+		return nil, violations
+	}
+	out.Country = c.Code
+
+	if len(c.States) > 0 {
+		if out.State == "" {
+			violate("address.state", "state is required in %s", c.Name)
+		} else if code, ok := c.state(out.State); !ok {
+			violate("address.state", "unknown state %q in %s", out.State, c.Name)
+		} else {
+			out.State = code
+		}
+	} else if len(out.State) > maxAddressFieldLength {
+		violate("address.state", "state must be at most %d characters", maxAddressFieldLength)
+	}
+
+	if c.Postal != nil {
+		postal := collapseSpace(addr.GetPostalCode())
+		if postal == "" && addr.GetZipCode() != 0 {
+			postal = c.legacyPostalCode(addr.GetZipCode())
+		}
+		if postal == "" {
+			violate("address.postal_code", "postal code is required in %s", c.Name)
+		} else if code, ok := c.postalCode(postal); !ok {
+			violate("address.postal_code", "postal code must look like %s in %s", c.PostalExample, c.Name)
+		} else {
+			out.PostalCode = code
+		}
+		// Keep zip_code set for clients that still read it.
+		if zip, err := strconv.ParseInt(out.PostalCode, 10, 32); err == nil {
+			out.ZipCode = int32(zip)
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, violations
+	}
+	return out, nil
+}
+
+func collapseSpace(s string) string { return strings.Join(strings.Fields(s), " ") }
+
+// invalidAddressError returns an InvalidArgument status whose details hold
+// the field violations as an errdetails.BadRequest, for clients to show
+// next to their form fields.
+func invalidAddressError(violations []*errdetails.BadRequest_FieldViolation) error {
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		msgs[i] = v.GetDescription()
+	}
+	st := status.New(codes.InvalidArgument, "invalid address: "+strings.Join(msgs, "; "))
+	if withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "regexp"
+
+// countries are the destinations the shop ships to. Add a country here
+// before adding it to a rate card zone.
+var countries = []country{
+	{Code: "US", Name: "United States", Aliases: []string{"USA", "United States of America", "America"},
+		Postal: regexp.MustCompile(`^(\d{5})(\d{4})?$`), PostalSep: "-", PostalExample: "94043",
+		States: []region{
+			{"AL", "Alabama"}, {"AK", "Alaska"}, {"AZ", "Arizona"}, {"AR", "Arkansas"},
+			{"CA", "California"}, {"CO", "Colorado"}, {"CT", "Connecticut"}, {"DE", "Delaware"},
+			{"DC", "District of Columbia"}, {"FL", "Florida"}, {"GA", "Georgia"}, {"HI", "Hawaii"},
+			{"ID", "Idaho"}, {"IL", "Illinois"}, {"IN", "Indiana"}, {"IA", "Iowa"},
+			{"KS", "Kansas"}, {"KY", "Kentucky"}, {"LA", "Louisiana"}, {"ME", "Maine"},
+			{"MD", "Maryland"}, {"MA", "Massachusetts"}, {"MI", "Michigan"}, {"MN", "Minnesota"},
+			{"MS", "Mississippi"}, {"MO", "Missouri"}, {"MT", "Montana"}, {"NE", "Nebraska"},
+			{"NV", "Nevada"}, {"NH", "New Hampshire"}, {"NJ", "New Jersey"}, {"NM", "New Mexico"},
+			{"NY", "New York"}, {"NC", "North Carolina"}, {"ND", "North Dakota"}, {"OH", "Ohio"},
+			{"OK", "Oklahoma"}, {"OR", "Oregon"}, {"PA", "Pennsylvania"}, {"RI", "Rhode Island"},
+			{"SC", "South Carolina"}, {"SD", "South Dakota"}, {"TN", "Tennessee"}, {"TX", "Texas"},
+			{"UT", "Utah"}, {"VT", "Vermont"}, {"VA", "Virginia"}, {"WA", "Washington"},
+			{"WV", "West Virginia"}, {"WI", "Wisconsin"}, {"WY", "Wyoming"},
+			{"AS", "American Samoa"}, {"GU", "Guam"}, {"MP", "Northern Mariana Islands"},
+			{"PR", "Puerto Rico"}, {"VI", "U.S. Virgin Islands"},
+		}},
+	{Code: "CA", Name: "Canada",
+		Postal: regexp.MustCompile(`^([A-Z]\d[A-Z])(\d[A-Z]\d)$`), PostalSep: " ", PostalExample: "K1A 0B1",
+		States: []region{
+			{"AB", "Alberta"}, {"BC", "British Columbia"}, {"MB", "Manitoba"}, {"NB", "New Brunswick"},
+			{"NL", "Newfoundland and Labrador"}, {"NS", "Nova Scotia"}, {"NT", "Northwest Territories"},
+			{"NU", "Nunavut"}, {"ON", "Ontario"}, {"PE", "Prince Edward Island"}, {"QC", "Quebec"},
+			{"SK", "Saskatchewan"}, {"YT", "Yukon"},
+		}},
+	{Code: "MX", Name: "Mexico", Aliases: []string{"México"},
+		Postal: regexp.MustCompile(`^(\d{5})$`), PostalExample: "06000"},
+	{Code: "GB", Name: "United Kingdom",
+		Aliases: []string{"UK", "Great Britain", "England", "Scotland", "Wales", "Northern Ireland"},
+		Postal:  regexp.MustCompile(`^([A-Z]{1,2}\d[A-Z\d]?)(\d[A-Z]{2})$`), PostalSep: " ", PostalExample: "SW1A 1AA"},
+	{Code: "IE", Name: "Ireland",
+		Postal: regexp.MustCompile(`^([A-Z]\d[\dW])([\dA-Z]{4})$`), PostalSep: " ", PostalExample: "D02 X285"},
+	{Code: "FR", Name: "France",
+		Postal: regexp.MustCompile(`^(\d{5})$`), PostalExample: "75008"},
+	{Code: "DE", Name: "Germany", Aliases: []string{"Deutschland"},
+		Postal: regexp.MustCompile(`^(\d{5})$`), PostalExample: "10115"},
+	{Code: "NL", Name: "Netherlands", Aliases: []string{"The Netherlands", "Holland"},
+		Postal: regexp.MustCompile(`^(\d{4})([A-Z]{2})$`), PostalSep: " ", PostalExample: "1012 AB"},
+	{Code: "BE", Name: "Belgium",
+		Postal: regexp.MustCompile(`^(\d{4})$`), PostalExample: "1000"},
+	{Code: "CH", Name: "Switzerland",
+		Postal: regexp.MustCompile(`^(\d{4})$`), PostalExample: "8001"},
+	{Code: "AT", Name: "Austria",
+		Postal: regexp.MustCompile(`^(\d{4})$`), PostalExample: "1010"},
+	{Code: "IT", Name: "Italy", Aliases: []string{"Italia"},
+		Postal: regexp.MustCompile(`^(\d{5})$`), PostalExample: "00184"},
+	{Code: "ES", Name: "Spain", Aliases: []string{"España"},
+		Postal: regexp.MustCompile(`^(\d{5})$`), PostalExample: "28001"},
+	{Code: "PL", Name: "Poland",
+		Postal: regexp.MustCompile(`^(\d{2})(\d{3})$`), PostalSep: "-", PostalExample: "00-950"},
+	{Code: "SE", Name: "Sweden",
+		Postal: regexp.MustCompile(`^(\d{3})(\d{2})$`), PostalSep: " ", PostalExample: "111 22"},
+	{Code: "DK", Name: "Denmark",
+		Postal: regexp.MustCompile(`^(\d{4})$`), PostalExample: "1050"},
+	{Code: "NO", Name: "Norway",
+		Postal: regexp.MustCompile(`^(\d{4})$`), PostalExample: "0150"},
+	{Code: "FI", Name: "Finland",
+		Postal: regexp.MustCompile(`^(\d{5})$`), PostalExample: "00100"},
+	{Code: "JP", Name: "Japan",
+		Postal: regexp.MustCompile(`^(\d{3})(\d{4})$`), PostalSep: "-", PostalExample: "100-0001"},
+	{Code: "KR", Name: "South Korea", Aliases: []string{"Korea", "Republic of Korea"},
+		Postal: regexp.MustCompile(`^(\d{5})$`), PostalExample: "03187"},
+	{Code: "CN", Name: "China",
+		Postal: regexp.MustCompile(`^(\d{6})$`), PostalExample: "100000"},
+	{Code: "HK", Name: "Hong Kong"},
+	{Code: "SG", Name: "Singapore",
+		Postal: regexp.MustCompile(`^(\d{6})$`), PostalExample: "018956"},
+	{Code: "IN", Name: "India",
+		Postal: regexp.MustCompile(`^(\d{6})$`), PostalExample: "110001"},
+	{Code: "AU", Name: "Australia",
+		Postal: regexp.MustCompile(`^(\d{4})$`), PostalExample: "2000",
+		States: []region{
+			{"ACT", "Australian Capital Territory"}, {"NSW", "New South Wales"},
+			{"NT", "Northern Territory"}, {"QLD", "Queensland"}, {"SA", "South Australia"},
+			{"TAS", "Tasmania"}, {"VIC", "Victoria"}, {"WA", "Western Australia"},
+		}},
+	{Code: "NZ", Name: "New Zealand",
+		Postal: regexp.MustCompile(`^(\d{4})$`), PostalExample: "6011"},
+	{Code: "BR", Name: "Brazil", Aliases: []string{"Brasil"},
+		Postal: regexp.MustCompile(`^(\d{5})(\d{3})$`), PostalSep: "-", PostalExample: "01310-100"},
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *pb.Address
+		want *pb.Address
+	}{
+		{"us",
+			&pb.Address{StreetAddress: " 1600  Amphitheatre Parkway ", City: "Mountain View", State: "california", Country: "United States", PostalCode: "94043"},
+			&pb.Address{StreetAddress: "1600 Amphitheatre Parkway", City: "Mountain View", State: "CA", Country: "US", PostalCode: "94043", ZipCode: 94043}},
+		{"us zip+4",
+			&pb.Address{StreetAddress: "1 Main St", City: "Boston", State: "MA", Country: "usa", PostalCode: "02108 1234"},
+			&pb.Address{StreetAddress: "1 Main St", City: "Boston", State: "MA", Country: "US", PostalCode: "02108-1234"}},
+		{"legacy zip code keeps leading zero",
+			&pb.Address{StreetAddress: "1 Main St", City: "Boston", State: "Massachusetts", Country: "US", ZipCode: 2108},
+			&pb.Address{StreetAddress: "1 Main St", City: "Boston", State: "MA", Country: "US", PostalCode: "02108", ZipCode: 2108}},
+		{"postal code wins over zip code",
+			&pb.Address{StreetAddress: "1 Main St", City: "Boston", State: "MA", Country: "US", PostalCode: "02109", ZipCode: 2108},
+			&pb.Address{StreetAddress: "1 Main St", City: "Boston", State: "MA", Country: "US", PostalCode: "02109", ZipCode: 2109}},
+		{"canada",
+			&pb.Address{StreetAddress: "111 Wellington St", City: "Ottawa", State: "Ontario", Country: "canada", PostalCode: "k1a0a9"},
+			&pb.Address{StreetAddress: "111 Wellington St", City: "Ottawa", State: "ON", Country: "CA", PostalCode: "K1A 0A9"}},
+		{"uk without state",
+			&pb.Address{StreetAddress: "10 Downing Street", City: "London", Country: "England", PostalCode: "sw1a2aa"},
+			&pb.Address{StreetAddress: "10 Downing Street", City: "London", Country: "GB", PostalCode: "SW1A 2AA"}},
+		{"japan",
+			&pb.Address{StreetAddress: "1-1 Chiyoda", City: "Tokyo", Country: "JP", PostalCode: "1008111"},
+			&pb.Address{StreetAddress: "1-1 Chiyoda", City: "Tokyo", Country: "JP", PostalCode: "100-8111"}},
+		{"free-form state",
+			&pb.Address{StreetAddress: "Unter den Linden 1", City: "Berlin", State: "Berlin", Country: "Deutschland", PostalCode: "10117"},
+			&pb.Address{StreetAddress: "Unter den Linden 1", City: "Berlin", State: "Berlin", Country: "DE", PostalCode: "10117", ZipCode: 10117}},
+		{"no postal codes",
+			&pb.Address{StreetAddress: "1 Queen's Road", City: "Central", Country: "Hong Kong", PostalCode: "000000"},
+			&pb.Address{StreetAddress: "1 Queen's Road", City: "Central", Country: "HK"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, violations := normalizeAddress(tt.in)
+			if violations != nil {
+				t.Fatalf("normalizeAddress() violations = %v", violations)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("normalizeAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddressViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     *pb.Address
+		fields []string
+	}{
+		{"nil", nil, []string{"address"}},
+		{"empty", &pb.Address{}, []string{"address.city", "address.country", "address.street_address"}},
+		{"unknown country", &pb.Address{StreetAddress: "1 Main St", City: "Springfield", Country: "Narnia"},
+			[]string{"address.country"}},
+		{"us missing state and zip", &pb.Address{StreetAddress: "1 Main St", City: "Springfield", Country: "US"},
+			[]string{"address.postal_code", "address.state"}},
+		{"unknown state", &pb.Address{StreetAddress: "1 Main St", City: "Springfield", State: "XY", Country: "US", PostalCode: "62701"},
+			[]string{"address.state"}},
+		{"malformed zip", &pb.Address{StreetAddress: "1 Main St", City: "Springfield", State: "IL", Country: "US", PostalCode: "6270"},
+			[]string{"address.postal_code"}},
+		{"us zip in canada", &pb.Address{StreetAddress: "1 Main St", City: "Toronto", State: "ON", Country: "CA", PostalCode: "62701"},
+			[]string{"address.postal_code"}},
+		{"too long", &pb.Address{StreetAddress: strings.Repeat("a", maxAddressFieldLength+1), City: "Paris", Country: "FR", PostalCode: "75001"},
+			[]string{"address.street_address"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, violations := normalizeAddress(tt.in)
+			if got != nil {
+				t.Errorf("normalizeAddress() = %v, want nil", got)
+			}
+			var fields []string
+			for _, v := range violations {
+				if v.GetDescription() == "" {
+					t.Errorf("violation of %s has no description", v.GetField())
+				}
+				fields = append(fields, v.GetField())
+			}
+			sort.Strings(fields)
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("normalizeAddress() violations of %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestCountriesData(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range countries {
+		for _, name := range append([]string{c.Code, c.Name}, c.Aliases...) {
+			if seen[strings.ToLower(name)] {
+				t.Errorf("%s: name %q is not unique", c.Code, name)
+			}
+			seen[strings.ToLower(name)] = true
+		}
+		if c.Postal != nil {
+			if _, ok := c.postalCode(c.PostalExample); !ok {
+				t.Errorf("%s: example postal code %q does not match", c.Code, c.PostalExample)
+			}
+		}
+	}
+}
+
+func TestInvalidAddressDetails(t *testing.T) {
+	s := newTestServer(t)
+	addr := &pb.Address{StreetAddress: "1 Main St", City: "Springfield", State: "IL", Country: "US", PostalCode: "6270"}
+	for name, call := range map[string]func() error{
+		"GetQuote": func() error {
+			_, err := s.GetQuote(context.Background(), &pb.GetQuoteRequest{Address: addr})
+			return err
+		},
+		"ShipOrder": func() error {
+			_, err := s.ShipOrder(context.Background(), &pb.ShipOrderRequest{Address: addr})
+			return err
+		},
+	} {
+		st := status.Convert(call())
+		if st.Code() != codes.InvalidArgument {
+			t.Fatalf("%s() = %v, want InvalidArgument", name, st.Err())
+		}
+		var fields []string
+		for _, d := range st.Details() {
+			if br, ok := d.(*errdetails.BadRequest); ok {
+				for _, v := range br.GetFieldViolations() {
+					fields = append(fields, v.GetField())
+				}
+			}
+		}
+		if len(fields) != 1 || fields[0] != "address.postal_code" {
+			t.Errorf("%s() field violations of %v, want address.postal_code", name, fields)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"time"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+// defaultDayLength is how long a simulated day lasts unless
+// SHIPMENT_DAY_LENGTH says otherwise.
+const defaultDayLength = 24 * time.Hour
+
+// clock is the time source of the shipment lifecycle. Tests replace it to
+// move shipments along.
+type clock interface {
+	Now() time.Time
+	// Timer returns a channel that receives once the time reaches t, and a
+	// function that releases the timer if it is no longer needed.
+	Timer(t time.Time) (c <-chan time.Time, stop func())
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Timer(t time.Time) (<-chan time.Time, func()) {
+	timer := time.NewTimer(time.Until(t))
+	return timer.C, func() { timer.Stop() }
+}
+
+// dayLengthFromEnv reads SHIPMENT_DAY_LENGTH as a time.Duration.
+func dayLengthFromEnv() (time.Duration, error) {
+	if s := os.Getenv("SHIPMENT_DAY_LENGTH"); s != "" {
+		return time.ParseDuration(s)
+	}
+	return defaultDayLength, nil
+}
+
+// lifecycle simulates the progress of shipments. A shipment is picked up a
+// quarter of a day after its label is created, goes out for delivery in the
+// morning of its delivery day and is delivered that afternoon. Shortening
+// dayLength speeds shipments up for demos.
+type lifecycle struct {
+	clock     clock
+	dayLength time.Duration
+}
+
+// milestone is a state a shipment enters after the given number of days.
+type milestone struct {
+	state pb.ShipmentStatus_State
+	days  float64
+}
+
+// milestones returns the states s goes through, in order.
+func (l lifecycle) milestones(s *shipment) []milestone {
+	days := float64(s.DeliveryDays)
+	if days < 1 {
+		days = 1
+	}
+	return []milestone{
+		{pb.ShipmentStatus_LABEL_CREATED, 0},
+		{pb.ShipmentStatus_IN_TRANSIT, 0.25},
+		{pb.ShipmentStatus_OUT_FOR_DELIVERY, days - 0.5},
+		{pb.ShipmentStatus_DELIVERED, days - 0.25},
+	}
+}
+
+func (l lifecycle) at(s *shipment, days float64) time.Time {
+	return s.CreatedAt.Add(time.Duration(days * float64(l.dayLength)))
+}
+
+// status returns the status of s now, and when it next changes. The time
+// is zero once s has been delivered.
+func (l lifecycle) status(s *shipment) (*pb.ShipmentStatus, time.Time) {
+	now := l.clock.Now()
+	milestones := l.milestones(s)
+	st := &pb.ShipmentStatus{
+		TrackingId:        s.TrackingID,
+		ServiceLevel:      s.ServiceLevel,
+		EstimatedDelivery: l.at(s, milestones[len(milestones)-1].days).Unix(),
+	}
+	for _, m := range milestones {
+		t := l.at(s, m.days)
+		if t.After(now) {
+			return st, t
+		}
+		st.State = m.state
+		st.Events = append(st.Events, &pb.ShipmentEvent{State: m.state, Time: t.Unix()})
+	}
+	return st, time.Time{}
+}
@@ -0,0 +1,245 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers map[chan time.Time]time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		timers: make(map[chan time.Time]time.Time),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Timer(t time.Time) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if !t.After(c.now) {
+		ch <- c.now
+		return ch, func() {}
+	}
+	c.timers[ch] = t
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.timers, ch)
+	}
+}
+
+// Advance moves the clock forward by d and fires the timers that are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for ch, t := range c.timers {
+		if !t.After(c.now) {
+			ch <- c.now
+			delete(c.timers, ch)
+		}
+	}
+}
+
+// describe renders a status as "STATE: EVENT@hours, ..." with the events'
+// times in hours since start.
+func describe(st *pb.ShipmentStatus, start time.Time) string {
+	var events []string
+	for _, e := range st.GetEvents() {
+		events = append(events, fmt.Sprintf("%s@%v", e.GetState(), time.Unix(e.GetTime(), 0).Sub(start).Hours()))
+	}
+	return fmt.Sprintf("%s: %s", st.GetState(), strings.Join(events, ", "))
+}
+
+func TestLifecycle(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	l := lifecycle{clock: clock, dayLength: 24 * time.Hour}
+	sh := &shipment{TrackingID: "AB-1", ServiceLevel: "express", DeliveryDays: 3, CreatedAt: start}
+
+	for _, step := range []struct {
+		advance  time.Duration
+		want     string
+		wantNext time.Duration
+	}{
+		{0, "LABEL_CREATED: LABEL_CREATED@0", 6 * time.Hour},
+		{6 * time.Hour, "IN_TRANSIT: LABEL_CREATED@0, IN_TRANSIT@6", 60 * time.Hour},
+		{53 * time.Hour, "IN_TRANSIT: LABEL_CREATED@0, IN_TRANSIT@6", 60 * time.Hour},
+		{time.Hour, "OUT_FOR_DELIVERY: LABEL_CREATED@0, IN_TRANSIT@6, OUT_FOR_DELIVERY@60", 66 * time.Hour},
+		{6 * time.Hour, "DELIVERED: LABEL_CREATED@0, IN_TRANSIT@6, OUT_FOR_DELIVERY@60, DELIVERED@66", -1},
+	} {
+		clock.Advance(step.advance)
+		st, next := l.status(sh)
+		if got := describe(st, start); got != step.want {
+			t.Errorf("at %v: status %q, want %q", clock.Now().Sub(start), got, step.want)
+		}
+		if step.wantNext < 0 && !next.IsZero() || step.wantNext >= 0 && !next.Equal(start.Add(step.wantNext)) {
+			t.Errorf("at %v: next change at %v, want %v", clock.Now().Sub(start), next, step.wantNext)
+		}
+		if want := start.Add(66 * time.Hour).Unix(); st.GetEstimatedDelivery() != want {
+			t.Errorf("estimated delivery %d, want %d", st.GetEstimatedDelivery(), want)
+		}
+	}
+
+	// Shorter days speed shipments up.
+	l.dayLength = time.Minute
+	if st, _ := l.status(sh); st.GetState() != pb.ShipmentStatus_DELIVERED {
+		t.Errorf("with one minute days, state after %v = %v, want DELIVERED", clock.Now().Sub(start), st.GetState())
+	}
+}
+
+// serveShipping serves s on an in-memory listener and returns a client.
+func serveShipping(t *testing.T, s *server) pb.ShippingServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterShippingServiceServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewShippingServiceClient(conn)
+}
+
+func TestShipmentTracking(t *testing.T) {
+	s := newTestServer(t)
+	clock := newFakeClock()
+	s.lifecycle.clock = clock
+	client := serveShipping(t, s)
+	ctx := context.Background()
+
+	shipped, err := client.ShipOrder(ctx, &pb.ShipOrderRequest{
+		Address:      testAddress,
+		Items:        []*pb.CartItem{{ProductId: "OLJCESPC7Z", Quantity: 1}},
+		ServiceLevel: "overnight",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := shipped.GetTrackingId()
+
+	st, err := client.GetShipmentStatus(ctx, &pb.GetShipmentStatusRequest{TrackingId: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.GetTrackingId() != id || st.GetServiceLevel() != "overnight" || st.GetState() != pb.ShipmentStatus_LABEL_CREATED {
+		t.Errorf("GetShipmentStatus() = %v", st)
+	}
+
+	stream, err := client.WatchShipment(ctx, &pb.WatchShipmentRequest{TrackingId: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []struct {
+		advance time.Duration
+		want    pb.ShipmentStatus_State
+	}{
+		{0, pb.ShipmentStatus_LABEL_CREATED},
+		{6 * time.Hour, pb.ShipmentStatus_IN_TRANSIT},
+		// Overnight shipments go out for delivery after half a day and are
+		// delivered after three quarters; skipping past both sends only the
+		// latest state.
+		{12 * time.Hour, pb.ShipmentStatus_DELIVERED},
+	} {
+		clock.Advance(step.advance)
+		st, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.GetState() != step.want {
+			t.Errorf("after %v: state %v, want %v", step.advance, st.GetState(), step.want)
+		}
+	}
+	if st, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv() after delivery = %v, %v; want EOF", st, err)
+	}
+	if len(st.GetEvents()) != 1 {
+		t.Errorf("GetShipmentStatus() events = %v", st.GetEvents())
+	}
+
+	for _, tc := range []struct {
+		id   string
+		want codes.Code
+	}{
+		{"", codes.InvalidArgument},
+		{"XX-0", codes.InvalidArgument},
+		{"HS-0000000000000", codes.NotFound},
+	} {
+		if _, err := client.GetShipmentStatus(ctx, &pb.GetShipmentStatusRequest{TrackingId: tc.id}); status.Code(err) != tc.want {
+			t.Errorf("GetShipmentStatus(%q) = %v, want %v", tc.id, err, tc.want)
+		}
+		stream, err := client.WatchShipment(ctx, &pb.WatchShipmentRequest{TrackingId: tc.id})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != tc.want {
+			t.Errorf("WatchShipment(%q) = %v, want %v", tc.id, err, tc.want)
+		}
+	}
+}
+
+func TestWatchShipmentStopsOnShutdown(t *testing.T) {
+	s := newTestServer(t)
+	client := serveShipping(t, s)
+	ctx := context.Background()
+	shipped, err := client.ShipOrder(ctx, &pb.ShipOrderRequest{Address: testAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := client.WatchShipment(ctx, &pb.WatchShipmentRequest{TrackingId: shipped.GetTrackingId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	close(s.stopping)
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Recv() after shutdown = %v, want Unavailable", err)
+	}
+}
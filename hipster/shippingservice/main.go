@@ -16,42 +16,27 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 
-	//opentelemetry
-	"github.com/google/uuid"
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp"
-	"go.opentelemetry.io/otel/exporters/stdout"
-	"go.opentelemetry.io/otel/exporters/trace/jaeger"
-	"go.opentelemetry.io/otel/label"
-	"go.opentelemetry.io/otel/sdk/resource"
-	"go.opentelemetry.io/otel/semconv"
-
-	"go.opentelemetry.io/otel/propagation"
-	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
-
-	
-	"go.opentelemetry.io/otel/sdk/trace"
-
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/observability/health"
 	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/money"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-
 var log *logrus.Logger
-var serviceName string
-var serviceNameSpace string
 
 func init() {
 	log = logrus.New()
@@ -65,106 +50,39 @@ func init() {
 		TimestampFormat: time.RFC3339Nano,
 	}
 	log.Out = os.Stdout
+	log.AddHook(observability.LogHook{})
 }
 
-func detectResource() (*resource.Resource, error) {
-	var instID label.KeyValue
-	if host, ok := os.LookupEnv("HOSTNAME"); ok && host != "" {
-		instID = semconv.ServiceInstanceIDKey.String(host)
+// initTracing installs the global tracer provider described by cfg and
+// returns a function that flushes and stops it.
+func initTracing(cfg observability.Config) (shutdown func(context.Context) error) {
+	if cfg.Disabled {
+		log.Info("tracing disabled")
 	} else {
-		instID = semconv.ServiceInstanceIDKey.String(uuid.New().String())
-	}
-    hostName:=os.Getenv("MY_POD_NAME")
-   hostIp:=os.Getenv("MY_POD_IP")
-   resourceType:=os.Getenv("RESOURCE_TYPE")
-   return resource.New(
-      context.Background(),
-      resource.WithAttributes(
-         instID,
-         semconv.ServiceNameKey.String(serviceName),
-         semconv.HostNameKey.String(hostName),
-         label.String("service.namespace",serviceNameSpace),
-         label.String("ip",hostIp),
-         label.String("resource.type",resourceType),
-      ),
-   )
-}
-func spanExporter() (exporttrace.SpanExporter, error) {
-
-    var user = os.Getenv("JAEGER_USER")
-    var password = os.Getenv("JAEGER_PASSWORD")
-	export_type := os.Getenv("EXPORT_TYPE")
-	if export_type == "JAEGER" {
-		log.Info("exporting with JAEGER logger")
-		addr1 := os.Getenv("JAEGER_ENDPOINT")
-		return jaeger.NewRawExporter(
-			jaeger.WithCollectorEndpoint(addr1,jaeger.WithUsername(user),jaeger.WithPassword(password)),
-			jaeger.WithProcess(jaeger.Process{
-				ServiceName: serviceName,
-			}),
-		)
-	}
-	if export_type == "OTLP" {
-		log.Info("exporting with OTLP logger")
-		if os.Getenv("OTLP_ENDPOINT") != "" {
-			addr := os.Getenv("OTLP_ENDPOINT")
-			return otlp.NewExporter(
-				context.Background(),
-				otlp.WithInsecure(),
-				otlp.WithAddress(addr),
-			)
-		}
+		log.WithField("exporter", cfg.Exporter).Info("tracing enabled")
 	}
-
-	log.Info("exporting with STDOUT logger")
-	return stdout.NewExporter(
-		stdout.WithPrettyPrint(),
-		stdout.WithWriter(log.Writer()),
-	)
-}
-func initTracing() {
-	if os.Getenv("DISABLE_TRACING") != "" {
-		log.Info("tracing disabled")
-		return
+	if cfg.Exporter == "" || cfg.Exporter == observability.ExporterStdout {
+		cfg.StdoutWriter = log.Writer()
 	}
-
-	res, err := detectResource()
+	shutdown, err := observability.Setup(context.Background(), cfg)
 	if err != nil {
-		log.WithError(err).Fatal("failed to detect environment resource")
+		log.WithError(err).Fatal("failed to initialize tracing")
 	}
-
-	exp, err := spanExporter()
-	if err != nil {
-		log.WithError(err).Fatal("failed to initialize Span exporter")
-		return
-	}
-
-	log.Info("tracing enabled")
-	otel.SetTracerProvider(
-		trace.NewTracerProvider(
-			trace.WithConfig(
-				trace.Config{
-					DefaultSampler: trace.AlwaysSample(),
-					Resource:       res,
-				},
-			),
-			trace.WithSpanProcessor(
-				trace.NewBatchSpanProcessor(exp),
-			),
-		),
-	)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return shutdown
 }
 
 func main() {
 
-    if serviceName=os.Getenv("SERVICE_NAME");serviceName==""{
-        serviceName = "Shipping-service"
-    }
-    if serviceNameSpace=os.Getenv("SERVICE_NAMESPACE");serviceNameSpace==""{
-        serviceNameSpace = "hipster"
-    }
-	initTracing()
+	telemetryConfig, err := observability.ConfigFromEnv("Shipping-service")
+	if err != nil {
+		log.WithError(err).Fatal("invalid telemetry configuration")
+	}
+	shutdownTracing := initTracing(telemetryConfig)
+	shutdownMetrics := initMetrics(telemetryConfig)
+	shutdownTimeout, err := observability.ShutdownTimeoutFromEnv()
+	if err != nil {
+		log.Fatalf("failed to parse SHUTDOWN_TIMEOUT as time.Duration: %+v", err)
+	}
 
 	var port = os.Getenv("PORT")
 	if value, ok := os.LookupEnv("PORT"); ok {
@@ -180,69 +98,221 @@ func main() {
 	var srv *grpc.Server
 
 	srv = grpc.NewServer(
-		grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
+		grpc.ChainUnaryInterceptor(observability.UnaryServerInterceptors(log)...),
+		grpc.ChainStreamInterceptor(observability.StreamServerInterceptors(log)...),
 	)
 
-	svc := &server{}
+	rates, err := rateCardFromEnv()
+	if err != nil {
+		log.WithError(err).Fatal("failed to load rate card")
+	}
+	dayLength, err := dayLengthFromEnv()
+	if err != nil {
+		log.Fatalf("failed to parse SHIPMENT_DAY_LENGTH as time.Duration: %+v", err)
+	}
+	trackingIdFormat, trackingIds, err := trackingIdsFromEnv()
+	if err != nil {
+		log.WithError(err).Fatal("invalid tracking ID configuration")
+	}
+	shipments, err := newShipmentStoreFromEnv()
+	if err != nil {
+		log.WithError(err).Fatal("failed to open shipment store")
+	}
+	svc := &server{
+		rates:     rates,
+		shipments: shipments,
+		lifecycle: lifecycle{clock: realClock{}, dayLength: dayLength},
+		stopping:  make(chan struct{}),
+
+		trackingIdFormat: trackingIdFormat,
+		trackingIds:      trackingIds,
+	}
+	// The service has no dependencies, so it is serving whenever it is up.
+	hs := health.NewServer("hipstershop.ShippingService")
 	pb.RegisterShippingServiceServer(srv, svc)
-	healthpb.RegisterHealthServer(srv, svc)
+	healthpb.RegisterHealthServer(srv, hs)
 	log.Infof("Shipping Service listening on port %s", port)
 
 	// Register reflection service on gRPC server.
 	reflection.Register(srv)
-	if err := srv.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	observability.WaitForShutdownSignal(log)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	hs.Shutdown()
+	close(svc.stopping)
+	observability.GracefulStop(ctx, log, srv)
+	if err := shipments.Close(); err != nil {
+		log.WithError(err).Warn("failed to close shipment store")
 	}
+	observability.FlushTelemetry(log, shutdownTracing, shutdownMetrics)
+	log.Info("shutdown complete")
 }
 
+// maxTrackingIDAttempts bounds the tracking IDs ShipOrder generates before
+// giving up on finding an unused one.
+const maxTrackingIDAttempts = 5
+
 // server controls RPC service responses.
-type server struct{}
+type server struct {
+	rates     *RateCard
+	shipments shipmentStore
+	lifecycle lifecycle
 
-// Check is for health checking.
-func (s *server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
-	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
-}
+	trackingIdFormat TrackingIdFormat
+	trackingIds      TrackingIdGenerator
 
-func (s *server) Watch(req *healthpb.HealthCheckRequest, ws healthpb.Health_WatchServer) error {
-	return status.Errorf(codes.Unimplemented, "health check via Watch not implemented")
+	// stopping is closed when the server shuts down, to end WatchShipment
+	// streams which would otherwise hold up a graceful stop.
+	stopping chan struct{}
 }
 
-// GetQuote produces a shipping quote (cost) in USD.
+// GetQuote prices the shipment at every service level available for its
+// destination and returns the cost of the requested one.
 func (s *server) GetQuote(ctx context.Context, in *pb.GetQuoteRequest) (*pb.GetQuoteResponse, error) {
+	log := observability.LoggerFromContext(ctx, log)
 	log.Info("[GetQuote] received request")
 	defer log.Info("[GetQuote] completed request")
 
-	// 1. Our quote system requires the total number of items to be shipped.
-	count := 0
-	for _, item := range in.Items {
-		count += int(item.Quantity)
+	if c := in.GetSubtotalUsd().GetCurrencyCode(); c != "" && c != "USD" {
+		return nil, status.Errorf(codes.InvalidArgument, "subtotal must be in USD, got %s", c)
+	}
+	subtotal := pb.Money{CurrencyCode: "USD", Units: in.GetSubtotalUsd().GetUnits(), Nanos: in.GetSubtotalUsd().GetNanos()}
+	if !money.IsValid(subtotal) {
+		return nil, status.Error(codes.InvalidArgument, "subtotal is not a valid amount")
+	}
+	// Quotes without an address, as for a cart, are priced for the default
+	// zone.
+	addr := in.GetAddress()
+	if addr != nil {
+		var violations []*errdetails.BadRequest_FieldViolation
+		if addr, violations = normalizeAddress(addr); violations != nil {
+			return nil, invalidAddressError(violations)
+		}
+	}
+	options, err := s.rates.options(addr, in.GetItems(), subtotal)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	// 2. Generate a quote based on the total number of items to be shipped.
-	quote := CreateQuoteFromCount(count)
-
-	// 3. Generate a response.
-	return &pb.GetQuoteResponse{
-		CostUsd: &pb.Money{
-			CurrencyCode: "USD",
-			Units:        int64(quote.Dollars),
-			Nanos:        int32(quote.Cents * 10000000)},
-	}, nil
-
+	level := in.GetServiceLevel()
+	if level == "" {
+		level = defaultServiceLevel
+	}
+	resp := new(pb.GetQuoteResponse)
+	for _, o := range options {
+		cost := o.cost.Money()
+		resp.Options = append(resp.Options, &pb.ShippingOption{
+			ServiceLevel: o.level.ID,
+			Name:         o.level.displayName(),
+			CostUsd:      cost,
+			MinDays:      o.level.MinDays,
+			MaxDays:      o.level.MaxDays,
+		})
+		if o.level.ID == level {
+			resp.CostUsd = cost
+		}
+	}
+	if resp.CostUsd == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "service level %q is not available for this destination", level)
+	}
+	return resp, nil
 }
 
-// ShipOrder mocks that the requested items will be shipped.
-// It supplies a tracking ID for notional lookup of shipment delivery status.
+// ShipOrder packs the requested items into packages and records a shipment
+// for each. Every package has its own tracking ID, which GetShipmentStatus
+// and WatchShipment look it up by.
 func (s *server) ShipOrder(ctx context.Context, in *pb.ShipOrderRequest) (*pb.ShipOrderResponse, error) {
+	log := observability.LoggerFromContext(ctx, log)
 	log.Info("[ShipOrder] received request")
 	defer log.Info("[ShipOrder] completed request")
-	// 1. Create a Tracking ID
-	baseAddress := fmt.Sprintf("%s, %s, %s", in.Address.StreetAddress, in.Address.City, in.Address.State)
-	id := CreateTrackingId(baseAddress)
+	levelID := in.GetServiceLevel()
+	if levelID == "" {
+		levelID = defaultServiceLevel
+	}
+	level, ok := s.rates.serviceLevel(levelID)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown service level %q", levelID)
+	}
+	addr, violations := normalizeAddress(in.GetAddress())
+	if violations != nil {
+		return nil, invalidAddressError(violations)
+	}
+	if err := s.rates.checkUnits(in.GetItems()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// 1. Pack the items and create a shipment for each package.
+	resp := &pb.ShipOrderResponse{Address: addr}
+	for _, p := range s.rates.packItems(in.GetItems()) {
+		sh := &shipment{
+			ServiceLevel: level.ID,
+			DeliveryDays: level.MaxDays,
+			Address:      addr,
+			CreatedAt:    s.lifecycle.clock.Now(),
+		}
+		if err := s.createShipment(ctx, sh); err != nil {
+			log.WithError(err).Error("failed to store shipment")
+			return nil, status.Errorf(codes.Internal, "failed to store shipment: %v", err)
+		}
+		resp.Packages = append(resp.Packages, &pb.Package{
+			TrackingId: sh.TrackingID,
+			Box:        p.boxID(),
+			Items:      p.items,
+			WeightKg:   math.Round(p.weight*1000) / 1000,
+		})
+	}
+	if len(resp.Packages) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order has no items to ship")
+	}
+	resp.TrackingId = resp.Packages[0].GetTrackingId()
+	log.WithField("tracking_id", resp.TrackingId).Infof("shipment created in %d packages", len(resp.Packages))
 
 	// 2. Generate a response.
-	return &pb.ShipOrderResponse{
-		TrackingId: id,
-	}, nil
+	return resp, nil
+}
+
+// createShipment stores sh under a new tracking ID, trying again in the
+// unlikely case that it is taken.
+func (s *server) createShipment(ctx context.Context, sh *shipment) error {
+	var err error
+	for attempt := 0; attempt < maxTrackingIDAttempts; attempt++ {
+		sh.TrackingID = s.trackingIds.NewTrackingId()
+		if err = s.shipments.Create(ctx, sh); err != errShipmentExists {
+			break
+		}
+	}
+	return err
+}
+
+// GetShipmentStatus returns the tracking history of a shipment.
+func (s *server) GetShipmentStatus(ctx context.Context, in *pb.GetShipmentStatusRequest) (*pb.ShipmentStatus, error) {
+	sh, err := s.getShipment(ctx, in.GetTrackingId())
+	if err != nil {
+		return nil, err
+	}
+	st, _ := s.lifecycle.status(sh)
+	return st, nil
+}
+
+// getShipment looks up a shipment and maps store errors to gRPC statuses.
+func (s *server) getShipment(ctx context.Context, trackingID string) (*shipment, error) {
+	if trackingID == "" {
+		return nil, status.Error(codes.InvalidArgument, "tracking_id is required")
+	}
+	if err := s.trackingIdFormat.ValidateTrackingId(trackingID); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	sh, err := s.shipments.Get(ctx, trackingID)
+	if err == errShipmentNotFound {
+		return nil, status.Errorf(codes.NotFound, "no shipment with tracking ID %s", trackingID)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to look up shipment: %v", err)
+	}
+	return sh, nil
 }
@@ -0,0 +1,180 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+// Box is a shipping box orders are packed in.
+type Box struct {
+	ID          string  `json:"id"`
+	LengthCm    float64 `json:"length_cm"`
+	WidthCm     float64 `json:"width_cm"`
+	HeightCm    float64 `json:"height_cm"`
+	MaxWeightKg float64 `json:"max_weight_kg"`
+}
+
+func (b Box) volume() float64 { return b.LengthCm * b.WidthCm * b.HeightCm }
+
+// dims returns the dimensions of p from the longest to the shortest.
+func (p Parcel) dims() [3]float64 {
+	d := []float64{p.LengthCm, p.WidthCm, p.HeightCm}
+	sort.Sort(sort.Reverse(sort.Float64Slice(d)))
+	return [3]float64{d[0], d[1], d[2]}
+}
+
+// parcelPackage is a package being packed: its box, or nil if its items
+// ship as they are, and its contents.
+type parcelPackage struct {
+	box   *Box
+	items []*pb.CartItem
+
+	weight float64
+	volume float64
+	// dims holds the longest, middle and shortest dimensions of any unit
+	// in the package, which the box must be at least as large as.
+	dims [3]float64
+}
+
+func (p *parcelPackage) boxID() string {
+	if p.box == nil {
+		return ""
+	}
+	return p.box.ID
+}
+
+// holds reports whether b has room for the contents of p plus the unit u.
+func (p *parcelPackage) holds(b *Box, u Parcel) bool {
+	if p.weight+u.WeightKg > b.MaxWeightKg || p.volume+u.volume() > b.volume() {
+		return false
+	}
+	ud, bd := u.dims(), Parcel{LengthCm: b.LengthCm, WidthCm: b.WidthCm, HeightCm: b.HeightCm}.dims()
+	for i := range ud {
+		if math.Max(p.dims[i], ud[i]) > bd[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parcelPackage) add(productID string, u Parcel) {
+	p.weight += u.WeightKg
+	p.volume += u.volume()
+	for i, d := range u.dims() {
+		p.dims[i] = math.Max(p.dims[i], d)
+	}
+	for _, item := range p.items {
+		if item.ProductId == productID {
+			item.Quantity++
+			return
+		}
+	}
+	p.items = append(p.items, &pb.CartItem{ProductId: productID, Quantity: 1})
+}
+
+// smallestBox returns the smallest box with room for the contents of p plus
+// u, or nil if none has.
+func (c *RateCard) smallestBox(p *parcelPackage, u Parcel) *Box {
+	var best *Box
+	for i := range c.Boxes {
+		b := &c.Boxes[i]
+		if p.holds(b, u) && (best == nil || b.volume() < best.volume()) {
+			best = b
+		}
+	}
+	return best
+}
+
+// packItems splits items into packages by first-fit decreasing: units are
+// packed from the largest to the smallest, each into the first package with
+// room for it, then into the first package that a larger box would make room
+// in, and otherwise into a new package in the smallest box it fits in.
+// Units too large or heavy for every box ship as they are, on their own.
+//
+// Room is judged by volume, weight and the dimensions of each unit, as if
+// units could be arranged to fill a box.
+func (c *RateCard) packItems(items []*pb.CartItem) []*parcelPackage {
+	if len(c.Boxes) == 0 || len(items) == 0 {
+		p := &parcelPackage{}
+		for _, item := range items {
+			p.items = append(p.items, proto.Clone(item).(*pb.CartItem))
+			p.weight += c.parcel(item.GetProductId()).WeightKg * float64(item.GetQuantity())
+		}
+		return []*parcelPackage{p}
+	}
+
+	type unit struct {
+		productID string
+		parcel    Parcel
+	}
+	var units []unit
+	for _, item := range items {
+		p := c.parcel(item.GetProductId())
+		for i := int32(0); i < item.GetQuantity(); i++ {
+			units = append(units, unit{item.GetProductId(), p})
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		if vi, vj := units[i].parcel.volume(), units[j].parcel.volume(); vi != vj {
+			return vi > vj
+		}
+		return units[i].parcel.WeightKg > units[j].parcel.WeightKg
+	})
+
+	var packages []*parcelPackage
+	for _, u := range units {
+		p := c.packageFor(packages, u.parcel)
+		if p == nil {
+			p = &parcelPackage{box: c.smallestBox(&parcelPackage{}, u.parcel)}
+			packages = append(packages, p)
+		}
+		p.add(u.productID, u.parcel)
+	}
+	return packages
+}
+
+// packageFor returns the first of packages with room for u, moving it to a
+// larger box if that is what it takes, or nil if there is none.
+func (c *RateCard) packageFor(packages []*parcelPackage, u Parcel) *parcelPackage {
+	for _, p := range packages {
+		if p.box != nil && p.holds(p.box, u) {
+			return p
+		}
+	}
+	for _, p := range packages {
+		if p.box == nil {
+			continue
+		}
+		if b := c.smallestBox(p, u); b != nil {
+			p.box = b
+			return p
+		}
+	}
+	return nil
+}
+
+// parcel returns the parcel of one unit of the product.
+func (c *RateCard) parcel(productID string) Parcel {
+	if p, ok := c.Products[productID]; ok {
+		return p
+	}
+	return c.DefaultProduct
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+const (
+	typewriter = "OLJCESPC7Z"
+	mug        = "LS4PSXUNUM"
+	bike       = "9SIQT8TOJO"
+	baristaKit = "1YMWWN1N4O"
+)
+
+// describePackages summarizes packages as in "large[OLJCESPC7Z×1 LS4PSXUNUM×2]".
+func describePackages(packages []*parcelPackage) string {
+	var out []string
+	for _, p := range packages {
+		var items []string
+		for _, item := range p.items {
+			items = append(items, fmt.Sprintf("%s×%d", item.GetProductId(), item.GetQuantity()))
+		}
+		out = append(out, fmt.Sprintf("%s[%s]", p.boxID(), strings.Join(items, " ")))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestPackItems(t *testing.T) {
+	c, err := loadRateCard("rates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name  string
+		items []*pb.CartItem
+		want  string
+	}{
+		{"empty", nil, "[]"},
+		{"small items share a small box", []*pb.CartItem{{ProductId: mug, Quantity: 2}}, "small[LS4PSXUNUM×2]"},
+		{"packed around the largest item", []*pb.CartItem{{ProductId: mug, Quantity: 2}, {ProductId: typewriter, Quantity: 1}},
+			"large[OLJCESPC7Z×1 LS4PSXUNUM×2]"},
+		{"split by volume", []*pb.CartItem{{ProductId: typewriter, Quantity: 5}},
+			"large[OLJCESPC7Z×3] large[OLJCESPC7Z×2]"},
+		{"too large for any box", []*pb.CartItem{{ProductId: bike, Quantity: 1}, {ProductId: mug, Quantity: 1}},
+			"[9SIQT8TOJO×1] small[LS4PSXUNUM×1]"},
+		{"too large items ship one by one", []*pb.CartItem{{ProductId: bike, Quantity: 2}},
+			"[9SIQT8TOJO×1] [9SIQT8TOJO×1]"},
+		{"moved to a larger box", []*pb.CartItem{{ProductId: baristaKit, Quantity: 2}},
+			"large[1YMWWN1N4O×2]"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := describePackages(c.packItems(tc.items)); got != tc.want {
+				t.Errorf("packItems() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPackItemsByWeight(t *testing.T) {
+	c := testRateCard()
+	c.Boxes = []Box{{ID: "crate", LengthCm: 100, WidthCm: 100, HeightCm: 100, MaxWeightKg: 10}}
+	got := c.packItems([]*pb.CartItem{{ProductId: "heavy", Quantity: 5}})
+	if want := "crate[heavy×2] crate[heavy×2] crate[heavy×1]"; describePackages(got) != want {
+		t.Errorf("packItems() = %s, want %s", describePackages(got), want)
+	}
+
+	c.Boxes = nil
+	got = c.packItems([]*pb.CartItem{{ProductId: "heavy", Quantity: 5}, {ProductId: "bulky", Quantity: 1}})
+	if want := "[heavy×5 bulky×1]"; describePackages(got) != want || got[0].weight != 21 {
+		t.Errorf("packItems() without boxes = %s of %g kg, want %s of 21 kg", describePackages(got), got[0].weight, want)
+	}
+}
+
+// TestPackItemsInvariants checks that packing random carts ships every unit
+// exactly once and never overfills a box.
+func TestPackItemsInvariants(t *testing.T) {
+	c, err := loadRateCard("rates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var products []string
+	for id := range c.Products {
+		products = append(products, id)
+	}
+	sort.Strings(products)
+
+	check := func(seed int64) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		want := make(map[string]int32)
+		var items []*pb.CartItem
+		for _, i := range rnd.Perm(len(products))[:1+rnd.Intn(len(products))] {
+			qty := int32(1 + rnd.Intn(6))
+			items = append(items, &pb.CartItem{ProductId: products[i], Quantity: qty})
+			want[products[i]] = qty
+		}
+
+		got := make(map[string]int32)
+		for _, p := range c.packItems(items) {
+			var weight, volume float64
+			for _, item := range p.items {
+				got[item.GetProductId()] += item.GetQuantity()
+				parcel := c.parcel(item.GetProductId())
+				weight += parcel.WeightKg * float64(item.GetQuantity())
+				volume += parcel.volume() * float64(item.GetQuantity())
+			}
+			if p.box == nil {
+				if len(p.items) != 1 || p.items[0].GetQuantity() != 1 {
+					t.Logf("seed %d: unboxed package of several units: %s", seed, describePackages([]*parcelPackage{p}))
+					return false
+				}
+				continue
+			}
+			if weight > p.box.MaxWeightKg+1e-9 || volume > p.box.volume() {
+				t.Logf("seed %d: overfilled %s", seed, describePackages([]*parcelPackage{p}))
+				return false
+			}
+		}
+		for id, qty := range want {
+			if got[id] != qty {
+				t.Logf("seed %d: packed %d of %s, want %d", seed, got[id], id, qty)
+				return false
+			}
+		}
+		return len(got) == len(want)
+	}
+	if err := quick.Check(check, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestShipOrderPackages(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	res, err := s.ShipOrder(ctx, &pb.ShipOrderRequest{
+		Address: testAddress,
+		Items:   []*pb.CartItem{{ProductId: bike, Quantity: 1}, {ProductId: mug, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packages := res.GetPackages()
+	if len(packages) != 2 {
+		t.Fatalf("ShipOrder() packages = %v, want 2", packages)
+	}
+	if res.GetTrackingId() != packages[0].GetTrackingId() {
+		t.Errorf("ShipOrder() tracking ID %s, want that of the first package %s", res.GetTrackingId(), packages[0].GetTrackingId())
+	}
+	if packages[0].GetBox() != "" || packages[1].GetBox() != "small" || packages[1].GetWeightKg() != 0.6 {
+		t.Errorf("ShipOrder() packages = %v", packages)
+	}
+	if packages[0].GetTrackingId() == packages[1].GetTrackingId() {
+		t.Errorf("packages share tracking ID %s", packages[0].GetTrackingId())
+	}
+	for _, p := range packages {
+		if _, err := s.GetShipmentStatus(ctx, &pb.GetShipmentStatusRequest{TrackingId: p.GetTrackingId()}); err != nil {
+			t.Errorf("GetShipmentStatus(%s) = %v", p.GetTrackingId(), err)
+		}
+	}
+}
+
+func TestShipOrderTooManyUnits(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	for _, items := range [][]*pb.CartItem{
+		{{ProductId: mug, Quantity: math.MaxInt32}},
+		{{ProductId: mug, Quantity: defaultMaxUnits}, {ProductId: typewriter, Quantity: 1}},
+		{{ProductId: mug, Quantity: -1}},
+		{{ProductId: mug, Quantity: 0}, {ProductId: typewriter, Quantity: 1}},
+	} {
+		_, err := s.ShipOrder(ctx, &pb.ShipOrderRequest{Address: testAddress, Items: items})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ShipOrder(%v) = %v, want InvalidArgument", items, err)
+		}
+		_, err = s.GetQuote(ctx, &pb.GetQuoteRequest{Address: testAddress, Items: items})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetQuote(%v) = %v, want InvalidArgument", items, err)
+		}
+	}
+
+	s.rates.MaxUnits = 3
+	if _, err := s.ShipOrder(ctx, &pb.ShipOrderRequest{
+		Address: testAddress,
+		Items:   []*pb.CartItem{{ProductId: mug, Quantity: 3}},
+	}); err != nil {
+		t.Errorf("ShipOrder() of max_units units = %v", err)
+	}
+	if _, err := s.ShipOrder(ctx, &pb.ShipOrderRequest{
+		Address: testAddress,
+		Items:   []*pb.CartItem{{ProductId: mug, Quantity: 4}},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ShipOrder() of max_units+1 units = %v, want InvalidArgument", err)
+	}
+}
+
+func TestShipOrderZeroQuantities(t *testing.T) {
+	s := newTestServer(t)
+	items := []*pb.CartItem{{ProductId: mug, Quantity: 0}, {ProductId: typewriter, Quantity: 0}}
+	_, err := s.ShipOrder(context.Background(), &pb.ShipOrderRequest{Address: testAddress, Items: items})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ShipOrder(%v) = %v, want InvalidArgument", items, err)
+	}
+}
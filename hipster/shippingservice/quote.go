@@ -17,39 +17,73 @@ package main
 import (
 	"fmt"
 	"math"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/money"
+)
+
+const (
+	nanosPerUnit = 1000000000
+	nanosPerCent = 10000000
+	centsPerUnit = 100
 )
 
-// Quote represents a currency value.
+// Quote is a shipping cost in USD, rounded to the cent. Costs are computed
+// exactly, in nanos of a dollar, and only rounded once they are final.
 type Quote struct {
-	Dollars uint32
-	Cents   uint32
+	usd pb.Money
 }
 
-// String representation of the Quote.
+// newQuote rounds m to the nearest cent, halves away from zero.
+func newQuote(m pb.Money) Quote {
+	nanos := m.GetNanos()
+	rem := nanos % nanosPerCent
+	m = pb.Money{CurrencyCode: "USD", Units: m.GetUnits(), Nanos: nanos - rem}
+	switch {
+	case rem >= nanosPerCent/2:
+		m = money.Must(money.Sum(m, pb.Money{CurrencyCode: "USD", Nanos: nanosPerCent}))
+	case rem <= -nanosPerCent/2:
+		m = money.Must(money.Sum(m, pb.Money{CurrencyCode: "USD", Nanos: -nanosPerCent}))
+	}
+	return Quote{usd: m}
+}
+
+// String formats the quote as in "$11.20".
 func (q Quote) String() string {
-	return fmt.Sprintf("$%d.%d", q.Dollars, q.Cents)
+	sign, units, cents := "", q.usd.GetUnits(), q.usd.GetNanos()/nanosPerCent
+	if money.IsNegative(q.usd) {
+		sign, units, cents = "-", -units, -cents
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, units, cents)
 }
 
-// CreateQuoteFromCount takes a number of items and returns a Price struct.
-func CreateQuoteFromCount(count int) Quote {
-	return CreateQuoteFromFloat(quoteByCountFloat(count))
+// Money returns the quote as a pb.Money in USD.
+func (q Quote) Money() *pb.Money {
+	return &pb.Money{CurrencyCode: "USD", Units: q.usd.GetUnits(), Nanos: q.usd.GetNanos()}
 }
 
-// CreateQuoteFromFloat takes a price represented as a float and creates a Price struct.
-func CreateQuoteFromFloat(value float64) Quote {
-	units, fraction := math.Modf(value)
-	return Quote{
-		uint32(units),
-		uint32(math.Trunc(fraction * 100)),
-	}
+// less reports whether q costs less than o.
+func (q Quote) less(o Quote) bool { return lessUSD(q.usd, o.usd) }
+
+// lessUSD reports whether l is less than r. Both must be valid amounts in
+// USD.
+func lessUSD(l, r pb.Money) bool {
+	return money.IsNegative(money.Must(money.Sum(l, money.Negate(r))))
 }
 
-// quoteByCountFloat takes a number of items and generates a price quote represented as a float.
-func quoteByCountFloat(count int) float64 {
-	if count == 0 {
-		return 0
-	}
-	count64 := float64(count)
-	var p = 1 + (count64 * 0.2)
-	return count64 + math.Pow(3, p)
+// usdNanos returns an amount of nanos of a dollar in USD.
+func usdNanos(nanos int64) pb.Money {
+	return pb.Money{CurrencyCode: "USD", Units: nanos / nanosPerUnit, Nanos: int32(nanos % nanosPerUnit)}
+}
+
+func usdCents(cents int64) pb.Money { return usdNanos(cents * nanosPerCent) }
+
+// cents converts an amount in dollars read from the rate card, which holds
+// whole cents, to cents.
+func cents(dollars float64) int64 { return int64(math.Round(dollars * centsPerUnit)) }
+
+// isWholeCents reports whether dollars is a whole number of cents, give or
+// take the error of its float64 representation.
+func isWholeCents(dollars float64) bool {
+	return math.Abs(dollars*centsPerUnit-math.Round(dollars*centsPerUnit)) < 1e-6
 }
@@ -0,0 +1,107 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+func usd(units int64, nanos int32) pb.Money {
+	return pb.Money{CurrencyCode: "USD", Units: units, Nanos: nanos}
+}
+
+func TestNewQuote(t *testing.T) {
+	for _, tc := range []struct {
+		in   pb.Money
+		want string
+	}{
+		{usd(0, 0), "$0.00"},
+		{usd(0, 50000000), "$0.05"},
+		{usd(11, 200000000), "$11.20"},
+		{usd(4, 485000000), "$4.49"},
+		{usd(4, 484999999), "$4.48"},
+		{usd(4, 995000000), "$5.00"},
+		{usd(0, 5000000), "$0.01"},
+		{usd(0, 4999999), "$0.00"},
+		{usd(-4, -485000000), "-$4.49"},
+		{usd(-4, -484999999), "-$4.48"},
+		{usd(0, -5000000), "-$0.01"},
+	} {
+		if got := newQuote(tc.in).String(); got != tc.want {
+			t.Errorf("newQuote(%v) = %s; want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestQuoteMoney(t *testing.T) {
+	got := newQuote(usd(11, 199999999)).Money()
+	if got.GetCurrencyCode() != "USD" || got.GetUnits() != 11 || got.GetNanos() != 200000000 {
+		t.Errorf("Money() = %v; want USD 11.200000000", got)
+	}
+}
+
+// TestCostEveryItemCount checks that costs are rounded correctly at every
+// item count against the same computation done in whole cents.
+func TestCostEveryItemCount(t *testing.T) {
+	card := testRateCard()
+	card.DefaultProduct = Parcel{WeightKg: 0.25}
+	rate := Rate{Base: 4.99, PerKg: 2.99}
+	const baseCents, perKgCents = 499, 299
+	for n := 1; n <= 100; n++ {
+		items := []*pb.CartItem{{ProductId: "p", Quantity: int32(n)}}
+		increments := int64((n + 1) / 2) // 0.25 kg each, billed per 0.5 kg
+		cents := baseCents + (perKgCents*increments+1)/2
+		want := fmt.Sprintf("$%d.%02d", cents/100, cents%100)
+		got, err := rate.cost(card.billableWeight(items), usd(0, 0))
+		if err != nil || got.String() != want {
+			t.Errorf("cost of %d items = %s, %v; want %s", n, got, err, want)
+		}
+	}
+}
+
+func TestCostFreeOver(t *testing.T) {
+	rate := Rate{Base: 5, PerKg: 1, FreeOver: 50}
+	for _, tc := range []struct {
+		subtotal pb.Money
+		want     string
+	}{
+		{usd(49, 990000000), "$6.00"},
+		{usd(49, 999999999), "$6.00"},
+		{usd(50, 0), "$0.00"},
+		{usd(120, 0), "$0.00"},
+	} {
+		got, err := rate.cost(1, tc.subtotal)
+		if err != nil || got.String() != tc.want {
+			t.Errorf("cost(1, %v) = %s, %v; want %s", tc.subtotal, got, err, tc.want)
+		}
+	}
+}
+
+func TestCostWeightRange(t *testing.T) {
+	rate := Rate{Base: 5, PerKg: 1}
+	got, err := rate.cost(1e9, usd(0, 0))
+	if err != nil || got.String() != "$1000000005.00" {
+		t.Errorf("cost(1e9) = %s, %v; want $1000000005.00", got, err)
+	}
+	for _, weight := range []float64{5e10, 1e30, -1, math.Inf(1), math.NaN()} {
+		if got, err := rate.cost(weight, usd(0, 0)); err == nil {
+			t.Errorf("cost(%g) = %s; want an error", weight, got)
+		}
+	}
+}
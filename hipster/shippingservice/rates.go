@@ -0,0 +1,383 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+const (
+	// defaultServiceLevel is quoted and shipped when a request names none.
+	// Every rate card must offer it in every zone.
+	defaultServiceLevel = "standard"
+
+	// anyCountry in a zone's countries matches every destination.
+	anyCountry = "*"
+
+	// Billable weights are rounded up to a whole number of increments of
+	// 1/weightIncrementsPerKg kg.
+	weightIncrementsPerKg = 2
+	weightIncrementKg     = 1.0 / weightIncrementsPerKg
+
+	// defaultMaxUnits is the most units a shipment may hold when the rate
+	// card sets no max_units.
+	defaultMaxUnits = 1000
+)
+
+// RateCard holds everything shipments are priced from. It is read from
+// JSON; see rates.json for the demo shop's.
+type RateCard struct {
+	// DefaultZone is the zone of shipments quoted without an address.
+	DefaultZone string `json:"default_zone"`
+
+	// Zones are matched against the destination in order; the first that
+	// matches is used.
+	Zones []Zone `json:"zones"`
+
+	ServiceLevels []ServiceLevel `json:"service_levels"`
+
+	// Products holds the parcel of one unit of each product, by product ID.
+	// Products not listed ship as DefaultProduct.
+	Products       map[string]Parcel `json:"products"`
+	DefaultProduct Parcel            `json:"default_product"`
+
+	// DimensionalDivisor converts a volume in cm³ into a dimensional weight
+	// in kg. Shipments are billed by the larger of their actual and
+	// dimensional weights.
+	DimensionalDivisor float64 `json:"dimensional_divisor"`
+
+	// Boxes are what ShipOrder packs orders in; see packItems. Without
+	// boxes every order ships as a single package.
+	Boxes []Box `json:"boxes,omitempty"`
+
+	// MaxUnits is the most units, summed over all items, that can be quoted
+	// or shipped at once; zero means defaultMaxUnits. Packing takes time
+	// and memory for every unit, so larger orders are rejected.
+	MaxUnits int64 `json:"max_units,omitempty"`
+}
+
+// Zone is a set of destinations sharing rates. A destination is in the zone
+// if its country is one of Countries and, when they are set, its state is
+// one of States and its zip code starts with one of ZipPrefixes. Names are
+// compared case-insensitively.
+type Zone struct {
+	Name        string   `json:"name"`
+	Countries   []string `json:"countries"`
+	States      []string `json:"states,omitempty"`
+	ZipPrefixes []string `json:"zip_prefixes,omitempty"`
+}
+
+// ServiceLevel is a delivery speed, such as "express", and its rates by zone.
+// A level without a rate for a zone is not offered there.
+type ServiceLevel struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	MinDays int32           `json:"min_days"`
+	MaxDays int32           `json:"max_days"`
+	Rates   map[string]Rate `json:"rates"`
+}
+
+// Rate prices a shipment in USD as Base plus PerKg for every billable kg. A
+// shipment worth at least FreeOver ships for free; a zero FreeOver never
+// does. Amounts are whole cents.
+type Rate struct {
+	Base     float64 `json:"base"`
+	PerKg    float64 `json:"per_kg"`
+	FreeOver float64 `json:"free_over,omitempty"`
+}
+
+// Parcel is the weight and dimensions of one unit of a product.
+type Parcel struct {
+	WeightKg float64 `json:"weight_kg"`
+	LengthCm float64 `json:"length_cm"`
+	WidthCm  float64 `json:"width_cm"`
+	HeightCm float64 `json:"height_cm"`
+}
+
+func (p Parcel) volume() float64 { return p.LengthCm * p.WidthCm * p.HeightCm }
+
+// rateCardFromEnv loads the rate card at RATE_CARD_PATH, rates.json by
+// default.
+func rateCardFromEnv() (*RateCard, error) {
+	path := os.Getenv("RATE_CARD_PATH")
+	if path == "" {
+		path = "rates.json"
+	}
+	return loadRateCard(path)
+}
+
+func loadRateCard(path string) (*RateCard, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate card: %v", err)
+	}
+	var c RateCard
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("failed to parse rate card %s: %v", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate card %s: %v", path, err)
+	}
+	return &c, nil
+}
+
+func (c *RateCard) validate() error {
+	if c.DimensionalDivisor <= 0 {
+		return fmt.Errorf("dimensional_divisor must be positive")
+	}
+	if c.MaxUnits < 0 {
+		return fmt.Errorf("max_units must not be negative")
+	}
+	zones := make(map[string]bool, len(c.Zones))
+	for _, z := range c.Zones {
+		if z.Name == "" || zones[z.Name] {
+			return fmt.Errorf("zone names must be unique and non-empty, got %q", z.Name)
+		}
+		if len(z.Countries) == 0 {
+			return fmt.Errorf("zone %q has no countries", z.Name)
+		}
+		zones[z.Name] = true
+	}
+	if !zones[c.DefaultZone] {
+		return fmt.Errorf("default zone %q is not defined", c.DefaultZone)
+	}
+
+	levels := make(map[string]bool, len(c.ServiceLevels))
+	for _, l := range c.ServiceLevels {
+		if l.ID == "" || levels[l.ID] {
+			return fmt.Errorf("service level IDs must be unique and non-empty, got %q", l.ID)
+		}
+		levels[l.ID] = true
+		if l.MinDays < 0 || l.MaxDays < l.MinDays {
+			return fmt.Errorf("service level %q has invalid delivery days %d-%d", l.ID, l.MinDays, l.MaxDays)
+		}
+		for zone, r := range l.Rates {
+			if !zones[zone] {
+				return fmt.Errorf("service level %q has a rate for unknown zone %q", l.ID, zone)
+			}
+			if r.Base < 0 || r.PerKg < 0 || r.FreeOver < 0 {
+				return fmt.Errorf("service level %q has a negative rate for zone %q", l.ID, zone)
+			}
+			if !isWholeCents(r.Base) || !isWholeCents(r.PerKg) || !isWholeCents(r.FreeOver) {
+				return fmt.Errorf("service level %q has a rate for zone %q that is not in whole cents", l.ID, zone)
+			}
+		}
+		if l.ID == defaultServiceLevel && len(l.Rates) != len(zones) {
+			return fmt.Errorf("service level %q must have a rate for every zone", l.ID)
+		}
+	}
+	if !levels[defaultServiceLevel] {
+		return fmt.Errorf("service level %q is not defined", defaultServiceLevel)
+	}
+
+	for id, p := range c.Products {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("product %q: %v", id, err)
+		}
+	}
+	if err := c.DefaultProduct.validate(); err != nil {
+		return fmt.Errorf("default product: %v", err)
+	}
+
+	boxes := make(map[string]bool, len(c.Boxes))
+	for _, b := range c.Boxes {
+		if b.ID == "" || boxes[b.ID] {
+			return fmt.Errorf("box IDs must be unique and non-empty, got %q", b.ID)
+		}
+		boxes[b.ID] = true
+		if b.LengthCm <= 0 || b.WidthCm <= 0 || b.HeightCm <= 0 || b.MaxWeightKg <= 0 {
+			return fmt.Errorf("box %q must have a positive size and maximum weight", b.ID)
+		}
+	}
+	return nil
+}
+
+func (p Parcel) validate() error {
+	if p.WeightKg < 0 || p.LengthCm < 0 || p.WidthCm < 0 || p.HeightCm < 0 {
+		return fmt.Errorf("negative weight or dimension")
+	}
+	return nil
+}
+
+// zoneFor returns the zone addr belongs to, or the default zone if addr is
+// nil.
+func (c *RateCard) zoneFor(addr *pb.Address) (string, error) {
+	if addr == nil {
+		return c.DefaultZone, nil
+	}
+	zip := strings.ReplaceAll(addr.GetPostalCode(), " ", "")
+	if zip == "" && addr.GetZipCode() != 0 {
+		zip = strconv.Itoa(int(addr.GetZipCode()))
+	}
+	for _, z := range c.Zones {
+		if z.matches(addr.GetCountry(), addr.GetState(), zip) {
+			return z.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no shipping to %q", addr.GetCountry())
+}
+
+func (z Zone) matches(country, state, zip string) bool {
+	if !containsFold(z.Countries, country) && !containsFold(z.Countries, anyCountry) {
+		return false
+	}
+	if len(z.States) > 0 && !containsFold(z.States, state) {
+		return false
+	}
+	if len(z.ZipPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range z.ZipPrefixes {
+		if strings.HasPrefix(zip, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	s = strings.TrimSpace(s)
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// billableWeight returns the weight items are billed by in kg: the larger of
+// their actual and dimensional weights, rounded up to weightIncrementKg.
+func (c *RateCard) billableWeight(items []*pb.CartItem) float64 {
+	var weight, volume float64
+	for _, item := range items {
+		p := c.parcel(item.GetProductId())
+		qty := float64(item.GetQuantity())
+		weight += p.WeightKg * qty
+		volume += p.volume() * qty
+	}
+	weight = math.Max(weight, volume/c.DimensionalDivisor)
+	return math.Ceil(weight/weightIncrementKg) * weightIncrementKg
+}
+
+// cost returns the cost of a shipment of weightKg billable kg worth
+// subtotalUSD, or an error if the shipment is too heavy to price.
+func (r Rate) cost(weightKg float64, subtotalUSD pb.Money) (Quote, error) {
+	if r.FreeOver > 0 && !lessUSD(subtotalUSD, usdCents(cents(r.FreeOver))) {
+		return newQuote(usdCents(0)), nil
+	}
+	if !(weightKg >= 0 && weightKg*weightIncrementsPerKg < math.MaxInt64) {
+		return Quote{}, fmt.Errorf("billable weight of %g kg is out of range", weightKg)
+	}
+	// A per-kg rate in whole cents costs a whole number of nanos per
+	// increment, so the total is exact until it is rounded.
+	increments := int64(math.Round(weightKg * weightIncrementsPerKg))
+	perIncrement := cents(r.PerKg) * nanosPerCent / weightIncrementsPerKg
+	base := cents(r.Base) * nanosPerCent
+	if perIncrement > 0 && increments > (math.MaxInt64-base)/perIncrement {
+		return Quote{}, fmt.Errorf("billable weight of %g kg is out of range", weightKg)
+	}
+	return newQuote(usdNanos(base + perIncrement*increments)), nil
+}
+
+// shippingOption is a service level priced for a shipment.
+type shippingOption struct {
+	level ServiceLevel
+	cost  Quote
+}
+
+// options prices items shipped to addr at every service level offered
+// there, cheapest first. subtotalUSD is the value of the items. An empty
+// shipment is free.
+func (c *RateCard) options(addr *pb.Address, items []*pb.CartItem, subtotalUSD pb.Money) ([]shippingOption, error) {
+	zone, err := c.zoneFor(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkUnits(items); err != nil {
+		return nil, err
+	}
+	weight := c.billableWeight(items)
+	var out []shippingOption
+	for _, l := range c.ServiceLevels {
+		r, ok := l.Rates[zone]
+		if !ok {
+			continue
+		}
+		cost := newQuote(usdCents(0))
+		if len(items) > 0 {
+			if cost, err = r.cost(weight, subtotalUSD); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, shippingOption{level: l, cost: cost})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].cost.less(out[j].cost) })
+	return out, nil
+}
+
+// checkUnits returns an error if an item has a quantity that is not
+// positive or items hold more units than the rate card allows.
+func (c *RateCard) checkUnits(items []*pb.CartItem) error {
+	max := c.MaxUnits
+	if max == 0 {
+		max = defaultMaxUnits
+	}
+	var units int64
+	for _, item := range items {
+		if item.GetQuantity() <= 0 {
+			return fmt.Errorf("quantity of product %q must be positive", item.GetProductId())
+		}
+		units += int64(item.GetQuantity())
+	}
+	if units > max {
+		return fmt.Errorf("shipments hold at most %d units, got %d", max, units)
+	}
+	return nil
+}
+
+// serviceLevel returns the service level with the given ID.
+func (c *RateCard) serviceLevel(id string) (ServiceLevel, bool) {
+	for _, l := range c.ServiceLevels {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return ServiceLevel{}, false
+}
+
+// displayName describes the option to customers, as in
+// "Express (2-3 days)".
+func (l ServiceLevel) displayName() string {
+	days := fmt.Sprintf("%d-%d days", l.MinDays, l.MaxDays)
+	if l.MinDays == l.MaxDays {
+		days = fmt.Sprintf("%d day", l.MinDays)
+		if l.MinDays != 1 {
+			days += "s"
+		}
+	}
+	return fmt.Sprintf("%s (%s)", l.Name, days)
+}
@@ -0,0 +1,197 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+func testRateCard() *RateCard {
+	return &RateCard{
+		DefaultZone:        "domestic",
+		DimensionalDivisor: 5000,
+		Zones: []Zone{
+			{Name: "remote", Countries: []string{"US"}, States: []string{"AK"}},
+			{Name: "metro", Countries: []string{"US"}, ZipPrefixes: []string{"100", "941"}},
+			{Name: "domestic", Countries: []string{"US", "United States"}},
+			{Name: "world", Countries: []string{"*"}},
+		},
+		ServiceLevels: []ServiceLevel{
+			{ID: "express", Name: "Express", MinDays: 1, MaxDays: 2, Rates: map[string]Rate{
+				"domestic": {Base: 10, PerKg: 2},
+			}},
+			{ID: "standard", Name: "Standard", MinDays: 3, MaxDays: 5, Rates: map[string]Rate{
+				"remote":   {Base: 8, PerKg: 1},
+				"metro":    {Base: 3, PerKg: 1},
+				"domestic": {Base: 5, PerKg: 1, FreeOver: 50},
+				"world":    {Base: 20, PerKg: 2},
+			}},
+		},
+		Products: map[string]Parcel{
+			"heavy": {WeightKg: 4, LengthCm: 10, WidthCm: 10, HeightCm: 10},
+			"bulky": {WeightKg: 1, LengthCm: 50, WidthCm: 50, HeightCm: 10},
+		},
+		DefaultProduct: Parcel{WeightKg: 0.2},
+	}
+}
+
+func TestZoneFor(t *testing.T) {
+	c := testRateCard()
+	for _, tc := range []struct {
+		addr *pb.Address
+		want string
+	}{
+		{nil, "domestic"},
+		{&pb.Address{Country: "US", State: "AK", ZipCode: 99501}, "remote"},
+		{&pb.Address{Country: "us", State: "ak"}, "remote"},
+		{&pb.Address{Country: "US", State: "CA", ZipCode: 94103}, "metro"},
+		{&pb.Address{Country: "US", State: "CA", ZipCode: 95014}, "domestic"},
+		{&pb.Address{Country: " United States ", State: "NY", ZipCode: 10001}, "domestic"},
+		{&pb.Address{Country: "France"}, "world"},
+	} {
+		got, err := c.zoneFor(tc.addr)
+		if err != nil || got != tc.want {
+			t.Errorf("zoneFor(%v) = %q, %v; want %q", tc.addr, got, err, tc.want)
+		}
+	}
+
+	c.Zones = c.Zones[:3]
+	if _, err := c.zoneFor(&pb.Address{Country: "France"}); err == nil {
+		t.Error("zoneFor() of an address outside every zone succeeded")
+	}
+}
+
+func TestBillableWeight(t *testing.T) {
+	c := testRateCard()
+	for _, tc := range []struct {
+		name  string
+		items []*pb.CartItem
+		want  float64
+	}{
+		{"empty", nil, 0},
+		{"actual weight", []*pb.CartItem{{ProductId: "heavy", Quantity: 2}}, 8},
+		{"dimensional weight", []*pb.CartItem{{ProductId: "bulky", Quantity: 1}}, 5},
+		{"rounded up", []*pb.CartItem{{ProductId: "unknown", Quantity: 3}}, 1},
+		{"mixed", []*pb.CartItem{{ProductId: "heavy", Quantity: 1}, {ProductId: "bulky", Quantity: 1}}, 5.5},
+	} {
+		if got := c.billableWeight(tc.items); got != tc.want {
+			t.Errorf("%s: billableWeight() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	c := testRateCard()
+	heavy := []*pb.CartItem{{ProductId: "heavy", Quantity: 1}}
+	for _, tc := range []struct {
+		name     string
+		addr     *pb.Address
+		items    []*pb.CartItem
+		subtotal int64 // in cents
+		want     map[string]string
+		order    string
+	}{
+		{"domestic", nil, heavy, 0, map[string]string{"standard": "$9.00", "express": "$18.00"}, "standard,express"},
+		{"free standard", nil, heavy, 5000, map[string]string{"standard": "$0.00", "express": "$18.00"}, "standard,express"},
+		{"below threshold", nil, heavy, 4999, map[string]string{"standard": "$9.00", "express": "$18.00"}, "standard,express"},
+		{"standard only", &pb.Address{Country: "Japan"}, heavy, 100000, map[string]string{"standard": "$28.00"}, "standard"},
+		{"empty shipment", nil, nil, 0, map[string]string{"standard": "$0.00", "express": "$0.00"}, "express,standard"},
+	} {
+		opts, err := c.options(tc.addr, tc.items, usdCents(tc.subtotal))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var order []string
+		for _, o := range opts {
+			order = append(order, o.level.ID)
+			if want, ok := tc.want[o.level.ID]; !ok || o.cost.String() != want {
+				t.Errorf("%s: %s costs %v, want %v", tc.name, o.level.ID, o.cost, want)
+			}
+		}
+		if got := strings.Join(order, ","); got != tc.order {
+			t.Errorf("%s: options %s, want %s", tc.name, got, tc.order)
+		}
+	}
+}
+
+func TestRateCardValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(c *RateCard)
+		want   string
+	}{
+		{"valid", func(c *RateCard) {}, ""},
+		{"no divisor", func(c *RateCard) { c.DimensionalDivisor = 0 }, "dimensional_divisor"},
+		{"unknown default zone", func(c *RateCard) { c.DefaultZone = "moon" }, "default zone"},
+		{"duplicate zone", func(c *RateCard) { c.Zones = append(c.Zones, c.Zones[0]) }, "unique"},
+		{"zone without countries", func(c *RateCard) { c.Zones[0].Countries = nil }, "no countries"},
+		{"rate for unknown zone", func(c *RateCard) { c.ServiceLevels[0].Rates["moon"] = Rate{} }, "unknown zone"},
+		{"negative rate", func(c *RateCard) { c.ServiceLevels[0].Rates["domestic"] = Rate{Base: -1} }, "negative rate"},
+		{"bad days", func(c *RateCard) { c.ServiceLevels[0].MaxDays = 0 }, "delivery days"},
+		{"no standard", func(c *RateCard) { c.ServiceLevels = c.ServiceLevels[:1] }, `"standard" is not defined`},
+		{"partial standard", func(c *RateCard) { delete(c.ServiceLevels[1].Rates, "world") }, "every zone"},
+		{"negative weight", func(c *RateCard) { c.Products["heavy"] = Parcel{WeightKg: -1} }, "negative weight"},
+		{"duplicate box", func(c *RateCard) { c.Boxes = []Box{{"a", 1, 1, 1, 1}, {"a", 1, 1, 1, 1}} }, "box IDs"},
+		{"fractional cents", func(c *RateCard) { c.ServiceLevels[0].Rates["domestic"] = Rate{Base: 4.995} }, "whole cents"},
+		{"flat box", func(c *RateCard) { c.Boxes = []Box{{ID: "a", LengthCm: 1, WidthCm: 1, MaxWeightKg: 1}} }, "positive size"},
+	} {
+		c := testRateCard()
+		tc.modify(c)
+		err := c.validate()
+		if tc.want == "" && err != nil || tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)) {
+			t.Errorf("%s: validate() = %v, want error containing %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestLoadRateCard(t *testing.T) {
+	if _, err := loadRateCard("rates.json"); err != nil {
+		t.Errorf("rates.json: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "rates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rates.json")
+	if err := ioutil.WriteFile(path, []byte(`{"default_zone": "us", "zone": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadRateCard(path); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("loadRateCard() of a misspelt field = %v, want an unknown field error", err)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	for _, tc := range []struct {
+		level ServiceLevel
+		want  string
+	}{
+		{ServiceLevel{Name: "Standard", MinDays: 5, MaxDays: 7}, "Standard (5-7 days)"},
+		{ServiceLevel{Name: "Overnight", MinDays: 1, MaxDays: 1}, "Overnight (1 day)"},
+		{ServiceLevel{Name: "Freight", MinDays: 10, MaxDays: 10}, "Freight (10 days)"},
+	} {
+		if got := tc.level.displayName(); got != tc.want {
+			t.Errorf("displayName() = %q, want %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+var (
+	errShipmentNotFound = errors.New("shipment not found")
+	errShipmentExists   = errors.New("tracking ID already in use")
+)
+
+// shipment is a shipped order. Its tracking history is not stored but
+// derived from when it was created; see lifecycle.
+type shipment struct {
+	TrackingID   string
+	ServiceLevel string
+	// DeliveryDays is how many days after its creation the shipment is
+	// delivered.
+	DeliveryDays int32
+	Address      *pb.Address
+	CreatedAt    time.Time
+}
+
+// shipmentStore persists shipments.
+type shipmentStore interface {
+	// Create stores a new shipment, or returns errShipmentExists if its
+	// tracking ID is taken.
+	Create(ctx context.Context, s *shipment) error
+	// Get returns the shipment with the given tracking ID or
+	// errShipmentNotFound.
+	Get(ctx context.Context, trackingID string) (*shipment, error)
+	Close() error
+}
+
+// newShipmentStoreFromEnv creates the store selected by the SHIPMENT_STORE
+// environment variable ("memory" or "sqlite").
+func newShipmentStoreFromEnv() (shipmentStore, error) {
+	path := os.Getenv("SHIPMENT_STORE_PATH")
+	switch kind := os.Getenv("SHIPMENT_STORE"); kind {
+	case "", "memory":
+		return newMemoryShipmentStore(), nil
+	case "sqlite":
+		if path == "" {
+			path = "shipments.db"
+		}
+		return newSQLiteShipmentStore(path)
+	default:
+		return nil, fmt.Errorf("unknown shipment store %q", kind)
+	}
+}
+
+// memoryShipmentStore keeps shipments in process memory.
+type memoryShipmentStore struct {
+	mu   sync.RWMutex
+	byID map[string]*shipment
+}
+
+func newMemoryShipmentStore() *memoryShipmentStore {
+	return &memoryShipmentStore{byID: make(map[string]*shipment)}
+}
+
+func (m *memoryShipmentStore) Create(ctx context.Context, s *shipment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.byID[s.TrackingID]; ok {
+		return errShipmentExists
+	}
+	// Callers may reuse the address they passed in, so keep a copy.
+	stored := *s
+	if s.Address != nil {
+		stored.Address = proto.Clone(s.Address).(*pb.Address)
+	}
+	m.byID[s.TrackingID] = &stored
+	return nil
+}
+
+func (m *memoryShipmentStore) Get(ctx context.Context, trackingID string) (*shipment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.byID[trackingID]
+	if !ok {
+		return nil, errShipmentNotFound
+	}
+	return s, nil
+}
+
+func (m *memoryShipmentStore) Close() error { return nil }
@@ -0,0 +1,101 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/mattn/go-sqlite3"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+const sqliteShipmentsSchema = `
+CREATE TABLE IF NOT EXISTS shipments (
+	tracking_id   TEXT PRIMARY KEY,
+	service_level TEXT NOT NULL,
+	delivery_days INTEGER NOT NULL,
+	created_at    INTEGER NOT NULL,
+	address_pb    BLOB
+);
+`
+
+// sqliteShipmentStore stores shipments in a SQLite database, so that they
+// can still be tracked after a restart.
+type sqliteShipmentStore struct {
+	db *sql.DB
+}
+
+func newSQLiteShipmentStore(path string) (*sqliteShipmentStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shipment database %s: %+v", path, err)
+	}
+	// SQLite allows a single writer; serializing access through one
+	// connection avoids "database is locked" errors.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteShipmentsSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create shipment schema: %+v", err)
+	}
+	return &sqliteShipmentStore{db: db}, nil
+}
+
+func (r *sqliteShipmentStore) Create(ctx context.Context, s *shipment) error {
+	var addr []byte
+	if s.Address != nil {
+		var err error
+		if addr, err = proto.Marshal(s.Address); err != nil {
+			return fmt.Errorf("failed to serialize address: %+v", err)
+		}
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO shipments (tracking_id, service_level, delivery_days, created_at, address_pb) VALUES (?, ?, ?, ?, ?)`,
+		s.TrackingID, s.ServiceLevel, s.DeliveryDays, s.CreatedAt.UnixNano(), addr)
+	if e, ok := err.(sqlite3.Error); ok && e.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return errShipmentExists
+	}
+	return err
+}
+
+func (r *sqliteShipmentStore) Get(ctx context.Context, trackingID string) (*shipment, error) {
+	var (
+		s         = shipment{TrackingID: trackingID}
+		createdAt int64
+		addr      []byte
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT service_level, delivery_days, created_at, address_pb FROM shipments WHERE tracking_id = ?`, trackingID).
+		Scan(&s.ServiceLevel, &s.DeliveryDays, &createdAt, &addr)
+	if err == sql.ErrNoRows {
+		return nil, errShipmentNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	s.CreatedAt = time.Unix(0, createdAt)
+	if addr != nil {
+		s.Address = new(pb.Address)
+		if err := proto.Unmarshal(addr, s.Address); err != nil {
+			return nil, fmt.Errorf("failed to parse address of shipment %s: %+v", trackingID, err)
+		}
+	}
+	return &s, nil
+}
+
+func (r *sqliteShipmentStore) Close() error { return r.db.Close() }
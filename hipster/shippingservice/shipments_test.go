@@ -0,0 +1,91 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+func testShipmentStore(t *testing.T, s shipmentStore) {
+	ctx := context.Background()
+	created := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	addr := &pb.Address{StreetAddress: "1600 Amphitheatre Parkway", City: "Mountain View", Country: "US"}
+	want := &shipment{TrackingID: "AB-1", ServiceLevel: "express", DeliveryDays: 3, Address: addr, CreatedAt: created}
+	if err := s.Create(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	addr.City = "changed"
+	if err := s.Create(ctx, &shipment{TrackingID: "AB-1", ServiceLevel: "standard"}); err != errShipmentExists {
+		t.Errorf("Create() of a taken tracking ID = %v, want %v", err, errShipmentExists)
+	}
+	if err := s.Create(ctx, &shipment{TrackingID: "AB-2", ServiceLevel: "standard", CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Get(ctx, "AB-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ServiceLevel != "express" || got.DeliveryDays != 3 || !got.CreatedAt.Equal(created) ||
+		got.Address.GetCity() != "Mountain View" || !proto.Equal(got.Address, &pb.Address{
+		StreetAddress: "1600 Amphitheatre Parkway", City: "Mountain View", Country: "US"}) {
+		t.Errorf("Get(AB-1) = %+v", got)
+	}
+	if got, err := s.Get(ctx, "AB-2"); err != nil || got.Address != nil {
+		t.Errorf("Get(AB-2) = %+v, %v; want no address", got, err)
+	}
+	if _, err := s.Get(ctx, "missing"); err != errShipmentNotFound {
+		t.Errorf("Get(missing) error = %v, want %v", err, errShipmentNotFound)
+	}
+}
+
+func TestMemoryShipmentStore(t *testing.T) {
+	testShipmentStore(t, newMemoryShipmentStore())
+}
+
+func TestSQLiteShipmentStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shipments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "shipments.db")
+
+	s, err := newSQLiteShipmentStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testShipmentStore(t, s)
+	s.Close()
+
+	// Shipments survive a restart.
+	s, err = newSQLiteShipmentStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Get(context.Background(), "AB-1"); err != nil {
+		t.Errorf("Get(AB-1) after reopening: %v", err)
+	}
+}
@@ -15,16 +15,46 @@
 package main
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
 )
 
+// testAddress is a complete US address.
+var testAddress = &pb.Address{
+	StreetAddress: "1600 Amphitheatre Parkway",
+	City:          "Mountain View",
+	State:         "CA",
+	Country:       "US",
+	PostalCode:    "94043",
+}
+
+func newTestServer(t *testing.T) *server {
+	t.Helper()
+	rates, err := loadRateCard("rates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &server{
+		rates:     rates,
+		shipments: newMemoryShipmentStore(),
+		lifecycle: lifecycle{clock: realClock{}, dayLength: defaultDayLength},
+		stopping:  make(chan struct{}),
+
+		trackingIdFormat: DefaultTrackingIdFormat,
+		trackingIds:      NewRandomTrackingIdGenerator(DefaultTrackingIdFormat),
+	}
+}
+
 // TestGetQuote is a basic check on the GetQuote RPC service.
 func TestGetQuote(t *testing.T) {
-	s := server{}
+	s := newTestServer(t)
 
 	// A basic test case to test logic and protobuf interactions.
 	req := &pb.GetQuoteRequest{
@@ -33,6 +63,7 @@ func TestGetQuote(t *testing.T) {
 			City:          "London",
 			State:         "",
 			Country:       "England",
+			PostalCode:    "WC2B 5PW",
 		},
 		Items: []*pb.CartItem{
 			{
@@ -50,14 +81,66 @@ func TestGetQuote(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestGetQuote (%v) failed", err)
 	}
-	if res.CostUsd.GetUnits() != 11 || res.CostUsd.GetNanos() != 220000000 {
-		t.Errorf("TestGetQuote: Quote value '%d.%d' does not match expected '%s'", res.CostUsd.GetUnits(), res.CostUsd.GetNanos(), "11.220000000")
+	// Four unknown products ship internationally as 6.5 kg dimensional
+	// weight.
+	if res.CostUsd.GetUnits() != 41 || res.CostUsd.GetNanos() != 240000000 {
+		t.Errorf("TestGetQuote: Quote value '%d.%d' does not match expected '%s'", res.CostUsd.GetUnits(), res.CostUsd.GetNanos(), "41.240000000")
+	}
+	var levels []string
+	for _, o := range res.GetOptions() {
+		levels = append(levels, o.GetServiceLevel())
+	}
+	if got := strings.Join(levels, ","); got != "standard,express" {
+		t.Errorf("TestGetQuote: options %s, want standard,express", got)
+	}
+}
+
+func TestGetQuoteServiceLevels(t *testing.T) {
+	s := newTestServer(t)
+	// The typewriter is billed by its 7.5 kg dimensional weight.
+	items := []*pb.CartItem{{ProductId: "OLJCESPC7Z", Quantity: 1}}
+	home := &pb.Address{StreetAddress: "1600 Amphitheatre Parkway", City: "Mountain View",
+		State: "CA", Country: "United States", ZipCode: 94043}
+	paris := &pb.Address{StreetAddress: "8 Rue de Londres", City: "Paris", Country: "France", PostalCode: "75009"}
+
+	for _, tc := range []struct {
+		name     string
+		req      *pb.GetQuoteRequest
+		want     string
+		wantCode codes.Code
+	}{
+		{"default level", &pb.GetQuoteRequest{Address: home, Items: items}, "8.74", codes.OK},
+		{"no address", &pb.GetQuoteRequest{Items: items}, "8.74", codes.OK},
+		{"express", &pb.GetQuoteRequest{Address: home, Items: items, ServiceLevel: "express"}, "26.24", codes.OK},
+		{"overnight", &pb.GetQuoteRequest{Address: home, Items: items, ServiceLevel: "overnight"}, "57.49", codes.OK},
+		{"free standard", &pb.GetQuoteRequest{Address: home, Items: items,
+			SubtotalUsd: &pb.Money{CurrencyCode: "USD", Units: 100}}, "0.00", codes.OK},
+		{"express never free", &pb.GetQuoteRequest{Address: home, Items: items, ServiceLevel: "express",
+			SubtotalUsd: &pb.Money{CurrencyCode: "USD", Units: 100}}, "26.24", codes.OK},
+		{"overnight abroad", &pb.GetQuoteRequest{Address: paris, Items: items,
+			ServiceLevel: "overnight"}, "", codes.InvalidArgument},
+		{"unknown level", &pb.GetQuoteRequest{Items: items, ServiceLevel: "teleport"}, "", codes.InvalidArgument},
+		{"subtotal not in USD", &pb.GetQuoteRequest{Items: items,
+			SubtotalUsd: &pb.Money{CurrencyCode: "EUR", Units: 100}}, "", codes.InvalidArgument},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := s.GetQuote(context.Background(), tc.req)
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("GetQuote() = %v, want %v", err, tc.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if got := fmt.Sprintf("%d.%02d", res.CostUsd.GetUnits(), res.CostUsd.GetNanos()/10000000); got != tc.want {
+				t.Errorf("GetQuote() cost = %s, want %s", got, tc.want)
+			}
+		})
 	}
 }
 
 // TestShipOrder is a basic check on the ShipOrder RPC service.
 func TestShipOrder(t *testing.T) {
-	s := server{}
+	s := newTestServer(t)
 
 	// A basic test case to test logic and protobuf interactions.
 	req := &pb.ShipOrderRequest{
@@ -66,6 +149,7 @@ func TestShipOrder(t *testing.T) {
 			City:          "London",
 			State:         "",
 			Country:       "England",
+			PostalCode:    "WC2B 5PW",
 		},
 		Items: []*pb.CartItem{
 			{
@@ -83,8 +167,10 @@ func TestShipOrder(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestShipOrder (%v) failed", err)
 	}
-	// @todo improve quality of this test to check for a pattern such as '[A-Z]{2}-\d+-\d+'.
-	if len(res.TrackingId) != 18 {
-		t.Errorf("TestShipOrder: Tracking ID is malformed - has %d characters, %d expected", len(res.TrackingId), 18)
+	if err := ValidateTrackingId(res.TrackingId); err != nil {
+		t.Errorf("TestShipOrder: Tracking ID is malformed: %v", err)
+	}
+	if got := res.GetAddress(); got.GetCountry() != "GB" || got.GetPostalCode() != "WC2B 5PW" {
+		t.Errorf("TestShipOrder: address = %v, want it normalized", got)
 	}
 }
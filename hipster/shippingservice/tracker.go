@@ -15,42 +15,210 @@
 package main
 
 import (
+	crand "crypto/rand"
+	"encoding/binary"
 	"fmt"
 	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// seeded determines if the random number generator is ready.
-var seeded bool = false
+// CheckDigitScheme computes the check digit of a tracking ID's body.
+type CheckDigitScheme string
 
-// CreateTrackingId generates a tracking ID.
-func CreateTrackingId(salt string) string {
-	if !seeded {
-		rand.Seed(time.Now().UnixNano())
-		seeded = true
+const (
+	// Mod10 is the Luhn algorithm. It detects every single-digit error and
+	// most adjacent transpositions.
+	Mod10 CheckDigitScheme = "mod10"
+	// Mod11 weighs the digits 2 to 7 from the right, modulo 11. It detects
+	// every single-digit error and every adjacent transposition. Bodies
+	// whose check would be 10 are never issued.
+	Mod11 CheckDigitScheme = "mod11"
+)
+
+// checkDigit returns the check digit of body, a string of decimal digits,
+// and false if the scheme cannot protect body.
+func (s CheckDigitScheme) checkDigit(body string) (byte, bool) {
+	switch s {
+	case Mod10:
+		sum := 0
+		for i := 0; i < len(body); i++ {
+			d := int(body[len(body)-1-i] - '0')
+			if i%2 == 0 {
+				if d *= 2; d > 9 {
+					d -= 9
+				}
+			}
+			sum += d
+		}
+		return byte('0' + (10-sum%10)%10), true
+	case Mod11:
+		sum := 0
+		for i := 0; i < len(body); i++ {
+			sum += int(body[len(body)-1-i]-'0') * (2 + i%6)
+		}
+		c := (11 - sum%11) % 11
+		return byte('0' + c), c < 10
+	default:
+		return 0, false
+	}
+}
+
+// TrackingIdFormat describes tracking IDs: a carrier prefix of upper case
+// letters, a dash, and a body of Digits decimal digits followed by a check
+// digit, as in "HS-0012345678905".
+type TrackingIdFormat struct {
+	Prefix string
+	Digits int
+	Check  CheckDigitScheme
+}
+
+// DefaultTrackingIdFormat is the format of the IDs the service issues
+// unless configured otherwise.
+var DefaultTrackingIdFormat = TrackingIdFormat{Prefix: "HS", Digits: 12, Check: Mod10}
+
+func (f TrackingIdFormat) validate() error {
+	if len(f.Prefix) < 1 || len(f.Prefix) > 4 || strings.Trim(f.Prefix, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("tracking ID prefix must be 1 to 4 upper case letters, got %q", f.Prefix)
+	}
+	if f.Digits < 6 || f.Digits > 18 {
+		return fmt.Errorf("tracking IDs must have 6 to 18 digits, got %d", f.Digits)
+	}
+	if _, ok := f.Check.checkDigit("0"); !ok {
+		return fmt.Errorf("unknown check digit scheme %q", f.Check)
+	}
+	return nil
+}
+
+// modulus is the number of distinct bodies.
+func (f TrackingIdFormat) modulus() uint64 {
+	m := uint64(1)
+	for i := 0; i < f.Digits; i++ {
+		m *= 10
+	}
+	return m
+}
+
+// format returns the tracking ID of body, and false if body cannot be
+// issued under the check digit scheme.
+func (f TrackingIdFormat) format(body uint64) (string, bool) {
+	digits := fmt.Sprintf("%0*d", f.Digits, body%f.modulus())
+	check, ok := f.Check.checkDigit(digits)
+	if !ok {
+		return "", false
+	}
+	return f.Prefix + "-" + digits + string(check), true
+}
+
+// ValidateTrackingId checks that id is in format f and that its check
+// digit matches.
+func (f TrackingIdFormat) ValidateTrackingId(id string) error {
+	prefix := f.Prefix + "-"
+	if !strings.HasPrefix(id, prefix) {
+		return fmt.Errorf("tracking ID %q does not start with %q", id, prefix)
+	}
+	rest := id[len(prefix):]
+	if len(rest) != f.Digits+1 || strings.Trim(rest, "0123456789") != "" {
+		return fmt.Errorf("tracking ID %q must end in %d digits", id, f.Digits+1)
 	}
+	check, ok := f.Check.checkDigit(rest[:f.Digits])
+	if !ok || check != rest[f.Digits] {
+		return fmt.Errorf("tracking ID %q has an invalid check digit", id)
+	}
+	return nil
+}
+
+// ValidateTrackingId checks id against DefaultTrackingIdFormat.
+func ValidateTrackingId(id string) error {
+	return DefaultTrackingIdFormat.ValidateTrackingId(id)
+}
+
+// TrackingIdGenerator issues tracking IDs. Implementations are safe for
+// concurrent use.
+type TrackingIdGenerator interface {
+	NewTrackingId() string
+}
+
+// randomTrackingIds issues IDs with random bodies. With 12 digits a
+// collision is unlikely but possible, so callers must check that an ID is
+// unused.
+type randomTrackingIds struct {
+	format TrackingIdFormat
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewRandomTrackingIdGenerator returns a generator of random IDs in f. Its
+// source is seeded from crypto/rand so that replicas do not issue the same
+// sequence.
+func NewRandomTrackingIdGenerator(f TrackingIdFormat) TrackingIdGenerator {
+	var seed [8]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		binary.LittleEndian.PutUint64(seed[:], uint64(time.Now().UnixNano()))
+	}
+	return &randomTrackingIds{format: f, rnd: rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(seed[:]))))}
+}
+
+func (g *randomTrackingIds) NewTrackingId() string {
+	for {
+		g.mu.Lock()
+		body := uint64(g.rnd.Int63n(int64(g.format.modulus())))
+		g.mu.Unlock()
+		if id, ok := g.format.format(body); ok {
+			return id
+		}
+	}
+}
 
-	return fmt.Sprintf("%c%c-%d%s-%d%s",
-		getRandomLetterCode(),
-		getRandomLetterCode(),
-		len(salt),
-		getRandomNumber(3),
-		len(salt)/2,
-		getRandomNumber(7),
-	)
+// sequentialTrackingIds issues IDs with consecutive bodies, which never
+// collide until the body wraps around.
+type sequentialTrackingIds struct {
+	format TrackingIdFormat
+	next   uint64
 }
 
-// getRandomLetterCode generates a code point value for a capital letter.
-func getRandomLetterCode() uint32 {
-	return 65 + uint32(rand.Intn(25))
+// NewSequentialTrackingIdGenerator returns a generator of consecutive IDs
+// in f, starting with body start.
+func NewSequentialTrackingIdGenerator(f TrackingIdFormat, start uint64) TrackingIdGenerator {
+	return &sequentialTrackingIds{format: f, next: start}
 }
 
-// getRandomNumber generates a string representation of a number with the requested number of digits.
-func getRandomNumber(digits int) string {
-	str := ""
-	for i := 0; i < digits; i++ {
-		str = fmt.Sprintf("%s%d", str, rand.Intn(10))
+func (g *sequentialTrackingIds) NewTrackingId() string {
+	for {
+		body := atomic.AddUint64(&g.next, 1) - 1
+		if id, ok := g.format.format(body); ok {
+			return id
+		}
 	}
+}
 
-	return str
+// trackingIdsFromEnv returns the tracking ID format and generator
+// configured by TRACKING_ID_PREFIX, TRACKING_ID_CHECK ("mod10" or "mod11")
+// and TRACKING_ID_GENERATOR ("random" or "sequential"). A sequential
+// generator starts from the current time in milliseconds, so that a
+// restarted service does not reissue IDs while it issues fewer than a
+// thousand a second.
+func trackingIdsFromEnv() (TrackingIdFormat, TrackingIdGenerator, error) {
+	f := DefaultTrackingIdFormat
+	if p := os.Getenv("TRACKING_ID_PREFIX"); p != "" {
+		f.Prefix = p
+	}
+	if c := os.Getenv("TRACKING_ID_CHECK"); c != "" {
+		f.Check = CheckDigitScheme(c)
+	}
+	if err := f.validate(); err != nil {
+		return f, nil, err
+	}
+	switch kind := os.Getenv("TRACKING_ID_GENERATOR"); kind {
+	case "", "random":
+		return f, NewRandomTrackingIdGenerator(f), nil
+	case "sequential":
+		return f, NewSequentialTrackingIdGenerator(f, uint64(time.Now().UnixNano()/int64(time.Millisecond))), nil
+	default:
+		return f, nil, fmt.Errorf("unknown tracking ID generator %q", kind)
+	}
 }
@@ -0,0 +1,253 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"testing/quick"
+)
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		scheme CheckDigitScheme
+		body   string
+		want   byte
+		ok     bool
+	}{
+		{Mod10, "7992739871", '3', true},
+		{Mod10, "000000000000", '0', true},
+		{Mod10, "1", '8', true},
+		{Mod11, "123456789", '2', true},
+		{Mod11, "000000000000", '0', true},
+		{Mod11, "5", '1', true},
+		{Mod11, "6", 0, false},
+		{"mod9", "1", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.scheme.checkDigit(tt.body)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("%s.checkDigit(%q) = %q, %v; want %q, %v", tt.scheme, tt.body, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// mutations returns the bodies one typing error away from body: every
+// single-digit substitution and, if transpositions is set, every swap of
+// distinct adjacent digits.
+func mutations(body string, transpositions bool) []string {
+	var out []string
+	for i := range body {
+		for d := byte('0'); d <= '9'; d++ {
+			if d != body[i] {
+				b := []byte(body)
+				b[i] = d
+				out = append(out, string(b))
+			}
+		}
+		if transpositions && i+1 < len(body) && body[i] != body[i+1] {
+			b := []byte(body)
+			b[i], b[i+1] = b[i+1], b[i]
+			out = append(out, string(b))
+		}
+	}
+	return out
+}
+
+func TestCheckDigitDetectsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		scheme         CheckDigitScheme
+		transpositions bool
+	}{
+		{Mod10, false},
+		{Mod11, true},
+	} {
+		f := TrackingIdFormat{Prefix: "HS", Digits: 12, Check: tt.scheme}
+		detects := func(body uint64) bool {
+			id, ok := f.format(body)
+			if !ok {
+				return true
+			}
+			digits := id[len("HS-") : len(id)-1]
+			for _, m := range mutations(digits, tt.transpositions) {
+				if f.ValidateTrackingId("HS-"+m+id[len(id)-1:]) == nil {
+					t.Logf("%s: %s validates after mutating it to %s", tt.scheme, id, m)
+					return false
+				}
+			}
+			return true
+		}
+		if err := quick.Check(detects, nil); err != nil {
+			t.Errorf("%s: %v", tt.scheme, err)
+		}
+	}
+}
+
+func TestGeneratedIdsValidate(t *testing.T) {
+	for _, check := range []CheckDigitScheme{Mod10, Mod11} {
+		f := TrackingIdFormat{Prefix: "ABC", Digits: 8, Check: check}
+		random := NewRandomTrackingIdGenerator(f)
+		valid := func(start uint64) bool {
+			seq := NewSequentialTrackingIdGenerator(f, start)
+			for _, id := range []string{random.NewTrackingId(), seq.NewTrackingId()} {
+				if err := f.ValidateTrackingId(id); err != nil {
+					t.Log(err)
+					return false
+				}
+			}
+			return true
+		}
+		if err := quick.Check(valid, nil); err != nil {
+			t.Errorf("%s: %v", check, err)
+		}
+	}
+}
+
+func TestValidateTrackingId(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"HS-0000000000000", true},
+		{"HS-0012345678905", false},
+		{"", false},
+		{"HS-", false},
+		{"XX-0000000000000", false},
+		{"HS0000000000000", false},
+		{"HS-000000000000", false},
+		{"HS-00000000000000", false},
+		{"HS-00000000000a0", false},
+		{"HS-0000000000001", false},
+		{"hs-0000000000000", false},
+	}
+	id, _ := DefaultTrackingIdFormat.format(1234567890)
+	tests = append(tests, struct {
+		id    string
+		valid bool
+	}{id, true})
+	for _, tt := range tests {
+		if err := ValidateTrackingId(tt.id); (err == nil) != tt.valid {
+			t.Errorf("ValidateTrackingId(%q) = %v, want valid %v", tt.id, err, tt.valid)
+		}
+	}
+}
+
+func TestSequentialTrackingIds(t *testing.T) {
+	f := TrackingIdFormat{Prefix: "HS", Digits: 6, Check: Mod11}
+	g := NewSequentialTrackingIdGenerator(f, 0)
+	prev := ""
+	for i := 0; i < 1000; i++ {
+		id := g.NewTrackingId()
+		if id <= prev {
+			t.Fatalf("%s issued after %s", id, prev)
+		}
+		if _, ok := Mod11.checkDigit(id[3 : len(id)-1]); !ok {
+			t.Fatalf("%s issued with an unprotected body", id)
+		}
+		prev = id
+	}
+	// Bodies whose mod 11 check would be 10 are skipped, so the generator
+	// must have moved past about one in eleven.
+	var last uint64
+	fmt.Sscanf(prev[3:len(prev)-1], "%d", &last)
+	skipped := int(last) + 1 - 1000
+	if skipped < 50 || skipped > 130 {
+		t.Errorf("skipped %d of %d bodies, want about 1 in 11", skipped, last+1)
+	}
+}
+
+func TestTrackingIdsConcurrent(t *testing.T) {
+	const goroutines, perGoroutine = 16, 500
+	f := DefaultTrackingIdFormat
+	for _, g := range []struct {
+		name   string
+		gen    TrackingIdGenerator
+		unique bool
+	}{
+		{"random", NewRandomTrackingIdGenerator(f), false},
+		{"sequential", NewSequentialTrackingIdGenerator(f, 42), true},
+	} {
+		var (
+			mu   sync.Mutex
+			seen = make(map[string]bool)
+			wg   sync.WaitGroup
+		)
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < perGoroutine; j++ {
+					id := g.gen.NewTrackingId()
+					if err := f.ValidateTrackingId(id); err != nil {
+						t.Errorf("%s: %v", g.name, err)
+					}
+					mu.Lock()
+					if seen[id] && g.unique {
+						t.Errorf("%s: %s issued twice", g.name, id)
+					}
+					seen[id] = true
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func setenv(t *testing.T, key, value string) {
+	old, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestTrackingIdsFromEnv(t *testing.T) {
+	tests := []struct {
+		prefix, check, generator string
+		wantErr                  bool
+	}{
+		{"", "", "", false},
+		{"ACME", "mod11", "sequential", false},
+		{"", "", "random", false},
+		{"acme", "", "", true},
+		{"TOOLONG", "", "", true},
+		{"H1", "", "", true},
+		{"", "mod9", "", true},
+		{"", "", "uuid", true},
+	}
+	for _, tt := range tests {
+		setenv(t, "TRACKING_ID_PREFIX", tt.prefix)
+		setenv(t, "TRACKING_ID_CHECK", tt.check)
+		setenv(t, "TRACKING_ID_GENERATOR", tt.generator)
+		f, gen, err := trackingIdsFromEnv()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("trackingIdsFromEnv() with %+v: err = %v, want error %v", tt, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if id := gen.NewTrackingId(); f.ValidateTrackingId(id) != nil {
+			t.Errorf("trackingIdsFromEnv() with %+v issued invalid ID %q", tt, id)
+		}
+	}
+}
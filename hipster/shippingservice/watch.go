@@ -0,0 +1,50 @@
+// Copyright 2018 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/shippingservice/genproto"
+)
+
+// WatchShipment sends the status of a shipment, then sends it again every
+// time its state changes. The stream ends once the shipment is delivered.
+func (s *server) WatchShipment(req *pb.WatchShipmentRequest, stream pb.ShippingService_WatchShipmentServer) error {
+	sh, err := s.getShipment(stream.Context(), req.GetTrackingId())
+	if err != nil {
+		return err
+	}
+	for {
+		st, next := s.lifecycle.status(sh)
+		if err := stream.Send(st); err != nil {
+			return err
+		}
+		if next.IsZero() {
+			return nil
+		}
+		timer, stop := s.lifecycle.clock.Timer(next)
+		select {
+		case <-stream.Context().Done():
+			stop()
+			return nil
+		case <-s.stopping:
+			stop()
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-timer:
+		}
+	}
+}